@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"reflect"
 	"time"
@@ -17,64 +18,258 @@ const (
 	CacheType = "cache"
 )
 
+// CacheOption represents a cache option function
+type CacheOption[T any] func(o *Cache[T])
+
+// WithSerializer allows encoding values into bytes before they are set in the store,
+// and decoding them back when they are retrieved. This is needed to store typed
+// values in stores only handling bytes or strings, such as Redis, Memcache or Bigcache.
+func WithSerializer[T any](serializer Serializer[T]) CacheOption[T] {
+	return func(o *Cache[T]) {
+		o.serializer = serializer
+	}
+}
+
+// WithKeyGenerator allows setting the way store keys are computed from keys that are
+// neither strings nor CacheKeyGenerator implementations. By default, an MD5 checksum
+// of their printed value is used.
+func WithKeyGenerator[T any](generator KeyGenerator) CacheOption[T] {
+	return func(o *Cache[T]) {
+		o.keyGenerator = generator
+	}
+}
+
 // Cache represents the configuration needed by a cache
 type Cache[T any] struct {
-	codec codec.CodecInterface
+	codec         codec.CodecInterface
+	serializer    Serializer[T]
+	envelope      *envelope
+	keyGenerator  KeyGenerator
+	keyNormalizer *keyNormalizer
+	hooks         *store.Hooks
+	storeHooked   bool
+	lifecycle     lifecycle
 }
 
 // New instantiates a new cache entry
-func New[T any](store store.StoreInterface) *Cache[T] {
-	return &Cache[T]{
-		codec: codec.New(store),
+func New[T any](s store.StoreInterface, options ...CacheOption[T]) *Cache[T] {
+	cache := &Cache[T]{
+		codec: codec.New(s),
+	}
+
+	for _, option := range options {
+		option(cache)
+	}
+
+	if cache.envelope != nil && cache.serializer == nil {
+		cache.serializer = MsgpackSerializer[T]{}
+	}
+
+	if source, ok := s.(hooksProvider); ok && cache.hooks != nil && source.GetHooks() == cache.hooks {
+		// The store already emits set, delete, hit and miss events to these hooks
+		cache.storeHooked = true
+	}
+
+	if cache.keyNormalizer != nil && cache.keyNormalizer.constraints == nil {
+		constraints := store.GetKeyConstraints(s)
+		cache.keyNormalizer.constraints = &constraints
 	}
+
+	return cache
 }
 
 // Get returns the object stored in cache if it exists
 func (c *Cache[T]) Get(ctx context.Context, key any) (T, error) {
-	cacheKey := getCacheKey(key)
+	if c.lifecycle.isClosed() {
+		return *new(T), ErrClosed
+	}
 
-	value, err := c.codec.Get(ctx, cacheKey)
+	cacheKey, storeKey := c.getKeys(key)
+
+	value, err := c.codec.Get(ctx, storeKey)
 	if err != nil {
+		c.emitRead(key, *new(T), err)
 		return *new(T), err
 	}
 
-	return handleReturnValue[T](value), nil
+	object, err := c.decode(ctx, cacheKey, storeKey, value)
+	c.emitRead(key, object, err)
+
+	return object, err
 }
 
 // GetWithTTL returns the object stored in cache and its corresponding TTL
 func (c *Cache[T]) GetWithTTL(ctx context.Context, key any) (T, time.Duration, error) {
-	cacheKey := getCacheKey(key)
+	if c.lifecycle.isClosed() {
+		return *new(T), 0, ErrClosed
+	}
+
+	cacheKey, storeKey := c.getKeys(key)
 
-	value, duration, err := c.codec.GetWithTTL(ctx, cacheKey)
+	value, duration, err := c.codec.GetWithTTL(ctx, storeKey)
 	if err != nil {
+		c.emitRead(key, *new(T), err)
 		return *new(T), duration, err
 	}
 
-	return handleReturnValue[T](value), duration, nil
+	object, err := c.decode(ctx, cacheKey, storeKey, value)
+	c.emitRead(key, object, err)
+
+	return object, duration, err
 }
 
 // Set populates the cache item using the given key
 func (c *Cache[T]) Set(ctx context.Context, key any, object T, options ...store.Option) error {
-	cacheKey := getCacheKey(key)
-	return c.codec.Set(ctx, cacheKey, object, options...)
+	if c.lifecycle.isClosed() {
+		return ErrClosed
+	}
+
+	if err := c.set(ctx, key, object, options...); err != nil {
+		return err
+	}
+
+	c.emit(store.EventSet, key, object)
+
+	return nil
+}
+
+func (c *Cache[T]) set(ctx context.Context, key any, object T, options ...store.Option) error {
+	return c.setWithMetadata(ctx, key, object, nil, options...)
+}
+
+// setWithLoadDuration sets the given object like Set. When an envelope is used, the time its
+// load took and its expiration date are recorded in the envelope, so any instance reading
+// the value is able to refresh it early (see xfetchCacheInterface).
+func (c *Cache[T]) setWithLoadDuration(ctx context.Context, key any, object T, loadDuration time.Duration, options ...store.Option) error {
+	if c.lifecycle.isClosed() {
+		return ErrClosed
+	}
+
+	if c.envelope == nil {
+		return c.Set(ctx, key, object, options...)
+	}
+
+	metadata := &envelopeMetadata{loadDuration: loadDuration}
+
+	opts := store.ApplyOptions(options...)
+	if expiration := opts.Expiration(); expiration > 0 {
+		// The jitter is applied here so the recorded expiration date is the one of the store
+		expiration = opts.JitteredExpiration(expiration)
+		metadata.expiresAt = c.envelope.now().Add(expiration)
+		options = append(options[:len(options):len(options)], store.WithExpiration(expiration), store.WithExpirationJitter(0))
+	}
+
+	if err := c.setWithMetadata(ctx, key, object, metadata, options...); err != nil {
+		return err
+	}
+
+	c.emit(store.EventSet, key, object)
+
+	return nil
+}
+
+// getWithLoadDuration returns the object stored in cache, its TTL and the time its load
+// took, which is zero when it has not been recorded (see xfetchCacheInterface). The TTL is
+// computed from the recorded expiration date when the store does not return TTLs.
+func (c *Cache[T]) getWithLoadDuration(ctx context.Context, key any) (T, time.Duration, time.Duration, error) {
+	if c.lifecycle.isClosed() {
+		return *new(T), 0, 0, ErrClosed
+	}
+
+	cacheKey, storeKey := c.getKeys(key)
+
+	value, ttl, err := c.codec.GetWithTTL(ctx, storeKey)
+	if err != nil {
+		c.emitRead(key, *new(T), err)
+		return *new(T), ttl, 0, err
+	}
+
+	object, metadata, err := c.decodeWithMetadata(ctx, cacheKey, storeKey, value)
+	c.emitRead(key, object, err)
+
+	if err != nil || metadata == nil {
+		return object, ttl, 0, err
+	}
+
+	if ttl <= 0 && !metadata.expiresAt.IsZero() {
+		ttl = metadata.expiresAt.Sub(c.envelope.now())
+	}
+
+	return object, ttl, metadata.loadDuration, nil
+}
+
+func (c *Cache[T]) setWithMetadata(ctx context.Context, key any, object T, metadata *envelopeMetadata, options ...store.Option) error {
+	cacheKey, storeKey := c.getKeys(key)
+
+	if c.serializer == nil {
+		return c.codec.Set(ctx, storeKey, object, options...)
+	}
+
+	value, err := c.serializer.Marshal(object)
+	if err != nil {
+		return err
+	}
+
+	if c.envelope != nil {
+		var originalKey string
+		if storeKey != cacheKey {
+			originalKey = cacheKey
+		}
+		value = c.envelope.sealWithMetadata(value, originalKey, metadata)
+	}
+
+	return c.codec.Set(ctx, storeKey, value, options...)
 }
 
 // Delete removes the cache item using the given key
 func (c *Cache[T]) Delete(ctx context.Context, key any) error {
-	cacheKey := getCacheKey(key)
-	return c.codec.Delete(ctx, cacheKey)
+	if c.lifecycle.isClosed() {
+		return ErrClosed
+	}
+
+	_, storeKey := c.getKeys(key)
+	if err := c.codec.Delete(ctx, storeKey); err != nil {
+		return err
+	}
+
+	c.emit(store.EventDelete, key, nil)
+
+	return nil
 }
 
 // Invalidate invalidates cache item from given options
 func (c *Cache[T]) Invalidate(ctx context.Context, options ...store.InvalidateOption) error {
+	if c.lifecycle.isClosed() {
+		return ErrClosed
+	}
+
 	return c.codec.Invalidate(ctx, options...)
 }
 
 // Clear resets all cache data
 func (c *Cache[T]) Clear(ctx context.Context) error {
+	if c.lifecycle.isClosed() {
+		return ErrClosed
+	}
+
 	return c.codec.Clear(ctx)
 }
 
+// Close closes the store if it holds resources. Next operations return ErrClosed.
+func (c *Cache[T]) Close() error {
+	return closeWithTimeout(c.CloseWithContext)
+}
+
+// CloseWithContext closes the store if it holds resources, before the context deadline.
+// Next operations return ErrClosed.
+func (c *Cache[T]) CloseWithContext(ctx context.Context) error {
+	if closed, _ := c.lifecycle.close(ctx, nil); !closed {
+		return nil
+	}
+
+	return closeWrapped(ctx, c.codec.GetStore())
+}
+
 // GetCodec returns the current codec
 func (c *Cache[T]) GetCodec() codec.CodecInterface {
 	return c.codec
@@ -85,16 +280,63 @@ func (c *Cache[T]) GetType() string {
 	return CacheType
 }
 
+// getCacheKey returns the cache key for the given key object, using the key generator if any
+func (c *Cache[T]) getCacheKey(key any) string {
+	return generateCacheKey(key, c.keyGenerator)
+}
+
+// getKeyNormalizer returns the key normalizer of the cache, if any
+func (c *Cache[T]) getKeyNormalizer() *keyNormalizer {
+	return c.keyNormalizer
+}
+
+// getKeys returns the cache key for the given key object and the key used in the store,
+// which differs from the cache key when it has been normalized
+func (c *Cache[T]) getKeys(key any) (string, string) {
+	cacheKey := c.getCacheKey(key)
+
+	if c.keyNormalizer == nil {
+		return cacheKey, cacheKey
+	}
+
+	storeKey, _ := c.keyNormalizer.normalize(cacheKey)
+	return cacheKey, storeKey
+}
+
+// cacheKeyFunc returns a function computing the cache key of key objects the way the
+// given cache does, using its key generator, or the default one if it is not a Cache
+func cacheKeyFunc(cache any) func(key any) string {
+	keyer, ok := cache.(cacheKeyer)
+	if !ok {
+		return getCacheKey
+	}
+
+	return func(key any) string {
+		cacheKey, _ := keyer.getKeys(key)
+		return cacheKey
+	}
+}
+
 // getCacheKey returns the cache key for the given key object by returning
 // the key if type is string or by computing a checksum of key structure
 // if its type is other than string
 func getCacheKey(key any) string {
+	return generateCacheKey(key, nil)
+}
+
+// generateCacheKey returns the cache key for the given key object by returning
+// the key if type is string or by using the given generator (or computing a checksum
+// of key structure when nil) if its type is other than string
+func generateCacheKey(key any, generator KeyGenerator) string {
 	switch v := key.(type) {
 	case string:
 		return v
 	case CacheKeyGenerator:
 		return v.GetCacheKey()
 	default:
+		if generator != nil {
+			return generator.GenerateKey(key)
+		}
 		return checksum(key)
 	}
 }
@@ -109,22 +351,71 @@ func checksum(object any) string {
 	return fmt.Sprintf("%x", hash)
 }
 
-func handleReturnValue[T any](value any) T {
-	if v, ok := value.(T); ok {
-		return v
+// GetEnvelopeStats returns the number of invalid enveloped values read from the store
+func (c *Cache[T]) GetEnvelopeStats() EnvelopeStats {
+	if c.envelope == nil {
+		return EnvelopeStats{}
+	}
+
+	return c.envelope.stats()
+}
+
+// decode returns the given store value as the cache value type, using the envelope
+// and the serializer if any
+func (c *Cache[T]) decode(ctx context.Context, cacheKey string, storeKey string, value any) (T, error) {
+	object, _, err := c.decodeWithMetadata(ctx, cacheKey, storeKey, value)
+	return object, err
+}
+
+// decodeWithMetadata decodes the given store value like decode, also returning
+// the metadata recorded in its envelope, if any
+func (c *Cache[T]) decodeWithMetadata(ctx context.Context, cacheKey string, storeKey string, value any) (T, *envelopeMetadata, error) {
+	var metadata *envelopeMetadata
+
+	if c.envelope != nil {
+		payload, envelopeMetadata, err := c.envelope.openWithMetadata(value, cacheKey)
+		if err != nil {
+			if c.envelope.deleteInvalid && !errors.Is(err, ErrKeyCollision) {
+				c.codec.Delete(ctx, storeKey)
+			}
+			return *new(T), nil, store.NotFoundWithCause(err)
+		}
+		value = payload
+		metadata = envelopeMetadata
+	}
+
+	var object T
+	var err error
+	if c.serializer != nil {
+		object, err = decodeValue(c.serializer, value)
 	} else {
-		// in case we have []byte in store, it is returned as base64 string but we expect []byte,
-		// we need to decode the base64 string to []byte
-		if valStr, valOk := value.(string); valOk {
-			if _, tOk := any(*new(T)).([]byte); tOk {
-				decodedValue, err := base64.StdEncoding.DecodeString(valStr)
-				if err != nil {
-					// return the string value as []byte if it's not a base64 string
-					return any([]byte(valStr)).(T)
-				}
-				return any(decodedValue).(T)
+		object, err = handleReturnValue[T](value)
+	}
+
+	return object, metadata, err
+}
+
+func handleReturnValue[T any](value any) (T, error) {
+	if value == nil {
+		return *new(T), nil
+	}
+
+	if v, ok := value.(T); ok {
+		return v, nil
+	}
+
+	// in case we have []byte in store, it is returned as base64 string but we expect []byte,
+	// we need to decode the base64 string to []byte
+	if valStr, valOk := value.(string); valOk {
+		if _, tOk := any(*new(T)).([]byte); tOk {
+			decodedValue, err := base64.StdEncoding.DecodeString(valStr)
+			if err != nil {
+				// return the string value as []byte if it's not a base64 string
+				return any([]byte(valStr)).(T), nil
 			}
+			return any(decodedValue).(T), nil
 		}
 	}
-	return *new(T)
+
+	return *new(T), fmt.Errorf("%w: unexpected %T value in store", ErrDecode, value)
 }
@@ -104,6 +104,97 @@ func TestCacheGet(t *testing.T) {
 	assert.Equal(t, cacheValue, value)
 }
 
+func TestCacheGetWhenTypeMismatch(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	store := mocksStore.NewMockStoreInterface(ctrl)
+	store.EXPECT().Get(ctx, "my-key").Return(12, nil)
+
+	cache := New[string](store)
+
+	// When
+	value, err := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.ErrorIs(t, err, ErrDecode)
+	assert.Equal(t, "", value)
+}
+
+func TestCacheSetWithSerializer(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	type Book struct {
+		ID   int
+		Name string
+	}
+
+	mockedStore := mocksStore.NewMockStoreInterface(ctrl)
+	mockedStore.EXPECT().Set(ctx, "my-key", []byte(`{"ID":1,"Name":"My book"}`)).Return(nil)
+
+	cache := New[Book](mockedStore, WithSerializer[Book](JSONSerializer[Book]{}))
+
+	// When
+	err := cache.Set(ctx, "my-key", Book{ID: 1, Name: "My book"})
+
+	// Then
+	assert.Nil(t, err)
+}
+
+func TestCacheGetWithSerializer(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	type Book struct {
+		ID   int
+		Name string
+	}
+
+	store := mocksStore.NewMockStoreInterface(ctrl)
+	store.EXPECT().Get(ctx, "my-key").Return(`{"ID":1,"Name":"My book"}`, nil)
+
+	cache := New[Book](store, WithSerializer[Book](JSONSerializer[Book]{}))
+
+	// When
+	value, err := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, Book{ID: 1, Name: "My book"}, value)
+}
+
+func TestCacheGetWithSerializerWhenDecodeError(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	type Book struct {
+		ID   int
+		Name string
+	}
+
+	store := mocksStore.NewMockStoreInterface(ctrl)
+	store.EXPECT().GetWithTTL(ctx, "my-key").Return([]byte("not json"), time.Minute, nil)
+
+	cache := New[Book](store, WithSerializer[Book](JSONSerializer[Book]{}))
+
+	// When
+	value, ttl, err := cache.GetWithTTL(ctx, "my-key")
+
+	// Then
+	assert.ErrorIs(t, err, ErrDecode)
+	assert.Equal(t, Book{}, value)
+	assert.Equal(t, time.Minute, ttl)
+}
+
 func TestCacheGetWhenNotFound(t *testing.T) {
 	// Given
 	ctrl := gomock.NewController(t)
@@ -359,3 +450,44 @@ func TestCacheDeleteWhenError(t *testing.T) {
 	// Then
 	assert.Equal(t, expectedErr, err)
 }
+
+type closableStoreMock struct {
+	*mocksStore.MockStoreInterface
+	closeCalls int
+}
+
+func (s *closableStoreMock) Close() error {
+	s.closeCalls++
+	return nil
+}
+
+func TestCacheClose(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	closableStore := &closableStoreMock{MockStoreInterface: mocksStore.NewMockStoreInterface(ctrl)}
+
+	cache := New[any](closableStore)
+
+	// When
+	err := cache.Close()
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, 1, closableStore.closeCalls)
+
+	_, err = cache.Get(ctx, "my-key")
+	assert.ErrorIs(t, err, ErrClosed)
+	_, _, err = cache.GetWithTTL(ctx, "my-key")
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.ErrorIs(t, cache.Set(ctx, "my-key", "my-value"), ErrClosed)
+	assert.ErrorIs(t, cache.Delete(ctx, "my-key"), ErrClosed)
+	assert.ErrorIs(t, cache.Invalidate(ctx), ErrClosed)
+	assert.ErrorIs(t, cache.Clear(ctx), ErrClosed)
+
+	// Closing twice does not close the store again
+	assert.Nil(t, cache.Close())
+	assert.Equal(t, 1, closableStore.closeCalls)
+}
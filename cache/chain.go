@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/eko/gocache/v3/store"
@@ -15,26 +17,233 @@ const (
 )
 
 type chainKeyValue[T any] struct {
-	key       any
-	value     T
-	ttl       time.Duration
-	storeType *string
+	key   any
+	value T
+	ttl   time.Duration
+	layer int
+}
+
+// ChainOption represents a chain cache option function
+type ChainOption[T any] func(o *ChainCache[T])
+
+// WithChainLayerNames allows setting a name for each cache layer, in the same order
+// as the caches. Layer names are used in error messages and metrics labels, and have
+// to be unique: NewChainWithOptions panics when the same name is given to several layers.
+// When no name is given for a layer, its store type is used, followed by the position
+// of the layer (such as "redis#1") when several layers use the same store type.
+func WithChainLayerNames[T any](names ...string) ChainOption[T] {
+	return func(o *ChainCache[T]) {
+		seen := make(map[string]bool, len(names))
+		for _, name := range names {
+			if name == "" {
+				continue
+			}
+
+			if seen[name] {
+				panic(fmt.Sprintf("chain cache layer name %q is given to several layers", name))
+			}
+			seen[name] = true
+		}
+
+		o.names = names
+	}
+}
+
+// WithChainWritePolicy allows setting the policy used to write values into the
+// cache layers. Default policy is ChainWriteThrough.
+func WithChainWritePolicy[T any](policy ChainWritePolicy) ChainOption[T] {
+	return func(o *ChainCache[T]) {
+		o.writePolicy = policy
+	}
+}
+
+// WithChainParallelWrites allows writing all the cache layers concurrently
+// instead of one after another when using the ChainWriteThrough policy
+func WithChainParallelWrites[T any]() ChainOption[T] {
+	return func(o *ChainCache[T]) {
+		o.parallelWrites = true
+	}
+}
+
+// WithChainWriteBackRetry allows setting the number of attempts made to write a value
+// into each lower cache layer when using the ChainWriteBack policy, and the delay
+// between two attempts
+func WithChainWriteBackRetry[T any](maxAttempts int, backoff time.Duration) ChainOption[T] {
+	return func(o *ChainCache[T]) {
+		o.writeBackMaxAttempts = maxAttempts
+		o.writeBackBackoff = backoff
+	}
+}
+
+// WithChainWriteBackQueueSize allows setting the number of values waiting to be written into
+// the lower cache layers when using the ChainWriteBack policy. When the queue is full, values
+// are written synchronously. Default size is 10000.
+func WithChainWriteBackQueueSize[T any](size int) ChainOption[T] {
+	return func(o *ChainCache[T]) {
+		o.writeBackQueueSize = size
+	}
+}
+
+// ChainWriteBackDropCallback is called when a value cannot be written into a lower cache
+// layer when using the ChainWriteBack policy, after the last attempt or on close
+type ChainWriteBackDropCallback func(ctx context.Context, key any, layer string, err error)
+
+// WithChainWriteBackDropCallback allows setting a callback called when a value is dropped
+// without having been written into a lower cache layer when using the ChainWriteBack policy
+func WithChainWriteBackDropCallback[T any](callback ChainWriteBackDropCallback) ChainOption[T] {
+	return func(o *ChainCache[T]) {
+		o.writeBackDropCallback = callback
+	}
+}
+
+// WithChainLayerOptions allows setting store options applied when writing values into
+// the cache layer having the given name, for instance a shorter expiration in the first layer.
+// These options override the ones given to Set.
+func WithChainLayerOptions[T any](name string, options ...store.Option) ChainOption[T] {
+	return func(o *ChainCache[T]) {
+		if o.layerOptions == nil {
+			o.layerOptions = make(map[string][]store.Option)
+		}
+		o.layerOptions[name] = append(o.layerOptions[name], options...)
+	}
+}
+
+// ChainBackfillErrorCallback is called when a value found in a cache layer cannot be
+// set back into an upper layer
+type ChainBackfillErrorCallback func(ctx context.Context, key any, layer string, err error)
+
+// WithChainSyncBackfill allows setting values found in a cache layer back into the upper
+// layers before Get returns, instead of doing it in background
+func WithChainSyncBackfill[T any]() ChainOption[T] {
+	return func(o *ChainCache[T]) {
+		o.syncBackfill = true
+	}
+}
+
+// WithChainBackfillQueueSize allows setting the number of values waiting to be set back
+// into upper cache layers in background. Default size is 10000.
+func WithChainBackfillQueueSize[T any](size int) ChainOption[T] {
+	return func(o *ChainCache[T]) {
+		o.backfillQueueSize = size
+	}
+}
+
+// WithChainBackfillDropOnFull allows dropping values to be set back into upper cache layers
+// when the background queue is full, instead of blocking Get until there is room in the queue.
+// The number of dropped values is returned by DroppedBackfills.
+func WithChainBackfillDropOnFull[T any]() ChainOption[T] {
+	return func(o *ChainCache[T]) {
+		o.backfillDropOnFull = true
+	}
+}
+
+// WithChainLayerMaxBackfillTTL allows setting the maximum expiration of values set back
+// into the cache layer having the given name
+func WithChainLayerMaxBackfillTTL[T any](name string, ttl time.Duration) ChainOption[T] {
+	return func(o *ChainCache[T]) {
+		if o.maxBackfillTTLs == nil {
+			o.maxBackfillTTLs = make(map[string]time.Duration)
+		}
+		o.maxBackfillTTLs[name] = ttl
+	}
+}
+
+// WithChainBackfillErrorCallback allows setting a callback called when a value cannot
+// be set back into an upper cache layer
+func WithChainBackfillErrorCallback[T any](callback ChainBackfillErrorCallback) ChainOption[T] {
+	return func(o *ChainCache[T]) {
+		o.backfillErrorCallback = callback
+	}
+}
+
+// WithChainParallelReads allows querying all the cache layers concurrently on Get.
+// The value of the first layer having it is returned, and requests to lower layers are cancelled.
+func WithChainParallelReads[T any]() ChainOption[T] {
+	return func(o *ChainCache[T]) {
+		o.readStrategy = chainReadParallel
+	}
+}
+
+// WithChainHedgedReads allows querying the next cache layer on Get when the current one
+// has not answered after the given delay. The value of the first layer having it is returned,
+// and requests to lower layers are cancelled.
+func WithChainHedgedReads[T any](delay time.Duration) ChainOption[T] {
+	return func(o *ChainCache[T]) {
+		o.readStrategy = chainReadHedged
+		o.hedgeDelay = delay
+	}
+}
+
+// WithChainFailFast allows stopping Set, Delete, Invalidate and Clear operations at the
+// first cache layer error instead of trying all the layers (best effort, the default)
+func WithChainFailFast[T any]() ChainOption[T] {
+	return func(o *ChainCache[T]) {
+		o.failFast = true
+	}
 }
 
 // ChainCache represents the configuration needed by a cache aggregator
 type ChainCache[T any] struct {
-	caches     []SetterCacheInterface[T]
-	setChannel chan *chainKeyValue[T]
+	caches         []SetterCacheInterface[T]
+	names          []string
+	layerNames     []string
+	layerNamesOnce sync.Once
+	setChannel     chan *chainKeyValue[T]
+
+	syncBackfill          bool
+	backfillQueueSize     int
+	backfillDropOnFull    bool
+	droppedBackfills      uint64
+	maxBackfillTTLs       map[string]time.Duration
+	backfillErrorCallback ChainBackfillErrorCallback
+
+	failFast bool
+
+	readStrategy chainReadStrategy
+	hedgeDelay   time.Duration
+
+	lifecycle lifecycle
+
+	writePolicy           ChainWritePolicy
+	parallelWrites        bool
+	layerOptions          map[string][]store.Option
+	writeBackQueue        chan *chainWrite[T]
+	writeBackQueueSize    int
+	writeBackMaxAttempts  int
+	writeBackBackoff      time.Duration
+	writeBackDropCallback ChainWriteBackDropCallback
+	droppedWriteBacks     uint64
 }
 
 // NewChain instantiates a new cache aggregator
 func NewChain[T any](caches ...SetterCacheInterface[T]) *ChainCache[T] {
+	return NewChainWithOptions[T](caches)
+}
+
+// NewChainWithOptions instantiates a new cache aggregator with given options
+func NewChainWithOptions[T any](caches []SetterCacheInterface[T], options ...ChainOption[T]) *ChainCache[T] {
 	chain := &ChainCache[T]{
-		caches:     caches,
-		setChannel: make(chan *chainKeyValue[T], 10000),
+		caches:            caches,
+		backfillQueueSize: 10000,
+
+		writeBackQueueSize:   defaultChainWriteBackQueueSize,
+		writeBackMaxAttempts: 3,
+		writeBackBackoff:     100 * time.Millisecond,
 	}
 
-	go chain.setter()
+	for _, option := range options {
+		option(chain)
+	}
+
+	if !chain.syncBackfill {
+		chain.setChannel = make(chan *chainKeyValue[T], chain.backfillQueueSize)
+		chain.lifecycle.goroutine(chain.setter)
+	}
+
+	if chain.writePolicy == ChainWriteBack {
+		chain.writeBackQueue = make(chan *chainWrite[T], chain.writeBackQueueSize)
+		chain.lifecycle.goroutine(chain.writeBacker)
+	}
 
 	return chain
 }
@@ -42,81 +251,264 @@ func NewChain[T any](caches ...SetterCacheInterface[T]) *ChainCache[T] {
 // setter sets a value in available caches, until a given cache layer
 func (c *ChainCache[T]) setter() {
 	for item := range c.setChannel {
-		for _, cache := range c.caches {
-			if item.storeType != nil && *item.storeType == cache.GetCodec().GetStore().GetType() {
-				break
+		if !c.lifecycle.abandoned() {
+			c.backfill(context.Background(), item)
+		}
+	}
+}
+
+// backfill sets a value found in a cache layer back into the upper layers
+func (c *ChainCache[T]) backfill(ctx context.Context, item *chainKeyValue[T]) {
+	for i, cache := range c.caches[:item.layer] {
+		ttl := item.ttl
+
+		if len(c.maxBackfillTTLs) > 0 {
+			if maxTTL, ok := c.maxBackfillTTLs[c.layerName(i)]; ok && (ttl <= 0 || ttl > maxTTL) {
+				ttl = maxTTL
 			}
+		}
+
+		err := cache.Set(ctx, item.key, item.value, store.WithExpiration(ttl))
+		if err != nil && c.backfillErrorCallback != nil {
+			c.backfillErrorCallback(ctx, item.key, c.layerName(i), err)
+		}
+	}
+}
+
+// queueBackfill sets a value found in a cache layer back into the upper layers,
+// either synchronously or by sending it to the background setter
+func (c *ChainCache[T]) queueBackfill(ctx context.Context, item *chainKeyValue[T]) {
+	if item.layer == 0 {
+		return
+	}
+
+	if c.syncBackfill {
+		c.backfill(ctx, item)
+		return
+	}
+
+	c.lifecycle.do(func() {
+		if !c.backfillDropOnFull {
+			c.setChannel <- item
+			return
+		}
+
+		select {
+		case c.setChannel <- item:
+		default:
+			atomic.AddUint64(&c.droppedBackfills, 1)
+		}
+	})
+}
+
+// DroppedBackfills returns the number of values that have not been set back into
+// upper cache layers because the background queue was full
+func (c *ChainCache[T]) DroppedBackfills() uint64 {
+	return atomic.LoadUint64(&c.droppedBackfills)
+}
+
+// Close stops the background workers of the chain, after they have processed
+// the values waiting to be set back or written into cache layers, and closes
+// the cache layers. An error wrapping ErrChainWriteBackDropped is returned when values
+// could not be written into the lower cache layers. Next operations return ErrClosed.
+func (c *ChainCache[T]) Close() error {
+	return closeWithTimeout(c.CloseWithContext)
+}
+
+// CloseWithContext stops the background workers of the chain and closes the cache layers.
+// Values still waiting to be set back or written into cache layers when the context is done
+// are dropped. Next operations return ErrClosed.
+func (c *ChainCache[T]) CloseWithContext(ctx context.Context) error {
+	droppedWriteBacks := c.DroppedWriteBacks()
+
+	closed, err := c.lifecycle.close(ctx, func() {
+		if c.setChannel != nil {
+			close(c.setChannel)
+		}
+		if c.writeBackQueue != nil {
+			close(c.writeBackQueue)
+		}
+	})
+	if !closed {
+		return nil
+	}
+
+	errs := []error{err, c.flushWriteBacksError(droppedWriteBacks)}
+	for _, cache := range c.caches {
+		errs = append(errs, closeWrapped(ctx, cache))
+	}
+
+	return firstError(errs...)
+}
+
+// layerName returns the name of the cache layer at the given index
+func (c *ChainCache[T]) layerName(index int) string {
+	c.layerNamesOnce.Do(c.resolveLayerNames)
+
+	return c.layerNames[index]
+}
 
-			cache.Set(context.Background(), item.key, item.value, store.WithExpiration(item.ttl))
+// resolveLayerNames computes the name of each layer: the given name, or the store type
+// followed by the position of the layer when several layers use the same store type
+func (c *ChainCache[T]) resolveLayerNames() {
+	names := make([]string, len(c.caches))
+	typeCount := make(map[string]int)
+
+	for i, cache := range c.caches {
+		if i < len(c.names) && c.names[i] != "" {
+			names[i] = c.names[i]
+			continue
+		}
+
+		names[i] = cache.GetCodec().GetStore().GetType()
+		typeCount[names[i]]++
+	}
+
+	for i, name := range names {
+		if (i >= len(c.names) || c.names[i] == "") && typeCount[name] > 1 {
+			names[i] = fmt.Sprintf("%s#%d", name, i)
 		}
 	}
+
+	c.layerNames = names
 }
 
-// Get returns the object stored in cache if it exists
+// Get returns the object stored in cache if it exists. When no layer has it and some
+// layers failed with another error than a miss, a *ChainError holding the error of every
+// layer is returned, which still matches store.NotFound using errors.Is.
 func (c *ChainCache[T]) Get(ctx context.Context, key any) (T, error) {
+	if c.lifecycle.isClosed() {
+		return *new(T), ErrClosed
+	}
+
+	if c.readStrategy != chainReadSequential && len(c.caches) > 0 {
+		return c.getConcurrently(ctx, key)
+	}
+
 	var object T
 	var err error
 	var ttl time.Duration
 
-	for _, cache := range c.caches {
-		storeType := cache.GetCodec().GetStore().GetType()
+	errs := make([]error, len(c.caches))
+	for i, cache := range c.caches {
 		object, ttl, err = cache.GetWithTTL(ctx, key)
 		if err == nil {
 			// Set the value back until this cache layer
-			c.setChannel <- &chainKeyValue[T]{key, object, ttl, &storeType}
+			c.queueBackfill(ctx, &chainKeyValue[T]{key, object, ttl, i})
 			return object, nil
 		}
+
+		errs[i] = err
 	}
 
-	return object, err
+	return object, c.getError(errs)
 }
 
-// Set sets a value in available caches
-func (c *ChainCache[T]) Set(ctx context.Context, key any, object T, options ...store.Option) error {
-	errs := []error{}
-	for _, cache := range c.caches {
-		err := cache.Set(ctx, key, object, options...)
-		if err != nil {
-			storeType := cache.GetCodec().GetStore().GetType()
-			errs = append(errs, fmt.Errorf("Unable to set item into cache with store '%s': %v", storeType, err))
+// getError returns the error of a Get having found the value in no layer, given the
+// error of each layer: the last error when all the layers missed the value, or a
+// *ChainError holding all of them when some layers failed
+func (c *ChainCache[T]) getError(errs []error) error {
+	failed := false
+	for _, err := range errs {
+		if !errors.Is(err, store.NotFound{}) {
+			failed = true
+			break
 		}
 	}
-	if len(errs) > 0 {
-		errStr := ""
-		for k, v := range errs {
-			errStr += fmt.Sprintf("error %d of %d: %v", k+1, len(errs), v.Error())
+
+	if !failed {
+		if len(errs) == 0 {
+			return nil
 		}
-		return errors.New(errStr)
+		return errs[len(errs)-1]
+	}
+
+	layerErrs := make([]*ChainLayerError, len(errs))
+	for i, err := range errs {
+		layerErrs[i] = c.layerError(i, err)
 	}
 
-	return nil
+	return chainError("get", layerErrs)
 }
 
-// Delete removes a value from all available caches
-func (c *ChainCache[T]) Delete(ctx context.Context, key any) error {
-	for _, cache := range c.caches {
-		cache.Delete(ctx, key)
+// Set sets a value in available caches, depending on the write policy.
+// A *ChainError is returned when some cache layers failed.
+func (c *ChainCache[T]) Set(ctx context.Context, key any, object T, options ...store.Option) error {
+	if c.lifecycle.isClosed() {
+		return ErrClosed
 	}
 
-	return nil
+	var errs []*ChainLayerError
+
+	switch c.writePolicy {
+	case ChainWriteAround:
+		errs = c.writeAround(ctx, key, object, options)
+	case ChainWriteBack:
+		errs = c.writeBack(ctx, key, object, options)
+	default:
+		errs = c.writeThrough(ctx, key, object, options)
+	}
+
+	return chainError("set", errs)
 }
 
-// Invalidate invalidates cache item from given options
+// Delete removes a value from all available caches.
+// A *ChainError is returned when some cache layers failed.
+func (c *ChainCache[T]) Delete(ctx context.Context, key any) error {
+	return c.forEachLayer("delete", func(cache SetterCacheInterface[T]) error {
+		return cache.Delete(ctx, key)
+	})
+}
+
+// Invalidate invalidates cache item from given options.
+// A *ChainError is returned when some cache layers failed.
 func (c *ChainCache[T]) Invalidate(ctx context.Context, options ...store.InvalidateOption) error {
-	for _, cache := range c.caches {
-		cache.Invalidate(ctx, options...)
+	return c.forEachLayer("invalidate", func(cache SetterCacheInterface[T]) error {
+		return cache.Invalidate(ctx, options...)
+	})
+}
+
+// Clear resets all cache data.
+// A *ChainError is returned when some cache layers failed.
+func (c *ChainCache[T]) Clear(ctx context.Context) error {
+	return c.forEachLayer("clear", func(cache SetterCacheInterface[T]) error {
+		return cache.Clear(ctx)
+	})
+}
+
+// forEachLayer calls the given function for each cache layer and collects their errors
+func (c *ChainCache[T]) forEachLayer(operation string, fn func(cache SetterCacheInterface[T]) error) error {
+	if c.lifecycle.isClosed() {
+		return ErrClosed
 	}
 
-	return nil
+	var errs []*ChainLayerError
+
+	for i, cache := range c.caches {
+		if err := fn(cache); err != nil {
+			errs = append(errs, c.layerError(i, err))
+
+			if c.failFast {
+				break
+			}
+		}
+	}
+
+	return chainError(operation, errs)
 }
 
-// Clear resets all cache data
-func (c *ChainCache[T]) Clear(ctx context.Context) error {
-	for _, cache := range c.caches {
-		cache.Clear(ctx)
+// layerError wraps an error returned by the cache layer at the given index
+func (c *ChainCache[T]) layerError(index int, err error) *ChainLayerError {
+	return &ChainLayerError{Layer: c.layerName(index), Err: err}
+}
+
+// chainError returns a *ChainError holding the given layer errors, or nil if there is none
+func chainError(operation string, errs []*ChainLayerError) error {
+	if len(errs) == 0 {
+		return nil
 	}
 
-	return nil
+	return &ChainError{Operation: operation, Errors: errs}
 }
 
 // GetCaches returns all Chained caches
@@ -124,6 +516,16 @@ func (c *ChainCache[T]) GetCaches() []SetterCacheInterface[T] {
 	return c.caches
 }
 
+// GetLayerNames returns the name of each chained cache layer
+func (c *ChainCache[T]) GetLayerNames() []string {
+	names := make([]string, len(c.caches))
+	for i := range c.caches {
+		names[i] = c.layerName(i)
+	}
+
+	return names
+}
+
 // GetType returns the cache type
 func (c *ChainCache[T]) GetType() string {
 	return ChainType
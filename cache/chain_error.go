@@ -0,0 +1,82 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ChainLayerError represents an error returned by a cache layer of a chain
+type ChainLayerError struct {
+	Layer string
+	Err   error
+}
+
+func (e *ChainLayerError) Error() string {
+	return fmt.Sprintf("layer '%s': %v", e.Layer, e.Err)
+}
+
+// Unwrap returns the error returned by the cache layer
+func (e *ChainLayerError) Unwrap() error {
+	return e.Err
+}
+
+// ChainError is returned by chain cache operations when some cache layers failed.
+// It holds the error of each failed layer, in the order of the chain, and can be
+// inspected using errors.Is and errors.As.
+type ChainError struct {
+	Operation string
+	Errors    []*ChainLayerError
+}
+
+func (e *ChainError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = fmt.Sprintf("error %d of %d: %v", i+1, len(e.Errors), err)
+	}
+
+	return fmt.Sprintf("chain cache %s failed: %s", e.Operation, strings.Join(messages, "; "))
+}
+
+// Layer returns the error returned by the cache layer having the given name, if any
+func (e *ChainError) Layer(name string) error {
+	for _, err := range e.Errors {
+		if err.Layer == name {
+			return err.Err
+		}
+	}
+
+	return nil
+}
+
+// Is reports whether any of the layer errors matches the target
+func (e *ChainError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first layer error that matches the target, and if so, sets target to it
+func (e *ChainError) As(target any) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Unwrap returns the error of each failed cache layer
+func (e *ChainError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+
+	return errs
+}
@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/eko/gocache/v3/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChainErrorError(t *testing.T) {
+	// Given
+	err := &ChainError{
+		Operation: "delete",
+		Errors: []*ChainLayerError{
+			{Layer: "local", Err: errors.New("first error")},
+			{Layer: "remote", Err: errors.New("second error")},
+		},
+	}
+
+	// When
+	message := err.Error()
+
+	// Then
+	assert.Equal(t, "chain cache delete failed: error 1 of 2: layer 'local': first error; error 2 of 2: layer 'remote': second error", message)
+}
+
+func TestChainErrorLayer(t *testing.T) {
+	// Given
+	remoteErr := errors.New("second error")
+
+	err := &ChainError{
+		Operation: "delete",
+		Errors: []*ChainLayerError{
+			{Layer: "remote", Err: remoteErr},
+		},
+	}
+
+	// When - Then
+	assert.Equal(t, remoteErr, err.Layer("remote"))
+	assert.Nil(t, err.Layer("local"))
+}
+
+func TestChainErrorIs(t *testing.T) {
+	// Given
+	remoteErr := errors.New("second error")
+
+	var err error = &ChainError{
+		Operation: "set",
+		Errors: []*ChainLayerError{
+			{Layer: "local", Err: store.NotFoundWithCause(errors.New("first error"))},
+			{Layer: "remote", Err: remoteErr},
+		},
+	}
+
+	// When - Then
+	assert.True(t, errors.Is(err, remoteErr))
+	assert.True(t, errors.Is(err, store.NotFound{}))
+	assert.False(t, errors.Is(err, errors.New("another error")))
+}
+
+func TestChainErrorAs(t *testing.T) {
+	// Given
+	var err error = &ChainError{
+		Operation: "set",
+		Errors: []*ChainLayerError{
+			{Layer: "remote", Err: &CachedLoadError{message: "an error"}},
+		},
+	}
+
+	// When
+	var layerErr *ChainLayerError
+	var loadErr *CachedLoadError
+
+	// Then
+	assert.True(t, errors.As(err, &layerErr))
+	assert.Equal(t, "remote", layerErr.Layer)
+
+	assert.True(t, errors.As(err, &loadErr))
+	assert.Equal(t, "an error", loadErr.Error())
+}
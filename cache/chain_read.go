@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+type chainReadStrategy int
+
+const (
+	chainReadSequential chainReadStrategy = iota
+	chainReadParallel
+	chainReadHedged
+)
+
+type chainReadResult[T any] struct {
+	layer int
+	value T
+	ttl   time.Duration
+	err   error
+}
+
+// getConcurrently queries cache layers concurrently, depending on the read strategy,
+// and returns the value of the first layer having it
+func (c *ChainCache[T]) getConcurrently(ctx context.Context, key any) (T, error) {
+	readCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan *chainReadResult[T], len(c.caches))
+	responses := make([]*chainReadResult[T], len(c.caches))
+
+	started := 0
+	start := func() {
+		layer := started
+		started++
+
+		go func() {
+			value, ttl, err := c.caches[layer].GetWithTTL(readCtx, key)
+			results <- &chainReadResult[T]{layer, value, ttl, err}
+		}()
+	}
+
+	var hedge <-chan time.Time
+	startNext := func() {
+		start()
+
+		hedge = nil
+		if c.readStrategy == chainReadHedged && started < len(c.caches) {
+			hedge = time.After(c.hedgeDelay)
+		}
+	}
+
+	if c.readStrategy == chainReadParallel {
+		for started < len(c.caches) {
+			start()
+		}
+	} else {
+		startNext()
+	}
+
+	for {
+		// Layers are checked in order so a value is only returned once all the upper layers missed it
+		for layer, result := range responses {
+			if result == nil {
+				break
+			}
+
+			if result.err == nil {
+				// Set the value back until this cache layer
+				c.queueBackfill(ctx, &chainKeyValue[T]{key, result.value, result.ttl, layer})
+				return result.value, nil
+			}
+
+			if layer == len(responses)-1 {
+				errs := make([]error, len(responses))
+				for i, response := range responses {
+					errs[i] = response.err
+				}
+
+				return result.value, c.getError(errs)
+			}
+		}
+
+		select {
+		case result := <-results:
+			responses[result.layer] = result
+
+			// The last started layer missed, do not wait for the hedge delay to query the next one
+			if result.err != nil && result.layer == started-1 && started < len(c.caches) {
+				startNext()
+			}
+
+		case <-hedge:
+			startNext()
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eko/gocache/v3/store"
+	mocksCache "github.com/eko/gocache/v3/test/mocks/cache"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChainGetWithParallelReadsWhenAvailableInSecondCache(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().GetWithTTL(gomock.Any(), "my-key").
+		DoAndReturn(func(_ context.Context, _ any) (any, time.Duration, error) {
+			time.Sleep(20 * time.Millisecond)
+			return nil, 0, store.NotFound{}
+		})
+	cache1.EXPECT().Set(ctx, "my-key", "my-value", gomock.Any()).Return(nil)
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2.EXPECT().GetWithTTL(gomock.Any(), "my-key").Return("my-value", time.Hour, nil)
+
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainParallelReads[any](),
+		WithChainSyncBackfill[any](),
+	)
+
+	// When
+	value, err := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "my-value", value)
+}
+
+func TestChainGetWithParallelReadsReturnsFirstLayerValue(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().GetWithTTL(gomock.Any(), "my-key").
+		DoAndReturn(func(_ context.Context, _ any) (any, time.Duration, error) {
+			time.Sleep(20 * time.Millisecond)
+			return "my-first-value", time.Hour, nil
+		})
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2.EXPECT().GetWithTTL(gomock.Any(), "my-key").Return("my-second-value", time.Hour, nil)
+
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainParallelReads[any](),
+	)
+
+	// When
+	value, err := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "my-first-value", value)
+}
+
+func TestChainGetWithParallelReadsCancelsLowerLayers(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	cancelled := make(chan struct{})
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().GetWithTTL(gomock.Any(), "my-key").Return("my-value", time.Hour, nil)
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2.EXPECT().GetWithTTL(gomock.Any(), "my-key").
+		DoAndReturn(func(ctx context.Context, _ any) (any, time.Duration, error) {
+			<-ctx.Done()
+			close(cancelled)
+			return nil, 0, ctx.Err()
+		})
+
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainParallelReads[any](),
+	)
+
+	// When
+	value, err := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "my-value", value)
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("request to second cache layer has not been cancelled")
+	}
+}
+
+func TestChainGetWithParallelReadsWhenNotAvailableInAnyCache(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().GetWithTTL(gomock.Any(), "my-key").Return(nil, 0*time.Second,
+		store.NotFoundWithCause(errors.New("unable to find in cache 1")))
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2.EXPECT().GetWithTTL(gomock.Any(), "my-key").Return(nil, 0*time.Second,
+		store.NotFoundWithCause(errors.New("unable to find in cache 2")))
+
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainParallelReads[any](),
+	)
+
+	// When
+	value, err := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, value)
+	assert.Equal(t, store.NotFoundWithCause(errors.New("unable to find in cache 2")), err)
+}
+
+func TestChainGetWithParallelReadsWhenLayerFails(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	unavailableErr := errors.New("unavailable")
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().GetWithTTL(gomock.Any(), "my-key").Return(nil, 0*time.Second, unavailableErr)
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2.EXPECT().GetWithTTL(gomock.Any(), "my-key").Return(nil, 0*time.Second, store.NotFound{})
+
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainParallelReads[any](),
+		WithChainLayerNames[any]("redis", "memcache"),
+	)
+
+	// When
+	value, err := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, value)
+
+	var chainErr *ChainError
+	assert.True(t, errors.As(err, &chainErr))
+	assert.Equal(t, "get", chainErr.Operation)
+	assert.Equal(t, unavailableErr, chainErr.Layer("redis"))
+	assert.True(t, errors.Is(err, store.NotFound{}))
+}
+
+func TestChainGetWithHedgedReads(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().GetWithTTL(gomock.Any(), "my-key").
+		DoAndReturn(func(_ context.Context, _ any) (any, time.Duration, error) {
+			time.Sleep(100 * time.Millisecond)
+			return nil, 0, store.NotFound{}
+		})
+	cache1.EXPECT().Set(ctx, "my-key", "my-value", gomock.Any()).Return(nil)
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2.EXPECT().GetWithTTL(gomock.Any(), "my-key").Return("my-value", time.Hour, nil)
+
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainHedgedReads[any](10*time.Millisecond),
+		WithChainSyncBackfill[any](),
+	)
+
+	// When
+	value, err := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "my-value", value)
+}
+
+func TestChainGetWithHedgedReadsWhenFirstLayerAnswersBeforeDelay(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().GetWithTTL(gomock.Any(), "my-key").Return("my-value", time.Hour, nil)
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainHedgedReads[any](time.Second),
+	)
+
+	// When
+	value, err := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "my-value", value)
+}
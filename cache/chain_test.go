@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand"
 	"testing"
 	"time"
 
+	"github.com/coocood/freecache"
 	"github.com/eko/gocache/v3/store"
 	mocksCache "github.com/eko/gocache/v3/test/mocks/cache"
 	mocksCodec "github.com/eko/gocache/v3/test/mocks/codec"
@@ -140,35 +142,348 @@ func TestChainGetWhenAvailableInSecondCache(t *testing.T) {
 	assert.Equal(t, cacheValue, value)
 }
 
-func TestChainGetWhenNotAvailableInAnyCache(t *testing.T) {
+func TestChainGetWhenAvailableInSecondCacheWithSameStoreType(t *testing.T) {
 	// Given
 	ctrl := gomock.NewController(t)
 
 	ctx := context.Background()
 
+	cacheValue := &struct {
+		Hello string
+	}{
+		Hello: "world",
+	}
+
 	// Cache 1
-	store1 := mocksStore.NewMockStoreInterface(ctrl)
-	store1.EXPECT().GetType().Return("store1")
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().GetWithTTL(ctx, "my-key").Return(nil, 0*time.Second,
+		errors.New("unable to find in cache 1"))
 
-	codec1 := mocksCodec.NewMockCodecInterface(ctrl)
-	codec1.EXPECT().GetStore().Return(store1)
+	// Cache 2
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2.EXPECT().GetWithTTL(ctx, "my-key").Return(cacheValue,
+		10*time.Second, nil)
+
+	backfilled := make(chan struct{})
+	cache1.EXPECT().Set(gomock.Any(), "my-key", cacheValue, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ any, _ any, options ...store.Option) error {
+			assert.Equal(t, 10*time.Second, store.ApplyOptions(options...).Expiration())
+			close(backfilled)
+			return nil
+		})
+
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainLayerNames[any]("regional", "global"),
+	)
+
+	// When
+	value, err := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, cacheValue, value)
+
+	select {
+	case <-backfilled:
+	case <-time.After(time.Second):
+		t.Fatal("first cache layer has not been backfilled")
+	}
+}
+
+func TestChainGetWithSyncBackfill(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().GetWithTTL(ctx, "my-key").Return(nil, 0*time.Second,
+		errors.New("unable to find in cache 1"))
+	cache1.EXPECT().Set(ctx, "my-key", "my-value", gomock.Any()).Return(nil)
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2.EXPECT().GetWithTTL(ctx, "my-key").Return("my-value", 10*time.Second, nil)
+
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainSyncBackfill[any](),
+	)
+
+	// When
+	value, err := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "my-value", value)
+}
+
+func TestChainGetWithLayerMaxBackfillTTL(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().GetWithTTL(ctx, "my-key").Return(nil, 0*time.Second,
+		errors.New("unable to find in cache 1"))
+	cache1.EXPECT().Set(ctx, "my-key", "my-value", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ any, _ any, options ...store.Option) error {
+			assert.Equal(t, time.Minute, store.ApplyOptions(options...).Expiration())
+			return nil
+		})
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2.EXPECT().GetWithTTL(ctx, "my-key").Return("my-value", time.Hour, nil)
+
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainSyncBackfill[any](),
+		WithChainLayerNames[any]("local", "remote"),
+		WithChainLayerMaxBackfillTTL[any]("local", time.Minute),
+	)
+
+	// When
+	value, err := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "my-value", value)
+}
+
+func TestChainGetWithBackfillErrorCallback(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	expectedErr := errors.New("unable to set in cache 1")
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().GetWithTTL(ctx, "my-key").Return(nil, 0*time.Second,
+		errors.New("unable to find in cache 1"))
+	cache1.EXPECT().Set(ctx, "my-key", "my-value", gomock.Any()).Return(expectedErr)
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2.EXPECT().GetWithTTL(ctx, "my-key").Return("my-value", time.Hour, nil)
+
+	var callbackLayer string
+	var callbackErr error
+
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainSyncBackfill[any](),
+		WithChainLayerNames[any]("local", "remote"),
+		WithChainBackfillErrorCallback[any](func(_ context.Context, key any, layer string, err error) {
+			callbackLayer = layer
+			callbackErr = err
+		}),
+	)
+
+	// When
+	value, err := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "my-value", value)
+
+	assert.Equal(t, "local", callbackLayer)
+	assert.Equal(t, expectedErr, callbackErr)
+}
+
+func TestChainGetWithBackfillDropOnFull(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().GetWithTTL(ctx, "my-key").Times(3).Return(nil, 0*time.Second,
+		errors.New("unable to find in cache 1"))
+	gomock.InOrder(
+		cache1.EXPECT().Set(gomock.Any(), "my-key", "my-value", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ any, _ any, _ ...store.Option) error {
+				close(started)
+				<-release
+				return nil
+			}),
+		cache1.EXPECT().Set(gomock.Any(), "my-key", "my-value", gomock.Any()).Return(nil),
+	)
+
+	cache1.EXPECT().Close().Return(nil)
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2.EXPECT().GetWithTTL(ctx, "my-key").Times(3).Return("my-value", time.Hour, nil)
+	cache2.EXPECT().Close().Return(nil)
+
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainBackfillQueueSize[any](1),
+		WithChainBackfillDropOnFull[any](),
+	)
+
+	_, err := cache.Get(ctx, "my-key")
+	assert.Nil(t, err)
+	<-started
+
+	// When
+	_, err = cache.Get(ctx, "my-key")
+	assert.Nil(t, err)
+
+	_, err = cache.Get(ctx, "my-key")
+	assert.Nil(t, err)
+
+	// Then
+	assert.Equal(t, uint64(1), cache.DroppedBackfills())
+
+	close(release)
+	assert.Nil(t, cache.Close())
+}
+
+func TestChainClose(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	backfilled := false
 
 	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
-	cache1.EXPECT().GetCodec().Return(codec1)
 	cache1.EXPECT().GetWithTTL(ctx, "my-key").Return(nil, 0*time.Second,
 		errors.New("unable to find in cache 1"))
+	cache1.EXPECT().Set(gomock.Any(), "my-key", "my-value", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ any, _ any, _ ...store.Option) error {
+			time.Sleep(50 * time.Millisecond)
+			backfilled = true
+			return nil
+		})
+	cache1.EXPECT().Close().Return(nil)
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2.EXPECT().GetWithTTL(ctx, "my-key").Return("my-value", time.Hour, nil)
+	cache2.EXPECT().Close().Return(nil)
+
+	cache := NewChain[any](cache1, cache2)
+
+	_, err := cache.Get(ctx, "my-key")
+	assert.Nil(t, err)
+
+	// When
+	err = cache.Close()
+
+	// Then
+	assert.Nil(t, err)
+	assert.True(t, backfilled)
+
+	_, err = cache.Get(ctx, "my-key")
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.ErrorIs(t, cache.Set(ctx, "my-key", "my-value"), ErrClosed)
+	assert.ErrorIs(t, cache.Delete(ctx, "my-key"), ErrClosed)
+
+	// Closing twice is a no-op
+	assert.Nil(t, cache.Close())
+}
+
+func TestChainCloseWhenDeadlineExceeded(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	release := make(chan struct{})
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().GetWithTTL(ctx, "my-key").Return(nil, 0*time.Second,
+		errors.New("unable to find in cache 1"))
+	cache1.EXPECT().Set(gomock.Any(), "my-key", "my-value", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ any, _ any, _ ...store.Option) error {
+			<-release
+			return nil
+		})
+	cache1.EXPECT().Close().Return(nil)
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2.EXPECT().GetWithTTL(ctx, "my-key").Return("my-value", time.Hour, nil)
+	cache2.EXPECT().Close().Return(nil)
+
+	cache := NewChain[any](cache1, cache2)
+
+	_, err := cache.Get(ctx, "my-key")
+	assert.Nil(t, err)
+
+	closeCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	// When
+	err = cache.CloseWithContext(closeCtx)
+
+	// Then
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+}
+
+func TestChainGetBackfillsWithExpirationJitter(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	// Cache 1 randomises expirations by up to one hour
+	store1 := store.NewFreecache(freecache.NewCache(1024*1024), store.WithExpirationJitter(time.Hour))
+	cache1 := New[[]byte](store1)
 
 	// Cache 2
 	store2 := mocksStore.NewMockStoreInterface(ctrl)
-	store2.EXPECT().GetType().Return("store2")
+	store2.EXPECT().GetType().AnyTimes().Return("store2")
 
 	codec2 := mocksCodec.NewMockCodecInterface(ctrl)
-	codec2.EXPECT().GetStore().Return(store2)
+	codec2.EXPECT().GetStore().AnyTimes().Return(store2)
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[[]byte](ctrl)
+	cache2.EXPECT().GetCodec().AnyTimes().Return(codec2)
+	cache2.EXPECT().GetWithTTL(ctx, "my-key").Return([]byte("my-value"), time.Hour, nil)
+
+	store.SetJitterSource(rand.NewSource(42))
+	expectedJitter := time.Duration(rand.New(rand.NewSource(42)).Int63n(int64(time.Hour)))
+
+	cache := NewChain[[]byte](cache1, cache2)
+
+	// When
+	value, err := cache.Get(ctx, "my-key")
+
+	// Wait for data to be processed
+	time.Sleep(100 * time.Millisecond)
 
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("my-value"), value)
+
+	// Freecache expirations have a one second granularity
+	_, ttl, err := cache1.GetWithTTL(ctx, "my-key")
+	assert.Nil(t, err)
+	assert.Greater(t, ttl, time.Hour+expectedJitter-2*time.Second)
+	assert.LessOrEqual(t, ttl, time.Hour+expectedJitter)
+}
+
+func TestChainGetWhenNotAvailableInAnyCache(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	// Cache 1
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().GetWithTTL(ctx, "my-key").Return(nil, 0*time.Second,
+		store.NotFoundWithCause(errors.New("unable to find in cache 1")))
+
+	// Cache 2
 	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
-	cache2.EXPECT().GetCodec().Return(codec2)
 	cache2.EXPECT().GetWithTTL(ctx, "my-key").Return(nil, 0*time.Second,
-		errors.New("unable to find in cache 2"))
+		store.NotFoundWithCause(errors.New("unable to find in cache 2")))
 
 	cache := NewChain[any](cache1, cache2)
 
@@ -179,10 +494,44 @@ func TestChainGetWhenNotAvailableInAnyCache(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 
 	// Then
-	assert.Equal(t, errors.New("unable to find in cache 2"), err)
+	assert.Equal(t, store.NotFoundWithCause(errors.New("unable to find in cache 2")), err)
 	assert.Equal(t, nil, value)
 }
 
+func TestChainGetWhenLayerFails(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	unavailableErr := errors.New("unavailable")
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().GetWithTTL(ctx, "my-key").Return(nil, 0*time.Second, unavailableErr)
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2.EXPECT().GetWithTTL(ctx, "my-key").Return(nil, 0*time.Second, store.NotFound{})
+
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainLayerNames[any]("redis", "memcache"),
+	)
+
+	// When
+	value, err := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, value)
+
+	var chainErr *ChainError
+	assert.True(t, errors.As(err, &chainErr))
+	assert.Equal(t, "get", chainErr.Operation)
+	assert.Equal(t, unavailableErr, chainErr.Layer("redis"))
+	assert.Equal(t, store.NotFound{}, chainErr.Layer("memcache"))
+	assert.True(t, errors.Is(err, unavailableErr))
+	assert.True(t, errors.Is(err, store.NotFound{}))
+}
+
 func TestChainSet(t *testing.T) {
 	// Given
 	ctrl := gomock.NewController(t)
@@ -238,7 +587,14 @@ func TestChainSetWhenErrorOnSetting(t *testing.T) {
 	cache1.EXPECT().Set(ctx, "my-key", cacheValue).Return(expectedErr)
 
 	// Cache 2
+	store2 := mocksStore.NewMockStoreInterface(ctrl)
+	store2.EXPECT().GetType().Return("store2")
+
+	codec2 := mocksCodec.NewMockCodecInterface(ctrl)
+	codec2.EXPECT().GetStore().Return(store2)
+
 	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2.EXPECT().GetCodec().Return(codec2)
 	cache2.EXPECT().Set(ctx, "my-key", cacheValue)
 
 	cache := NewChain[any](cache1, cache2)
@@ -247,8 +603,116 @@ func TestChainSetWhenErrorOnSetting(t *testing.T) {
 	err := cache.Set(ctx, "my-key", cacheValue)
 
 	// Then
-	assert.Error(t, err)
-	assert.Equal(t, fmt.Sprintf("error 1 of 1: Unable to set item into cache with store 'store1': %s", expectedErr.Error()), err.Error())
+	assert.ErrorIs(t, err, expectedErr)
+	assert.Equal(t, fmt.Sprintf("chain cache set failed: error 1 of 1: layer 'store1': %s", expectedErr.Error()), err.Error())
+}
+
+func TestChainSetWhenErrorOnSettingWithLayerNames(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	expectedErr := errors.New("an unexpected error occurred while setting data")
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().Set(ctx, "my-key", "my-value")
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2.EXPECT().Set(ctx, "my-key", "my-value").Return(expectedErr)
+
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainLayerNames[any]("regional", "global"),
+	)
+
+	// When
+	err := cache.Set(ctx, "my-key", "my-value")
+
+	// Then
+	var chainErr *ChainError
+	assert.ErrorAs(t, err, &chainErr)
+	assert.Equal(t, "set", chainErr.Operation)
+	assert.Nil(t, chainErr.Layer("regional"))
+	assert.Equal(t, expectedErr, chainErr.Layer("global"))
+	assert.Equal(t, fmt.Sprintf("chain cache set failed: error 1 of 1: layer 'global': %s", expectedErr.Error()), err.Error())
+}
+
+func TestChainGetLayerNames(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	store2 := mocksStore.NewMockStoreInterface(ctrl)
+	store2.EXPECT().GetType().Return("redis")
+
+	codec2 := mocksCodec.NewMockCodecInterface(ctrl)
+	codec2.EXPECT().GetStore().Return(store2)
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2.EXPECT().GetCodec().Return(codec2)
+
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainLayerNames[any]("local"),
+	)
+
+	// When
+	names := cache.GetLayerNames()
+
+	// Then
+	assert.Equal(t, []string{"local", "redis"}, names)
+}
+
+func TestNewChainWithOptionsWhenLayerNamesAreNotUnique(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache3 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+
+	caches := []SetterCacheInterface[any]{cache1, cache2, cache3}
+
+	// When - Then
+	assert.Panics(t, func() {
+		NewChainWithOptions[any](caches, WithChainLayerNames[any]("redis", "local", "redis"))
+	})
+	assert.NotPanics(t, func() {
+		NewChainWithOptions[any](caches, WithChainLayerNames[any]("", "local", ""))
+	})
+}
+
+func TestChainGetLayerNamesWhenLayersHaveSameStoreType(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	caches := make([]SetterCacheInterface[any], 0, 3)
+	for _, storeType := range []string{"go-cache", "redis", "redis"} {
+		store := mocksStore.NewMockStoreInterface(ctrl)
+		store.EXPECT().GetType().Return(storeType)
+
+		codec := mocksCodec.NewMockCodecInterface(ctrl)
+		codec.EXPECT().GetStore().Return(store)
+
+		cache := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+		cache.EXPECT().GetCodec().Return(codec)
+
+		caches = append(caches, cache)
+	}
+
+	cache := NewChainWithOptions[any](
+		caches,
+		WithChainLayerMaxBackfillTTL[any]("redis#1", time.Minute),
+	)
+
+	// When
+	names := cache.GetLayerNames()
+
+	// Then
+	assert.Equal(t, []string{"go-cache", "redis#1", "redis#2"}, names)
+	assert.Equal(t, time.Minute, cache.maxBackfillTTLs[cache.layerName(1)])
 }
 
 func TestChainDelete(t *testing.T) {
@@ -281,20 +745,53 @@ func TestChainDeleteWhenError(t *testing.T) {
 	ctx := context.Background()
 
 	// Cache 1
+	expectedErr := errors.New("an error has occurred while deleting key")
+
 	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
-	cache1.EXPECT().Delete(ctx, "my-key").Return(errors.New("an error has occurred while deleting key"))
+	cache1.EXPECT().Delete(ctx, "my-key").Return(expectedErr)
 
 	// Cache 2
 	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
 	cache2.EXPECT().Delete(ctx, "my-key").Return(nil)
 
-	cache := NewChain[any](cache1, cache2)
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainLayerNames[any]("local", "remote"),
+	)
 
 	// When
 	err := cache.Delete(ctx, "my-key")
 
 	// Then
-	assert.Nil(t, err)
+	assert.ErrorIs(t, err, expectedErr)
+	assert.Equal(t, "chain cache delete failed: error 1 of 1: layer 'local': an error has occurred while deleting key", err.Error())
+}
+
+func TestChainDeleteWithFailFast(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	expectedErr := errors.New("an error has occurred while deleting key")
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().Delete(ctx, "my-key").Return(expectedErr)
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainLayerNames[any]("local", "remote"),
+		WithChainFailFast[any](),
+	)
+
+	// When
+	err := cache.Delete(ctx, "my-key")
+
+	// Then
+	assert.ErrorIs(t, err, expectedErr)
+	assert.Equal(t, expectedErr, err.(*ChainError).Layer("local"))
 }
 
 func TestChainInvalidate(t *testing.T) {
@@ -327,20 +824,26 @@ func TestChainInvalidateWhenError(t *testing.T) {
 	ctx := context.Background()
 
 	// Cache 1
+	expectedErr := errors.New("an unexpected error has occurred while invalidation data")
+
 	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
-	cache1.EXPECT().Invalidate(ctx).Return(errors.New("an unexpected error has occurred while invalidation data"))
+	cache1.EXPECT().Invalidate(ctx).Return(expectedErr)
 
 	// Cache 2
 	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
 	cache2.EXPECT().Invalidate(ctx).Return(nil)
 
-	cache := NewChain[any](cache1, cache2)
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainLayerNames[any]("local", "remote"),
+	)
 
 	// When
 	err := cache.Invalidate(ctx)
 
 	// Then
-	assert.Nil(t, err)
+	assert.ErrorIs(t, err, expectedErr)
+	assert.Equal(t, "chain cache invalidate failed: error 1 of 1: layer 'local': an unexpected error has occurred while invalidation data", err.Error())
 }
 
 func TestChainClear(t *testing.T) {
@@ -373,20 +876,26 @@ func TestChainClearWhenError(t *testing.T) {
 	ctx := context.Background()
 
 	// Cache 1
+	expectedErr := errors.New("an unexpected error has occurred while invalidation data")
+
 	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
-	cache1.EXPECT().Clear(ctx).Return(errors.New("an unexpected error has occurred while invalidation data"))
+	cache1.EXPECT().Clear(ctx).Return(expectedErr)
 
 	// Cache 2
 	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
 	cache2.EXPECT().Clear(ctx).Return(nil)
 
-	cache := NewChain[any](cache1, cache2)
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainLayerNames[any]("local", "remote"),
+	)
 
 	// When
 	err := cache.Clear(ctx)
 
 	// Then
-	assert.Nil(t, err)
+	assert.ErrorIs(t, err, expectedErr)
+	assert.Equal(t, "chain cache clear failed: error 1 of 1: layer 'local': an unexpected error has occurred while invalidation data", err.Error())
 }
 
 func TestChainGetType(t *testing.T) {
@@ -441,6 +950,11 @@ func TestChainSetWhenErrorInChain(t *testing.T) {
 
 	store2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
 
+	store2.EXPECT().GetType().AnyTimes().Return("store2")
+	codec2 := mocksCodec.NewMockCodecInterface(ctrl)
+	codec2.EXPECT().GetStore().AnyTimes().Return(store2)
+	store2.EXPECT().GetCodec().AnyTimes().Return(codec2)
+
 	cache := NewChain[any](store1, store2)
 
 	// assert store2 set is called
@@ -449,7 +963,10 @@ func TestChainSetWhenErrorInChain(t *testing.T) {
 	// When - Then
 	err := cache.Set(ctx, key, value, nil)
 
-	expErr := errors.New("error 1 of 1: Unable to set item into cache with store 'store1': an issue occurred with the cache")
+	expErr := &ChainError{
+		Operation: "set",
+		Errors:    []*ChainLayerError{{Layer: "store1", Err: interError}},
+	}
 	// Then
 	assert.Equal(t, expErr, err)
 }
@@ -0,0 +1,252 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/eko/gocache/v3/store"
+)
+
+// ChainWritePolicy represents the way a chain cache writes values into its layers
+type ChainWritePolicy int
+
+const (
+	// ChainWriteThrough writes values into all the cache layers before returning
+	ChainWriteThrough ChainWritePolicy = iota
+	// ChainWriteAround writes values into the last cache layer only and deletes them
+	// from the upper layers, which are filled back on next reads
+	ChainWriteAround
+	// ChainWriteBack writes values into the first cache layer before returning and
+	// writes them asynchronously into the lower layers, retrying on failure.
+	// Pending writes are kept in memory (see WithChainWriteBackQueueSize): Close flushes
+	// them and returns an error wrapping ErrChainWriteBackDropped when some of them could
+	// not be written, but they are lost if the process stops without closing the chain.
+	// Writes still failing after the last attempt are dropped (see WithChainWriteBackDropCallback).
+	ChainWriteBack
+)
+
+// defaultChainWriteBackQueueSize is the default number of values waiting to be written
+// into the lower cache layers when using the ChainWriteBack policy
+const defaultChainWriteBackQueueSize = 10000
+
+// ErrChainWriteBackDropped is returned by Close when values waiting to be written into
+// the lower cache layers could not be written
+var ErrChainWriteBackDropped = errors.New("values have not been written into lower cache layers")
+
+type chainWrite[T any] struct {
+	key     any
+	value   T
+	options []store.Option
+	layers  []int
+	attempt int
+}
+
+// layerSetOptions returns the options to use to write into the cache layer at the given index
+func (c *ChainCache[T]) layerSetOptions(index int, options []store.Option) []store.Option {
+	if len(c.layerOptions) == 0 {
+		return options
+	}
+
+	layerOptions, ok := c.layerOptions[c.layerName(index)]
+	if !ok {
+		return options
+	}
+
+	return append(append([]store.Option{}, options...), layerOptions...)
+}
+
+// setLayer writes a value into the cache layer at the given index
+func (c *ChainCache[T]) setLayer(ctx context.Context, index int, key any, object T, options []store.Option) *ChainLayerError {
+	err := c.caches[index].Set(ctx, key, object, c.layerSetOptions(index, options)...)
+	if err != nil {
+		return c.layerError(index, err)
+	}
+
+	return nil
+}
+
+// setLayers writes a value into the cache layers from the given index, one after another
+func (c *ChainCache[T]) setLayers(ctx context.Context, from int, key any, object T, options []store.Option) []*ChainLayerError {
+	var errs []*ChainLayerError
+
+	for i := from; i < len(c.caches); i++ {
+		if err := c.setLayer(ctx, i, key, object, options); err != nil {
+			errs = append(errs, err)
+
+			if c.failFast {
+				break
+			}
+		}
+	}
+
+	return errs
+}
+
+// writeThrough writes a value into all the cache layers
+func (c *ChainCache[T]) writeThrough(ctx context.Context, key any, object T, options []store.Option) []*ChainLayerError {
+	if !c.parallelWrites {
+		return c.setLayers(ctx, 0, key, object, options)
+	}
+
+	layerErrs := make([]*ChainLayerError, len(c.caches))
+
+	var wg sync.WaitGroup
+	for i := range c.caches {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			layerErrs[i] = c.setLayer(ctx, i, key, object, options)
+		}(i)
+	}
+	wg.Wait()
+
+	var errs []*ChainLayerError
+	for _, err := range layerErrs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+// writeAround writes a value into the last cache layer and deletes it from the upper ones
+func (c *ChainCache[T]) writeAround(ctx context.Context, key any, object T, options []store.Option) []*ChainLayerError {
+	if len(c.caches) == 0 {
+		return nil
+	}
+
+	last := len(c.caches) - 1
+
+	if err := c.setLayer(ctx, last, key, object, options); err != nil {
+		return []*ChainLayerError{err}
+	}
+
+	var errs []*ChainLayerError
+	for i, cache := range c.caches[:last] {
+		if err := cache.Delete(ctx, key); err != nil {
+			errs = append(errs, c.layerError(i, err))
+
+			if c.failFast {
+				break
+			}
+		}
+	}
+
+	return errs
+}
+
+// writeBack writes a value into the first cache layer and queues the write of the lower ones
+func (c *ChainCache[T]) writeBack(ctx context.Context, key any, object T, options []store.Option) []*ChainLayerError {
+	if len(c.caches) == 0 {
+		return nil
+	}
+
+	if err := c.setLayer(ctx, 0, key, object, options); err != nil {
+		return []*ChainLayerError{err}
+	}
+
+	layers := make([]int, 0, len(c.caches)-1)
+	for i := 1; i < len(c.caches); i++ {
+		layers = append(layers, i)
+	}
+
+	if len(layers) == 0 || c.queueWriteBack(&chainWrite[T]{key: key, value: object, options: options, layers: layers, attempt: 1}) {
+		return nil
+	}
+
+	// Queue is full or chain is closed, write the lower layers synchronously instead of losing the value
+	return c.setLayers(ctx, 1, key, object, options)
+}
+
+// queueWriteBack sends a value to the write-back queue and returns false when it cannot be queued
+func (c *ChainCache[T]) queueWriteBack(item *chainWrite[T]) bool {
+	queued := false
+	c.lifecycle.do(func() {
+		select {
+		case c.writeBackQueue <- item:
+			queued = true
+		default:
+		}
+	})
+
+	return queued
+}
+
+// writeBacker writes queued values into the lower cache layers
+func (c *ChainCache[T]) writeBacker() {
+	for item := range c.writeBackQueue {
+		c.writeBackLayers(item)
+	}
+}
+
+// writeBackLayers writes a value into the lower cache layers of the given write. Failed
+// writes are retried in background after the write-back backoff, so they do not delay
+// the next queued values, until the maximum number of attempts is reached.
+func (c *ChainCache[T]) writeBackLayers(item *chainWrite[T]) {
+	ctx := context.Background()
+
+	if c.lifecycle.abandoned() {
+		for _, i := range item.layers {
+			c.dropWriteBack(ctx, item, i, ErrClosed)
+		}
+		return
+	}
+
+	var failed []int
+	for _, i := range item.layers {
+		err := c.setLayer(ctx, i, item.key, item.value, item.options)
+		if err == nil {
+			continue
+		}
+
+		if item.attempt >= c.writeBackMaxAttempts {
+			c.dropWriteBack(ctx, item, i, err.Err)
+			continue
+		}
+
+		failed = append(failed, i)
+	}
+
+	if len(failed) == 0 {
+		return
+	}
+
+	retry := *item
+	retry.layers = failed
+	retry.attempt++
+
+	c.lifecycle.after(c.writeBackBackoff, func() {
+		c.writeBackLayers(&retry)
+	})
+}
+
+// dropWriteBack gives up writing a value into the given cache layer
+func (c *ChainCache[T]) dropWriteBack(ctx context.Context, item *chainWrite[T], layer int, err error) {
+	atomic.AddUint64(&c.droppedWriteBacks, 1)
+
+	if c.writeBackDropCallback != nil {
+		c.writeBackDropCallback(ctx, item.key, c.layerName(layer), err)
+	}
+}
+
+// flushWriteBacksError returns an error when values have been dropped since the given number
+// of dropped values, while flushing the write-back queue on close
+func (c *ChainCache[T]) flushWriteBacksError(droppedBefore uint64) error {
+	dropped := c.DroppedWriteBacks() - droppedBefore
+	if dropped == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %d dropped while closing", ErrChainWriteBackDropped, dropped)
+}
+
+// DroppedWriteBacks returns the number of values that have not been written into a lower
+// cache layer when using the ChainWriteBack policy, because all the attempts failed or
+// because the chain has been closed before
+func (c *ChainCache[T]) DroppedWriteBacks() uint64 {
+	return atomic.LoadUint64(&c.droppedWriteBacks)
+}
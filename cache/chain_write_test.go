@@ -0,0 +1,356 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eko/gocache/v3/store"
+	mocksCache "github.com/eko/gocache/v3/test/mocks/cache"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChainSetWithParallelWrites(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+
+	setFunc := func(_ context.Context, _ any, _ any, _ ...store.Option) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().Set(ctx, "my-key", "my-value").DoAndReturn(setFunc)
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2.EXPECT().Set(ctx, "my-key", "my-value").DoAndReturn(setFunc)
+
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainParallelWrites[any](),
+	)
+
+	// When
+	done := make(chan error)
+	go func() {
+		done <- cache.Set(ctx, "my-key", "my-value")
+	}()
+
+	// Then
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("cache layers have not been written concurrently")
+		}
+	}
+	close(release)
+
+	assert.Nil(t, <-done)
+}
+
+func TestChainSetWithWriteAround(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().Delete(ctx, "my-key").Return(nil)
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2.EXPECT().Delete(ctx, "my-key").Return(nil)
+
+	cache3 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache3.EXPECT().Set(ctx, "my-key", "my-value").Return(nil)
+
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2, cache3},
+		WithChainWritePolicy[any](ChainWriteAround),
+	)
+
+	// When
+	err := cache.Set(ctx, "my-key", "my-value")
+
+	// Then
+	assert.Nil(t, err)
+}
+
+func TestChainSetWithWriteAroundWhenError(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2.EXPECT().Set(ctx, "my-key", "my-value").Return(errors.New("unavailable"))
+
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainWritePolicy[any](ChainWriteAround),
+		WithChainLayerNames[any]("local", "remote"),
+	)
+
+	// When
+	err := cache.Set(ctx, "my-key", "my-value")
+
+	// Then
+	assert.EqualError(t, err, "chain cache set failed: error 1 of 1: layer 'remote': unavailable")
+}
+
+func TestChainSetWithWriteBack(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().Set(ctx, "my-key", "my-value").Return(nil)
+
+	written := make(chan struct{})
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	gomock.InOrder(
+		cache2.EXPECT().Set(gomock.Any(), "my-key", "my-value").Return(errors.New("unavailable")),
+		cache2.EXPECT().Set(gomock.Any(), "my-key", "my-value").DoAndReturn(func(_ context.Context, _ any, _ any, _ ...store.Option) error {
+			close(written)
+			return nil
+		}),
+	)
+
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainWritePolicy[any](ChainWriteBack),
+		WithChainWriteBackRetry[any](3, time.Millisecond),
+		WithChainLayerNames[any]("local", "remote"),
+	)
+
+	// When
+	err := cache.Set(ctx, "my-key", "my-value")
+
+	// Then
+	assert.Nil(t, err)
+
+	select {
+	case <-written:
+	case <-time.After(time.Second):
+		t.Fatal("second cache layer has not been written back")
+	}
+}
+
+func TestChainSetWithWriteBackWhenAttemptsFail(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().Set(ctx, "my-key", "my-value").Return(nil)
+	cache1.EXPECT().Close().Return(nil)
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2.EXPECT().Set(gomock.Any(), "my-key", "my-value").Return(errors.New("unavailable")).Times(2)
+	cache2.EXPECT().Close().Return(nil)
+
+	var droppedKey any
+	var droppedLayer string
+	var droppedErr error
+
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainWritePolicy[any](ChainWriteBack),
+		WithChainWriteBackRetry[any](2, time.Millisecond),
+		WithChainWriteBackDropCallback[any](func(_ context.Context, key any, layer string, err error) {
+			droppedKey, droppedLayer, droppedErr = key, layer, err
+		}),
+		WithChainLayerNames[any]("local", "remote"),
+	)
+
+	// When
+	err := cache.Set(ctx, "my-key", "my-value")
+	closeErr := cache.Close()
+
+	// Then
+	assert.Nil(t, err)
+	assert.True(t, errors.Is(closeErr, ErrChainWriteBackDropped))
+	assert.EqualError(t, closeErr, "values have not been written into lower cache layers: 1 dropped while closing")
+	assert.Equal(t, uint64(1), cache.DroppedWriteBacks())
+	assert.Equal(t, "my-key", droppedKey)
+	assert.Equal(t, "remote", droppedLayer)
+	assert.EqualError(t, droppedErr, "unavailable")
+}
+
+func TestChainSetWithWriteBackDoesNotDelayNextValuesOnRetry(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().Set(ctx, gomock.Any(), "my-value").Return(nil).Times(2)
+	cache1.EXPECT().Close().Return(nil)
+
+	var mutex sync.Mutex
+	var written []any
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2.EXPECT().Set(gomock.Any(), gomock.Any(), "my-value").DoAndReturn(func(_ context.Context, key any, _ any, _ ...store.Option) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		written = append(written, key)
+		if key == "failing-key" {
+			return errors.New("unavailable")
+		}
+		return nil
+	}).Times(4)
+	cache2.EXPECT().Close().Return(nil)
+
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainWritePolicy[any](ChainWriteBack),
+		WithChainWriteBackRetry[any](3, 50*time.Millisecond),
+		WithChainLayerNames[any]("local", "remote"),
+	)
+
+	// When
+	err1 := cache.Set(ctx, "failing-key", "my-value")
+	err2 := cache.Set(ctx, "my-key", "my-value")
+	closeErr := cache.Close()
+
+	// Then
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.True(t, errors.Is(closeErr, ErrChainWriteBackDropped))
+	assert.Equal(t, []any{"failing-key", "my-key", "failing-key", "failing-key"}, written)
+	assert.Equal(t, uint64(1), cache.DroppedWriteBacks())
+}
+
+func TestChainSetWithWriteBackWhenQueueIsFull(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().Set(ctx, gomock.Any(), "my-value").Return(nil).Times(3)
+	cache1.EXPECT().Close().Return(nil)
+
+	var mutex sync.Mutex
+	var written []any
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2.EXPECT().Set(gomock.Any(), gomock.Any(), "my-value").DoAndReturn(func(_ context.Context, key any, _ any, _ ...store.Option) error {
+		if key == "blocking-key" {
+			close(started)
+			<-release
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		written = append(written, key)
+		return nil
+	}).Times(3)
+	cache2.EXPECT().Close().Return(nil)
+
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainWritePolicy[any](ChainWriteBack),
+		WithChainWriteBackQueueSize[any](1),
+		WithChainLayerNames[any]("local", "remote"),
+	)
+
+	assert.Nil(t, cache.Set(ctx, "blocking-key", "my-value"))
+	<-started
+
+	// When
+	queuedErr := cache.Set(ctx, "queued-key", "my-value")
+	syncErr := cache.Set(ctx, "sync-key", "my-value")
+
+	// Then
+	assert.Nil(t, queuedErr)
+	assert.Nil(t, syncErr)
+
+	mutex.Lock()
+	assert.Equal(t, []any{"sync-key"}, written)
+	mutex.Unlock()
+
+	close(release)
+	assert.Nil(t, cache.Close())
+	assert.Equal(t, []any{"sync-key", "blocking-key", "queued-key"}, written)
+}
+
+func TestChainSetWithWriteBackWhenFirstLayerError(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().Set(ctx, "my-key", "my-value").Return(errors.New("unavailable"))
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainWritePolicy[any](ChainWriteBack),
+		WithChainLayerNames[any]("local", "remote"),
+	)
+
+	// When
+	err := cache.Set(ctx, "my-key", "my-value")
+
+	// Wait for data to be processed
+	time.Sleep(50 * time.Millisecond)
+
+	// Then
+	assert.EqualError(t, err, "chain cache set failed: error 1 of 1: layer 'local': unavailable")
+}
+
+func TestChainSetWithLayerOptions(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().Set(ctx, "my-key", "my-value", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ any, _ any, options ...store.Option) error {
+			assert.Equal(t, time.Minute, store.ApplyOptions(options...).Expiration())
+			return nil
+		})
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2.EXPECT().Set(ctx, "my-key", "my-value", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ any, _ any, options ...store.Option) error {
+			assert.Equal(t, time.Hour, store.ApplyOptions(options...).Expiration())
+			return nil
+		})
+
+	cache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainLayerNames[any]("local", "remote"),
+		WithChainLayerOptions[any]("local", store.WithExpiration(time.Minute)),
+	)
+
+	// When
+	err := cache.Set(ctx, "my-key", "my-value", store.WithExpiration(time.Hour))
+
+	// Then
+	assert.Nil(t, err)
+}
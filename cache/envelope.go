@@ -0,0 +1,247 @@
+package cache
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"sync/atomic"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// EnvelopeChecksum represents the algorithm used to compute the checksum of enveloped values
+type EnvelopeChecksum byte
+
+const (
+	// EnvelopeCRC32C computes checksums using CRC-32 with the Castagnoli polynomial
+	EnvelopeCRC32C EnvelopeChecksum = 0x01
+	// EnvelopeXXHash computes checksums using 64-bit xxHash
+	EnvelopeXXHash EnvelopeChecksum = 0x02
+
+	envelopeMagic   byte = 0xE7
+	envelopeVersion byte = 0x01
+	// envelopeKeyVersion is the version of envelopes recording the original key
+	// of a normalized key, between the header and the payload
+	envelopeKeyVersion byte = 0x02
+	// envelopeMetadataVersion is the version of envelopes recording the original key (possibly
+	// empty), the load duration and the expiration date of the value, between the header and the payload
+	envelopeMetadataVersion byte = 0x03
+
+	// magic, envelope version, schema version, checksum algorithm, checksum, created at
+	envelopeHeaderSize = 1 + 1 + 4 + 1 + 8 + 8
+)
+
+var (
+	// ErrEnvelopeVersionMismatch is the cause of the NotFound error returned when a value
+	// has been set with another schema version
+	ErrEnvelopeVersionMismatch = errors.New("value schema version mismatch")
+	// ErrEnvelopeCorrupted is the cause of the NotFound error returned when a value
+	// is not enveloped or its checksum does not match
+	ErrEnvelopeCorrupted = errors.New("value is corrupted")
+	// ErrKeyCollision is the cause of the NotFound error returned when a value has been
+	// set for another key having the same normalized key
+	ErrKeyCollision = errors.New("normalized key collision")
+
+	crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// EnvelopeStats represents the number of invalid values read from the store
+type EnvelopeStats struct {
+	VersionMismatches uint64
+	Corruptions       uint64
+	KeyCollisions     uint64
+}
+
+// EnvelopeOption represents an envelope option function
+type EnvelopeOption func(o *envelope)
+
+// WithEnvelopeChecksum allows setting the checksum algorithm. Default is CRC32C.
+func WithEnvelopeChecksum(checksum EnvelopeChecksum) EnvelopeOption {
+	return func(o *envelope) {
+		o.checksum = checksum
+	}
+}
+
+// WithEnvelopeDeleteInvalid allows deleting values from the store when they have
+// another schema version or are corrupted
+func WithEnvelopeDeleteInvalid() EnvelopeOption {
+	return func(o *envelope) {
+		o.deleteInvalid = true
+	}
+}
+
+// WithEnvelope allows wrapping the encoded values in an envelope holding the given
+// schema version, a checksum and the creation date. Values having another schema
+// version or a checksum that does not match are returned as misses.
+// Values are encoded using msgpack when no serializer is given.
+func WithEnvelope[T any](version uint32, options ...EnvelopeOption) CacheOption[T] {
+	return func(o *Cache[T]) {
+		o.envelope = &envelope{
+			version:  version,
+			checksum: EnvelopeCRC32C,
+			now:      time.Now,
+		}
+
+		for _, option := range options {
+			option(o.envelope)
+		}
+	}
+}
+
+// envelopeMetadata holds what is needed to refresh a value early, recorded alongside
+// the value so every instance reading it can use it
+type envelopeMetadata struct {
+	loadDuration time.Duration
+	// expiresAt is zero when the value has no explicit expiration
+	expiresAt time.Time
+}
+
+type envelope struct {
+	version       uint32
+	checksum      EnvelopeChecksum
+	deleteInvalid bool
+	now           func() time.Time
+
+	versionMismatches uint64
+	corruptions       uint64
+	keyCollisions     uint64
+}
+
+// sealWithMetadata wraps the given payload into an envelope, recording the given metadata if any.
+// The original key is recorded when not empty, so collisions of normalized keys can be detected.
+func (e *envelope) sealWithMetadata(payload []byte, originalKey string, metadata *envelopeMetadata) []byte {
+	data := make([]byte, envelopeHeaderSize, envelopeHeaderSize+3*binary.MaxVarintLen64+len(originalKey)+len(payload))
+	data[0] = envelopeMagic
+	data[1] = envelopeVersion
+	binary.BigEndian.PutUint32(data[2:6], e.version)
+	data[6] = byte(e.checksum)
+	binary.BigEndian.PutUint64(data[15:23], uint64(e.now().UnixNano()))
+
+	varint := make([]byte, binary.MaxVarintLen64)
+
+	if originalKey != "" || metadata != nil {
+		data[1] = envelopeKeyVersion
+		data = append(data, varint[:binary.PutUvarint(varint, uint64(len(originalKey)))]...)
+		data = append(data, originalKey...)
+	}
+
+	if metadata != nil {
+		data[1] = envelopeMetadataVersion
+
+		var expiresAt int64
+		if !metadata.expiresAt.IsZero() {
+			expiresAt = metadata.expiresAt.UnixNano()
+		}
+
+		data = append(data, varint[:binary.PutUvarint(varint, uint64(metadata.loadDuration))]...)
+		data = append(data, varint[:binary.PutVarint(varint, expiresAt)]...)
+	}
+
+	data = append(data, payload...)
+
+	binary.BigEndian.PutUint64(data[7:15], computeEnvelopeChecksum(e.checksum, data))
+
+	return data
+}
+
+// openWithMetadata checks the given envelope and returns its payload and its metadata,
+// which is nil when the envelope does not record any. An error is returned if the
+// envelope records an original key other than the given key.
+func (e *envelope) openWithMetadata(value any, key string) ([]byte, *envelopeMetadata, error) {
+	var data []byte
+
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return nil, nil, e.corrupted(fmt.Errorf("%w: unexpected %T value in store", ErrEnvelopeCorrupted, value))
+	}
+
+	if len(data) < envelopeHeaderSize || data[0] != envelopeMagic ||
+		data[1] < envelopeVersion || data[1] > envelopeMetadataVersion {
+		return nil, nil, e.corrupted(fmt.Errorf("%w: invalid envelope header", ErrEnvelopeCorrupted))
+	}
+
+	expected := binary.BigEndian.Uint64(data[7:15])
+	if computeEnvelopeChecksum(EnvelopeChecksum(data[6]), data) != expected {
+		return nil, nil, e.corrupted(fmt.Errorf("%w: checksum mismatch", ErrEnvelopeCorrupted))
+	}
+
+	if version := binary.BigEndian.Uint32(data[2:6]); version != e.version {
+		atomic.AddUint64(&e.versionMismatches, 1)
+		return nil, nil, fmt.Errorf("%w: got %d, expected %d", ErrEnvelopeVersionMismatch, version, e.version)
+	}
+
+	payload := data[envelopeHeaderSize:]
+
+	if data[1] >= envelopeKeyVersion {
+		length, n := binary.Uvarint(payload)
+		if n <= 0 || uint64(len(payload)-n) < length {
+			return nil, nil, e.corrupted(fmt.Errorf("%w: invalid original key", ErrEnvelopeCorrupted))
+		}
+
+		if originalKey := string(payload[n : n+int(length)]); originalKey != "" && originalKey != key {
+			atomic.AddUint64(&e.keyCollisions, 1)
+			return nil, nil, fmt.Errorf("%w: value has been set for key %q", ErrKeyCollision, originalKey)
+		}
+
+		payload = payload[n+int(length):]
+	}
+
+	if data[1] < envelopeMetadataVersion {
+		return payload, nil, nil
+	}
+
+	loadDuration, n := binary.Uvarint(payload)
+	if n <= 0 {
+		return nil, nil, e.corrupted(fmt.Errorf("%w: invalid metadata", ErrEnvelopeCorrupted))
+	}
+	payload = payload[n:]
+
+	expiresAt, n := binary.Varint(payload)
+	if n <= 0 {
+		return nil, nil, e.corrupted(fmt.Errorf("%w: invalid metadata", ErrEnvelopeCorrupted))
+	}
+	payload = payload[n:]
+
+	metadata := &envelopeMetadata{loadDuration: time.Duration(loadDuration)}
+	if expiresAt != 0 {
+		metadata.expiresAt = time.Unix(0, expiresAt)
+	}
+
+	return payload, metadata, nil
+}
+
+func (e *envelope) corrupted(err error) error {
+	atomic.AddUint64(&e.corruptions, 1)
+	return err
+}
+
+func (e *envelope) stats() EnvelopeStats {
+	return EnvelopeStats{
+		VersionMismatches: atomic.LoadUint64(&e.versionMismatches),
+		Corruptions:       atomic.LoadUint64(&e.corruptions),
+		KeyCollisions:     atomic.LoadUint64(&e.keyCollisions),
+	}
+}
+
+// computeEnvelopeChecksum computes the checksum of the given envelope, excluding the checksum field
+func computeEnvelopeChecksum(checksum EnvelopeChecksum, data []byte) uint64 {
+	switch checksum {
+	case EnvelopeXXHash:
+		digest := xxhash.New()
+		digest.Write(data[:7])
+		digest.Write(data[15:])
+		return digest.Sum64()
+	case EnvelopeCRC32C:
+		sum := crc32.Update(0, crc32cTable, data[:7])
+		return uint64(crc32.Update(sum, crc32cTable, data[15:]))
+	default:
+		// Unknown algorithm, can never match
+		return ^binary.BigEndian.Uint64(data[7:15])
+	}
+}
@@ -0,0 +1,199 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eko/gocache/v3/store"
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+type envelopeTestBook struct {
+	ID   int
+	Name string
+}
+
+func TestEnvelopeSealAndOpen(t *testing.T) {
+	for _, checksum := range []EnvelopeChecksum{EnvelopeCRC32C, EnvelopeXXHash} {
+		// Given
+		e := &envelope{version: 3, checksum: checksum, now: time.Now}
+
+		// When
+		payload, _, err := e.openWithMetadata(e.sealWithMetadata([]byte("my-payload"), "", nil), "my-key")
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("my-payload"), payload)
+	}
+}
+
+func TestEnvelopeOpenWhenCorrupted(t *testing.T) {
+	// Given
+	e := &envelope{version: 1, checksum: EnvelopeCRC32C, now: time.Now}
+
+	data := e.sealWithMetadata([]byte("my-payload"), "", nil)
+	data[len(data)-1] = 'X'
+
+	// When
+	_, _, err := e.openWithMetadata(data, "my-key")
+	_, _, notEnvelopedErr := e.openWithMetadata("my-payload", "my-key")
+
+	// Then
+	assert.ErrorIs(t, err, ErrEnvelopeCorrupted)
+	assert.ErrorIs(t, notEnvelopedErr, ErrEnvelopeCorrupted)
+	assert.Equal(t, EnvelopeStats{Corruptions: 2}, e.stats())
+}
+
+func TestEnvelopeOpenWhenVersionMismatch(t *testing.T) {
+	// Given
+	previous := &envelope{version: 1, checksum: EnvelopeCRC32C, now: time.Now}
+	current := &envelope{version: 2, checksum: EnvelopeCRC32C, now: time.Now}
+
+	// When
+	_, _, err := current.openWithMetadata(previous.sealWithMetadata([]byte("my-payload"), "", nil), "my-key")
+
+	// Then
+	assert.ErrorIs(t, err, ErrEnvelopeVersionMismatch)
+	assert.Equal(t, EnvelopeStats{VersionMismatches: 1}, current.stats())
+}
+
+func TestEnvelopeOpenWhenOriginalKeyRecorded(t *testing.T) {
+	// Given
+	e := &envelope{version: 1, checksum: EnvelopeCRC32C, now: time.Now}
+
+	data := e.sealWithMetadata([]byte("my-payload"), "my-original-key", nil)
+
+	// When
+	payload, _, err := e.openWithMetadata(data, "my-original-key")
+	_, _, collisionErr := e.openWithMetadata(data, "my-other-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("my-payload"), payload)
+
+	assert.ErrorIs(t, collisionErr, ErrKeyCollision)
+	assert.Equal(t, EnvelopeStats{KeyCollisions: 1}, e.stats())
+}
+
+func TestCacheWithEnvelope(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	gocacheStore := store.NewGoCache(gocache.New(gocache.NoExpiration, time.Minute))
+
+	cache := New[envelopeTestBook](gocacheStore, WithEnvelope[envelopeTestBook](1))
+
+	// When
+	err := cache.Set(ctx, "my-key", envelopeTestBook{ID: 1, Name: "My book"})
+	assert.Nil(t, err)
+
+	value, err := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, envelopeTestBook{ID: 1, Name: "My book"}, value)
+}
+
+func TestCacheWithEnvelopeWhenVersionChanged(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	gocacheStore := store.NewGoCache(gocache.New(gocache.NoExpiration, time.Minute))
+
+	previousCache := New[envelopeTestBook](gocacheStore,
+		WithSerializer[envelopeTestBook](JSONSerializer[envelopeTestBook]{}),
+		WithEnvelope[envelopeTestBook](1),
+	)
+	assert.Nil(t, previousCache.Set(ctx, "my-key", envelopeTestBook{ID: 1, Name: "My book"}))
+
+	cache := New[envelopeTestBook](gocacheStore,
+		WithSerializer[envelopeTestBook](JSONSerializer[envelopeTestBook]{}),
+		WithEnvelope[envelopeTestBook](2, WithEnvelopeChecksum(EnvelopeXXHash), WithEnvelopeDeleteInvalid()),
+	)
+
+	// When
+	value, err := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Equal(t, envelopeTestBook{}, value)
+	assert.True(t, errors.Is(err, store.NotFound{}))
+	assert.ErrorIs(t, err.(*store.NotFound).Cause(), ErrEnvelopeVersionMismatch)
+	assert.Equal(t, EnvelopeStats{VersionMismatches: 1}, cache.GetEnvelopeStats())
+
+	_, err = gocacheStore.Get(ctx, "my-key")
+	assert.True(t, errors.Is(err, store.NotFound{}))
+}
+
+func TestCacheWithEnvelopeWhenValueNotEnveloped(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	gocacheStore := store.NewGoCache(gocache.New(gocache.NoExpiration, time.Minute))
+	assert.Nil(t, gocacheStore.Set(ctx, "my-key", []byte("not enveloped")))
+
+	cache := New[envelopeTestBook](gocacheStore, WithEnvelope[envelopeTestBook](1))
+
+	// When
+	_, _, err := cache.GetWithTTL(ctx, "my-key")
+
+	// Then
+	assert.True(t, errors.Is(err, store.NotFound{}))
+	assert.Equal(t, EnvelopeStats{Corruptions: 1}, cache.GetEnvelopeStats())
+
+	// Value is kept when invalid values are not deleted
+	_, err = gocacheStore.Get(ctx, "my-key")
+	assert.Nil(t, err)
+}
+
+func TestEnvelopeOpenWithMetadata(t *testing.T) {
+	// Given
+	e := &envelope{version: 1, checksum: EnvelopeCRC32C, now: time.Now}
+
+	expiresAt := time.Now().Add(time.Minute)
+
+	data := e.sealWithMetadata([]byte("my-payload"), "", &envelopeMetadata{loadDuration: time.Second, expiresAt: expiresAt})
+
+	// When
+	payload, metadata, err := e.openWithMetadata(data, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("my-payload"), payload)
+	assert.Equal(t, time.Second, metadata.loadDuration)
+	assert.True(t, expiresAt.Equal(metadata.expiresAt))
+}
+
+func TestEnvelopeOpenWithMetadataWhenOriginalKeyRecorded(t *testing.T) {
+	// Given
+	e := &envelope{version: 1, checksum: EnvelopeCRC32C, now: time.Now}
+
+	data := e.sealWithMetadata([]byte("my-payload"), "my-original-key", &envelopeMetadata{loadDuration: time.Second})
+
+	// When
+	payload, metadata, err := e.openWithMetadata(data, "my-original-key")
+	_, _, collisionErr := e.openWithMetadata(data, "my-other-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("my-payload"), payload)
+	assert.Equal(t, time.Second, metadata.loadDuration)
+	assert.True(t, metadata.expiresAt.IsZero())
+
+	assert.ErrorIs(t, collisionErr, ErrKeyCollision)
+}
+
+func TestEnvelopeOpenWithMetadataWhenNotRecorded(t *testing.T) {
+	// Given
+	e := &envelope{version: 1, checksum: EnvelopeCRC32C, now: time.Now}
+
+	// When
+	payload, metadata, err := e.openWithMetadata(e.sealWithMetadata([]byte("my-payload"), "", nil), "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("my-payload"), payload)
+	assert.Nil(t, metadata)
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"errors"
+	"time"
+
+	"github.com/eko/gocache/v3/store"
+)
+
+// WithHooks allows emitting set, delete, hit and miss events to the given hooks. Events hold
+// the given keys and the cache values. As hooks can be shared, they are not closed with the cache.
+// Evictions and expirations are emitted by stores wrapped using store.WithHooks. When the
+// store of the cache is wrapped using store.WithHooks with the same hooks, the cache does not
+// emit events, so they are not emitted twice. Other store decorators and caches emitting to
+// the same hooks (such as the layers of a chain) each emit their own events.
+func WithHooks[T any](hooks *store.Hooks) CacheOption[T] {
+	return func(o *Cache[T]) {
+		o.hooks = hooks
+	}
+}
+
+// hooksProvider is implemented by stores emitting events to hooks, see store.HookStore
+type hooksProvider interface {
+	GetHooks() *store.Hooks
+}
+
+// GetHooks returns the hooks events are emitted to, if any
+func (c *Cache[T]) GetHooks() *store.Hooks {
+	return c.hooks
+}
+
+// emit emits an event to the hooks, if any
+func (c *Cache[T]) emit(eventType store.EventType, key any, value any) {
+	if c.hooks == nil || c.storeHooked {
+		return
+	}
+
+	c.hooks.Emit(store.Event{
+		Type:   eventType,
+		Key:    key,
+		Value:  value,
+		Source: CacheType,
+		Time:   time.Now(),
+	})
+}
+
+// emitRead emits a hit or a miss event depending on the result of a read
+func (c *Cache[T]) emitRead(key any, value T, err error) {
+	if err == nil {
+		c.emit(store.EventHit, key, value)
+	} else if errors.Is(err, store.NotFound{}) {
+		c.emit(store.EventMiss, key, nil)
+	}
+}
@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eko/gocache/v3/store"
+	mocksStore "github.com/eko/gocache/v3/test/mocks/store"
+	"github.com/golang/mock/gomock"
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheEmitsEvents(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	hooks := store.NewHooks()
+
+	var mu sync.Mutex
+	var events []store.Event
+	record := func(event store.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+	hooks.OnSet(record)
+	hooks.OnHit(record)
+	hooks.OnMiss(record)
+	hooks.OnDelete(record)
+
+	cache := New[int](
+		store.NewGoCache(gocache.New(gocache.NoExpiration, time.Minute)),
+		WithSerializer[int](JSONSerializer[int]{}),
+		WithHooks[int](hooks),
+	)
+
+	// When
+	assert.Nil(t, cache.Set(ctx, "my-key", 42))
+
+	value, err := cache.Get(ctx, "my-key")
+	assert.Nil(t, err)
+	assert.Equal(t, 42, value)
+
+	assert.Nil(t, cache.Delete(ctx, "my-key"))
+
+	_, _, err = cache.GetWithTTL(ctx, "my-key")
+	assert.True(t, errors.Is(err, store.NotFound{}))
+
+	// Then
+	assert.Nil(t, hooks.Close())
+
+	assert.Len(t, events, 4)
+	assert.Equal(t, store.EventSet, events[0].Type)
+	assert.Equal(t, 42, events[0].Value)
+	assert.Equal(t, CacheType, events[0].Source)
+	assert.Equal(t, store.EventHit, events[1].Type)
+	assert.Equal(t, 42, events[1].Value)
+	assert.Equal(t, store.EventDelete, events[2].Type)
+	assert.Equal(t, store.EventMiss, events[3].Type)
+	assert.Equal(t, "my-key", events[3].Key)
+}
+
+func TestCacheEmitsNoEventWhenError(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+
+	storeError := errors.New("unable to reach store")
+
+	store1 := mocksStore.NewMockStoreInterface(ctrl)
+	store1.EXPECT().Set(ctx, "my-key", "my-value").Return(storeError)
+	store1.EXPECT().Get(ctx, "my-key").Return(nil, storeError)
+
+	hooks := store.NewHooks()
+
+	var events []store.Event
+	hooks.OnSet(func(event store.Event) { events = append(events, event) })
+	hooks.OnMiss(func(event store.Event) { events = append(events, event) })
+
+	cache := New[string](store1, WithHooks[string](hooks))
+
+	// When
+	err := cache.Set(ctx, "my-key", "my-value")
+	assert.Equal(t, storeError, err)
+
+	_, err = cache.Get(ctx, "my-key")
+	assert.Equal(t, storeError, err)
+
+	// Then
+	assert.Nil(t, hooks.Close())
+	assert.Empty(t, events)
+	assert.Equal(t, hooks, cache.GetHooks())
+}
+
+func TestCacheEmitsNoDuplicateEventWhenStoreHasSameHooks(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	hooks := store.NewHooks()
+
+	var mu sync.Mutex
+	var events []store.Event
+	record := func(event store.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+	hooks.OnSet(record)
+	hooks.OnHit(record)
+
+	cache := New[any](
+		store.WithHooks(store.NewGoCache(gocache.New(gocache.NoExpiration, time.Minute)), hooks),
+		WithHooks[any](hooks),
+	)
+
+	// When
+	assert.Nil(t, cache.Set(ctx, "my-key", "my-value"))
+
+	value, err := cache.Get(ctx, "my-key")
+	assert.Nil(t, err)
+	assert.Equal(t, "my-value", value)
+
+	// Then
+	assert.Nil(t, hooks.Close())
+
+	assert.Len(t, events, 2)
+	assert.Equal(t, store.EventSet, events[0].Type)
+	assert.Equal(t, store.GoCacheType, events[0].Source)
+	assert.Equal(t, store.EventHit, events[1].Type)
+	assert.Equal(t, store.GoCacheType, events[1].Source)
+}
@@ -16,6 +16,8 @@ type CacheInterface[T any] interface {
 	Invalidate(ctx context.Context, options ...store.InvalidateOption) error
 	Clear(ctx context.Context) error
 	GetType() string
+	// Close releases the resources of the cache and of the caches and stores it wraps
+	Close() error
 }
 
 type CacheKeyGenerator interface {
@@ -32,6 +34,7 @@ type SetterCacheInterface[T any] interface {
 	Invalidate(ctx context.Context, options ...store.InvalidateOption) error
 	Clear(ctx context.Context) error
 	GetType() string
+	Close() error
 
 	GetWithTTL(ctx context.Context, key any) (T, time.Duration, error)
 
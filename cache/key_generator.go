@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// KeyGenerator computes the store key of cache keys that are neither strings
+// nor CacheKeyGenerator implementations
+type KeyGenerator interface {
+	GenerateKey(key any) string
+}
+
+// KeyHash represents the hash function used by key generators
+type KeyHash int
+
+const (
+	// KeyHashXXHash hashes keys using 64-bit xxHash
+	KeyHashXXHash KeyHash = iota
+	// KeyHashSHA256 hashes keys using SHA-256
+	KeyHashSHA256
+)
+
+// hash returns the hexadecimal hash of the given data
+func (h KeyHash) hash(data []byte) string {
+	if h == KeyHashSHA256 {
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:])
+	}
+
+	return strconv.FormatUint(xxhash.Sum64(data), 16)
+}
+
+// canonicalKey returns the type name and the canonical JSON representation of the given key.
+// Map keys are sorted by encoding/json and pointers are dereferenced, so a key and a pointer
+// to it generate the same store key.
+// When the key cannot be encoded in JSON, or is a struct having fields but none of them
+// exported (all its values would be encoded as {}), its legacy checksum is returned instead.
+func canonicalKey(key any) []byte {
+	data, err := json.Marshal(key)
+	if err != nil {
+		return []byte(checksum(key))
+	}
+
+	typ := reflect.TypeOf(key)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ != nil && typ.Kind() == reflect.Struct && typ.NumField() > 0 && string(data) == "{}" {
+		return []byte(checksum(key))
+	}
+
+	return append([]byte(fmt.Sprintf("%v:", typ)), data...)
+}
+
+// JSONKeyGenerator generates keys by hashing the type name and the canonical JSON
+// representation of keys. Only exported fields are used: keys of structs without exported
+// fields are hashed from their legacy checksum instead.
+type JSONKeyGenerator struct {
+	Hash KeyHash
+}
+
+// GenerateKey returns the store key of the given key
+func (g JSONKeyGenerator) GenerateKey(key any) string {
+	return g.Hash.hash(canonicalKey(key))
+}
+
+// TagKeyGenerator generates keys by hashing the type name and the fields of struct keys
+// having a `cache:"name"` tag. Other keys are handled like JSONKeyGenerator does.
+type TagKeyGenerator struct {
+	Hash KeyHash
+}
+
+// GenerateKey returns the store key of the given key
+func (g TagKeyGenerator) GenerateKey(key any) string {
+	value := reflect.ValueOf(key)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return g.Hash.hash(canonicalKey(key))
+	}
+
+	fields := make(map[string]any)
+	for i := 0; i < value.NumField(); i++ {
+		name := value.Type().Field(i).Tag.Get("cache")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		field := value.Field(i)
+		if !field.CanInterface() {
+			// Unexported field, use its printed value
+			fields[name] = fmt.Sprint(field)
+			continue
+		}
+		fields[name] = field.Interface()
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	data := []byte(value.Type().String())
+	for _, name := range names {
+		fieldData, err := json.Marshal(fields[name])
+		if err != nil {
+			fieldData = []byte(fmt.Sprint(fields[name]))
+		}
+		data = append(data, '|')
+		data = append(data, name...)
+		data = append(data, '=')
+		data = append(data, fieldData...)
+	}
+
+	return g.Hash.hash(data)
+}
+
+// ReadableKeyGenerator generates human-readable keys made of the type name and the
+// canonical JSON representation of keys, without hashing. Useful for debugging,
+// as keys can be long.
+type ReadableKeyGenerator struct{}
+
+// GenerateKey returns the store key of the given key
+func (ReadableKeyGenerator) GenerateKey(key any) string {
+	return string(canonicalKey(key))
+}
@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	mocksStore "github.com/eko/gocache/v3/test/mocks/store"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type keyGeneratorTestQuery struct {
+	Slug     string `cache:"slug"`
+	Page     int    `cache:"page"`
+	TraceID  string
+	internal string
+}
+
+type keyGeneratorOtherQuery struct {
+	Slug string `cache:"slug"`
+	Page int    `cache:"page"`
+}
+
+func TestJSONKeyGenerator(t *testing.T) {
+	// Given
+	generator := JSONKeyGenerator{}
+
+	query := keyGeneratorTestQuery{Slug: "my-book", Page: 2}
+
+	// When
+	key := generator.GenerateKey(query)
+
+	// Then
+	assert.Equal(t, key, generator.GenerateKey(&query))
+	assert.Equal(t, key, generator.GenerateKey(keyGeneratorTestQuery{Slug: "my-book", Page: 2, internal: "ignored"}))
+	assert.NotEqual(t, key, generator.GenerateKey(keyGeneratorOtherQuery{Slug: "my-book", Page: 2}))
+	assert.NotEqual(t, key, generator.GenerateKey(keyGeneratorTestQuery{Slug: "my-book", Page: 3}))
+}
+
+func TestJSONKeyGeneratorWhenStructHasNoExportedFields(t *testing.T) {
+	// Given
+	type unexportedQuery struct {
+		slug string
+		page int
+	}
+
+	generator := JSONKeyGenerator{}
+
+	// When
+	key := generator.GenerateKey(unexportedQuery{slug: "my-book", page: 2})
+
+	// Then
+	assert.Equal(t, key, generator.GenerateKey(unexportedQuery{slug: "my-book", page: 2}))
+	assert.NotEqual(t, key, generator.GenerateKey(unexportedQuery{slug: "my-book", page: 3}))
+	assert.NotEqual(t, key, generator.GenerateKey(unexportedQuery{slug: "other-book", page: 2}))
+	assert.Equal(t, generator.GenerateKey(struct{}{}), generator.GenerateKey(struct{}{}))
+}
+
+func TestJSONKeyGeneratorWhenMap(t *testing.T) {
+	// Given
+	generator := JSONKeyGenerator{Hash: KeyHashSHA256}
+
+	// When
+	key := generator.GenerateKey(map[string]int{"a": 1, "b": 2})
+
+	// Then
+	assert.Len(t, key, 64)
+	assert.Equal(t, key, generator.GenerateKey(map[string]int{"b": 2, "a": 1}))
+}
+
+func TestTagKeyGenerator(t *testing.T) {
+	// Given
+	generator := TagKeyGenerator{}
+
+	// When
+	key := generator.GenerateKey(keyGeneratorTestQuery{Slug: "my-book", Page: 2, TraceID: "abc"})
+
+	// Then
+	assert.Equal(t, key, generator.GenerateKey(&keyGeneratorTestQuery{Slug: "my-book", Page: 2, TraceID: "def"}))
+	assert.NotEqual(t, key, generator.GenerateKey(keyGeneratorTestQuery{Slug: "my-book", Page: 3}))
+	assert.NotEqual(t, key, generator.GenerateKey(keyGeneratorOtherQuery{Slug: "my-book", Page: 2}))
+}
+
+func TestTagKeyGeneratorWhenNotStruct(t *testing.T) {
+	// Given
+	generator := TagKeyGenerator{}
+
+	// When - Then
+	assert.Equal(t, JSONKeyGenerator{}.GenerateKey([]int{1, 2}), generator.GenerateKey([]int{1, 2}))
+}
+
+func TestReadableKeyGenerator(t *testing.T) {
+	// Given
+	generator := ReadableKeyGenerator{}
+
+	// When
+	key := generator.GenerateKey(&keyGeneratorOtherQuery{Slug: "my-book", Page: 2})
+
+	// Then
+	assert.Equal(t, `cache.keyGeneratorOtherQuery:{"Slug":"my-book","Page":2}`, key)
+}
+
+func TestCacheGetWithKeyGenerator(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	query := keyGeneratorOtherQuery{Slug: "my-book", Page: 2}
+
+	store := mocksStore.NewMockStoreInterface(ctrl)
+	store.EXPECT().Get(ctx, `cache.keyGeneratorOtherQuery:{"Slug":"my-book","Page":2}`).Return("my-value", nil)
+	store.EXPECT().Get(ctx, "my-string-key").Return("my-value", nil)
+
+	cache := New[string](store, WithKeyGenerator[string](ReadableKeyGenerator{}))
+
+	// When
+	value, err := cache.Get(ctx, query)
+	stringValue, stringErr := cache.Get(ctx, "my-string-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "my-value", value)
+
+	assert.Nil(t, stringErr)
+	assert.Equal(t, "my-value", stringValue)
+}
@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/eko/gocache/v3/store"
+)
+
+const (
+	defaultKeyNormalizerPrefixLength = 64
+
+	// separator followed by the hex encoded SHA-256 hash of the key
+	normalizedKeyHashLength = 1 + 2*sha256.Size
+)
+
+// KeyNormalizerOption represents a key normalizer option function
+type KeyNormalizerOption func(o *keyNormalizer)
+
+// WithKeyNormalizerPrefixLength allows setting the maximum length of the readable
+// prefix kept in normalized keys. Default is 64 bytes. With a length of 0, normalized
+// keys are only made of the hash.
+func WithKeyNormalizerPrefixLength(length int) KeyNormalizerOption {
+	return func(o *keyNormalizer) {
+		o.prefixLength = length
+	}
+}
+
+// WithKeyNormalizerConstraints allows setting the key constraints to respect,
+// instead of the ones declared by the store
+func WithKeyNormalizerConstraints(constraints store.KeyConstraints) KeyNormalizerOption {
+	return func(o *keyNormalizer) {
+		o.constraints = &constraints
+	}
+}
+
+// WithKeyNormalizer allows rewriting keys that do not respect the constraints of the store
+// (such as Memcache keys longer than 250 bytes or containing spaces) instead of failing.
+// Invalid keys are replaced by a readable prefix of the key followed by its SHA-256 hash.
+// Collisions are only detected when an envelope is used (see WithEnvelope): the original key
+// is then recorded in it, so values set for another key having the same normalized key are
+// returned as misses. Without an envelope, such keys share the same value.
+func WithKeyNormalizer[T any](options ...KeyNormalizerOption) CacheOption[T] {
+	return func(o *Cache[T]) {
+		o.keyNormalizer = &keyNormalizer{
+			prefixLength: defaultKeyNormalizerPrefixLength,
+		}
+
+		for _, option := range options {
+			option(o.keyNormalizer)
+		}
+	}
+}
+
+// cacheKeyer is implemented by caches computing the store keys of keys themselves,
+// see Cache.getKeys
+type cacheKeyer interface {
+	getKeys(key any) (string, string)
+}
+
+// keyNormalizerProvider is implemented by caches able to normalize their keys
+type keyNormalizerProvider interface {
+	getKeyNormalizer() *keyNormalizer
+}
+
+type keyNormalizer struct {
+	constraints  *store.KeyConstraints
+	prefixLength int
+}
+
+// normalize returns the given key if it respects the constraints, or its normalized
+// version and true otherwise
+func (n *keyNormalizer) normalize(key string) (string, bool) {
+	if n.constraints.Validate(key) == nil {
+		return key, false
+	}
+
+	prefixLength := n.prefixLength
+	if maxLength := n.constraints.MaxLength; maxLength > 0 && maxLength-normalizedKeyHashLength < prefixLength {
+		prefixLength = maxLength - normalizedKeyHashLength
+	}
+
+	var builder strings.Builder
+	for _, char := range key {
+		if !n.constraints.IsValidRune(char) {
+			char = '_'
+		}
+		if builder.Len()+utf8.RuneLen(char) > prefixLength {
+			break
+		}
+		builder.WriteRune(char)
+	}
+
+	hash := sha256.Sum256([]byte(key))
+	hexHash := hex.EncodeToString(hash[:])
+
+	if prefixLength < 0 {
+		// The store does not even accept a full hash, keep its beginning
+		return hexHash[:n.constraints.MaxLength], true
+	}
+
+	if builder.Len() > 0 {
+		builder.WriteByte(':')
+	}
+	builder.WriteString(hexHash)
+
+	return builder.String(), true
+}
@@ -0,0 +1,176 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eko/gocache/v3/store"
+	mocksStore "github.com/eko/gocache/v3/test/mocks/store"
+	"github.com/golang/mock/gomock"
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyNormalizerWhenValidKey(t *testing.T) {
+	// Given
+	normalizer := &keyNormalizer{
+		constraints:  &store.KeyConstraints{MaxLength: 250, DisallowWhitespace: true},
+		prefixLength: defaultKeyNormalizerPrefixLength,
+	}
+
+	// When
+	key, normalized := normalizer.normalize("my-key")
+
+	// Then
+	assert.False(t, normalized)
+	assert.Equal(t, "my-key", key)
+}
+
+func TestKeyNormalizerWhenInvalidKey(t *testing.T) {
+	// Given
+	constraints := store.KeyConstraints{MaxLength: 250, DisallowWhitespace: true}
+
+	normalizer := &keyNormalizer{
+		constraints:  &constraints,
+		prefixLength: 10,
+	}
+
+	longKey := "books:" + strings.Repeat("a", 300)
+
+	// When
+	spaceKey, spaceNormalized := normalizer.normalize("my book key")
+	key, normalized := normalizer.normalize(longKey)
+	otherKey, _ := normalizer.normalize(longKey + "b")
+
+	// Then
+	assert.True(t, spaceNormalized)
+	assert.True(t, strings.HasPrefix(spaceKey, "my_book_ke:"))
+	assert.Nil(t, constraints.Validate(spaceKey))
+
+	assert.True(t, normalized)
+	assert.True(t, strings.HasPrefix(key, "books:aaaa:"))
+	assert.Len(t, key, 10+normalizedKeyHashLength)
+	assert.Nil(t, constraints.Validate(key))
+
+	assert.NotEqual(t, key, otherKey)
+}
+
+func TestKeyNormalizerWhenNoPrefix(t *testing.T) {
+	// Given
+	constraints := store.KeyConstraints{MaxLength: 250, DisallowWhitespace: true}
+
+	normalizer := &keyNormalizer{
+		constraints:  &constraints,
+		prefixLength: 0,
+	}
+
+	// When
+	key, normalized := normalizer.normalize("my book key")
+
+	// Then
+	assert.True(t, normalized)
+	assert.Len(t, key, normalizedKeyHashLength-1)
+	assert.False(t, strings.HasPrefix(key, ":"))
+	assert.Nil(t, constraints.Validate(key))
+}
+
+func TestKeyNormalizerWhenMaxLengthShorterThanHash(t *testing.T) {
+	// Given
+	normalizer := &keyNormalizer{
+		constraints:  &store.KeyConstraints{MaxLength: 16},
+		prefixLength: defaultKeyNormalizerPrefixLength,
+	}
+
+	// When
+	key, normalized := normalizer.normalize(strings.Repeat("a", 20))
+
+	// Then
+	assert.True(t, normalized)
+	assert.Len(t, key, 16)
+}
+
+func TestCacheWithKeyNormalizer(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	longKey := strings.Repeat("a", 300)
+
+	var storeKey string
+
+	memcacheStore := mocksStore.NewMockStoreInterface(ctrl)
+	memcacheStore.EXPECT().Set(ctx, gomock.Any(), "my-value").DoAndReturn(
+		func(_ context.Context, key any, _ any, _ ...store.Option) error {
+			storeKey = key.(string)
+			return nil
+		},
+	)
+
+	cache := New[string](memcacheStore, WithKeyNormalizer[string](
+		WithKeyNormalizerConstraints(store.KeyConstraints{MaxLength: store.MemcacheMaxKeyLength, DisallowWhitespace: true}),
+	))
+
+	// When
+	err := cache.Set(ctx, longKey, "my-value")
+
+	// Then
+	assert.Nil(t, err)
+	assert.LessOrEqual(t, len(storeKey), store.MemcacheMaxKeyLength)
+	assert.True(t, strings.HasPrefix(storeKey, strings.Repeat("a", defaultKeyNormalizerPrefixLength)+":"))
+}
+
+func TestCacheWithKeyNormalizerUsesStoreConstraints(t *testing.T) {
+	// Given
+	inner := store.NewMemcache(nil)
+
+	// When
+	cache := New[string](store.WithNamespace(inner, "svc-a:"), WithKeyNormalizer[string]())
+
+	// Then
+	assert.Equal(t, &store.KeyConstraints{MaxLength: 244, DisallowWhitespace: true}, cache.keyNormalizer.constraints)
+}
+
+func TestCacheWithKeyNormalizerWhenCollision(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	gocacheStore := store.NewGoCache(gocache.New(gocache.NoExpiration, time.Minute))
+
+	// Keys are normalized to the first character of their hash, so collisions are easy to find
+	cache := New[string](gocacheStore,
+		WithEnvelope[string](1),
+		WithKeyNormalizer[string](WithKeyNormalizerConstraints(store.KeyConstraints{MaxLength: 1})),
+	)
+
+	firstKey := "my-long-key-0"
+	firstStoreKey, _ := cache.keyNormalizer.normalize(firstKey)
+
+	var otherKey string
+	for i := 1; otherKey == ""; i++ {
+		key := fmt.Sprintf("my-long-key-%d", i)
+		if storeKey, _ := cache.keyNormalizer.normalize(key); storeKey == firstStoreKey {
+			otherKey = key
+		}
+	}
+
+	err := cache.Set(ctx, firstKey, "my-value")
+	assert.Nil(t, err)
+
+	// When
+	value, err := cache.Get(ctx, firstKey)
+	otherValue, otherErr := cache.Get(ctx, otherKey)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "my-value", value)
+
+	assert.True(t, errors.Is(otherErr, store.NotFound{}))
+	assert.ErrorIs(t, otherErr, ErrKeyCollision)
+	assert.Equal(t, "", otherValue)
+	assert.Equal(t, EnvelopeStats{KeyCollisions: 1}, cache.GetEnvelopeStats())
+}
@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultCloseTimeout is the maximum duration Close waits for background operations to complete
+const DefaultCloseTimeout = 10 * time.Second
+
+// ErrClosed is returned by cache operations called after the cache has been closed
+var ErrClosed = errors.New("cache is closed")
+
+// CloserInterface represents caches and stores able to release their resources
+// before a given deadline
+type CloserInterface interface {
+	io.Closer
+	CloseWithContext(ctx context.Context) error
+}
+
+// lifecycle tracks the background operations of a cache so they can be drained when it is closed
+type lifecycle struct {
+	mu      sync.RWMutex
+	closed  uint32
+	abandon uint32
+	running sync.WaitGroup
+}
+
+// isClosed returns true once close has been called
+func (l *lifecycle) isClosed() bool {
+	return atomic.LoadUint32(&l.closed) == 1
+}
+
+// abandoned returns true when background operations have to be skipped
+// because they could not be drained before the close deadline
+func (l *lifecycle) abandoned() bool {
+	return atomic.LoadUint32(&l.abandon) == 1
+}
+
+// do calls the given function unless the lifecycle is closed, so it can safely
+// send values to channels closed on close. It returns false if closed.
+func (l *lifecycle) do(fn func()) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.isClosed() {
+		return false
+	}
+
+	fn()
+	return true
+}
+
+// goroutine runs the given function in background unless the lifecycle is closed.
+// It returns false if closed.
+func (l *lifecycle) goroutine(fn func()) bool {
+	return l.do(func() {
+		l.running.Add(1)
+		go func() {
+			defer l.running.Done()
+			fn()
+		}()
+	})
+}
+
+// after runs the given function in background once the given delay has elapsed. Close waits
+// for it, so it has to be called from a background operation or before the lifecycle is closed.
+func (l *lifecycle) after(delay time.Duration, fn func()) {
+	l.running.Add(1)
+	time.AfterFunc(delay, func() {
+		defer l.running.Done()
+		fn()
+	})
+}
+
+// close marks the lifecycle as closed, calls the given function (to close queues for instance)
+// and waits for background operations until the context is done. Queued operations are then
+// abandoned while in-flight ones complete. It returns false if already closed.
+func (l *lifecycle) close(ctx context.Context, onClose func()) (bool, error) {
+	l.mu.Lock()
+	if l.isClosed() {
+		l.mu.Unlock()
+		return false, nil
+	}
+	atomic.StoreUint32(&l.closed, 1)
+	if onClose != nil {
+		onClose()
+	}
+	l.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		l.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true, nil
+	case <-ctx.Done():
+		atomic.StoreUint32(&l.abandon, 1)
+		return true, fmt.Errorf("unable to drain background operations: %w", ctx.Err())
+	}
+}
+
+// closeWithTimeout calls the given close function with a context expiring after DefaultCloseTimeout
+func closeWithTimeout(closeWithContext func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultCloseTimeout)
+	defer cancel()
+
+	return closeWithContext(ctx)
+}
+
+// closeWrapped closes the given cache or store if it is able to release resources
+func closeWrapped(ctx context.Context, value any) error {
+	switch closer := value.(type) {
+	case CloserInterface:
+		return closer.CloseWithContext(ctx)
+	case io.Closer:
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// firstError returns the first non-nil given error
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
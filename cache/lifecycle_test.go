@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLifecycleClose(t *testing.T) {
+	// Given
+	l := &lifecycle{}
+
+	finished := false
+	assert.True(t, l.goroutine(func() {
+		time.Sleep(10 * time.Millisecond)
+		finished = true
+	}))
+
+	onCloseCalls := 0
+
+	// When
+	closed, err := l.close(context.Background(), func() { onCloseCalls++ })
+	closedAgain, errAgain := l.close(context.Background(), func() { onCloseCalls++ })
+
+	// Then
+	assert.True(t, closed)
+	assert.Nil(t, err)
+	assert.True(t, finished)
+
+	assert.False(t, closedAgain)
+	assert.Nil(t, errAgain)
+	assert.Equal(t, 1, onCloseCalls)
+
+	assert.True(t, l.isClosed())
+	assert.False(t, l.abandoned())
+	assert.False(t, l.do(func() {}))
+	assert.False(t, l.goroutine(func() {}))
+}
+
+func TestLifecycleCloseWhenDeadlineExceeded(t *testing.T) {
+	// Given
+	l := &lifecycle{}
+
+	release := make(chan struct{})
+	defer close(release)
+
+	l.goroutine(func() {
+		<-release
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// When
+	closed, err := l.close(ctx, nil)
+
+	// Then
+	assert.True(t, closed)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, l.abandoned())
+}
+
+type closerMock struct {
+	closeCalls            int
+	closeWithContextCalls int
+}
+
+func (c *closerMock) Close() error {
+	c.closeCalls++
+	return nil
+}
+
+func (c *closerMock) CloseWithContext(_ context.Context) error {
+	c.closeWithContextCalls++
+	return nil
+}
+
+type ioCloserMock struct {
+	closeCalls int
+}
+
+func (c *ioCloserMock) Close() error {
+	c.closeCalls++
+	return nil
+}
+
+func TestCloseWrapped(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	closer := &closerMock{}
+	ioCloser := &ioCloserMock{}
+
+	// When
+	err := closeWrapped(ctx, closer)
+	ioErr := closeWrapped(ctx, ioCloser)
+	otherErr := closeWrapped(ctx, "not a closer")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Nil(t, ioErr)
+	assert.Nil(t, otherErr)
+
+	assert.Equal(t, 0, closer.closeCalls)
+	assert.Equal(t, 1, closer.closeWithContextCalls)
+	assert.Equal(t, 1, ioCloser.closeCalls)
+}
@@ -2,8 +2,11 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"sync"
+	"time"
 
+	"github.com/eko/gocache/v3/lock"
 	"github.com/eko/gocache/v3/store"
 )
 
@@ -13,81 +16,455 @@ const (
 )
 
 type loadableKeyValue[T any] struct {
-	key   any
-	value T
+	key     any
+	value   T
+	options []store.Option
+	// loadDuration is the time the load of the value took
+	loadDuration time.Duration
 }
 
 type LoadFunction[T any] func(ctx context.Context, key any) (T, error)
 
+// LoadFunctionWithOptions is a load function also returning the options used to set the
+// loaded value in cache, such as an expiration derived from the HTTP Cache-Control header
+// of the origin, or tags derived from the loaded entity
+type LoadFunctionWithOptions[T any] func(ctx context.Context, key any) (T, []store.Option, error)
+
+// withOptions returns the load function as a load function returning no options
+func (f LoadFunction[T]) withOptions() LoadFunctionWithOptions[T] {
+	if f == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, key any) (T, []store.Option, error) {
+		object, err := f(ctx, key)
+		return object, nil, err
+	}
+}
+
 // LoadableCache represents a cache that uses a function to load data
 type LoadableCache[T any] struct {
-	loadFunc   LoadFunction[T]
-	cache      CacheInterface[T]
-	setChannel chan *loadableKeyValue[T]
-	setterWg   *sync.WaitGroup
+	loadFunc            LoadFunction[T]
+	loadFuncWithOptions LoadFunctionWithOptions[T]
+	cache               CacheInterface[T]
+	setChannel          chan *loadableKeyValue[T]
+	lifecycle           lifecycle
+	loadLock            *loadLock
+	xfetch              *xfetch
+	negative            *negativeCache
+	refreshAhead        *refreshAhead
+	refreshing          sync.Map
+	// cacheKey returns the cache key of a key the way the wrapped cache computes it,
+	// so keys it considers the same are refreshed once
+	cacheKey func(key any) string
+}
+
+type LoadableCacheOption[T any] func(cache *LoadableCache[T])
+
+// WithLoadableLocker takes a lock on the key using the given locker before calling
+// the load function, so only one instance loads a given key at a time. Instances
+// that do not obtain the lock wait up to the wait duration for the value to be
+// available in cache before loading it themselves.
+func WithLoadableLocker[T any](locker lock.LockerInterface, ttl time.Duration, wait time.Duration) LoadableCacheOption[T] {
+	return func(cache *LoadableCache[T]) {
+		cache.loadLock = &loadLock{locker: locker, ttl: ttl, wait: wait}
+	}
+}
+
+// WithLoadableXFetch refreshes values in background before they expire, with a probability
+// that rises as expiry approaches, weighted by the time the last load took and the given
+// beta factor (1.0 is a good default, higher values refresh earlier). The wrapped cache
+// needs to be a Cache using WithEnvelope, as the load duration and the expiration date
+// are recorded in the envelope of values, so every instance reading them refreshes them early.
+func WithLoadableXFetch[T any](beta float64) LoadableCacheOption[T] {
+	return func(cache *LoadableCache[T]) {
+		cache.xfetch = newXFetch(beta)
+	}
+}
+
+// WithLoadableNegativeCache caches load function misses and errors so the load function is
+// not called again for the same key during the given durations. When the load function returns
+// ErrNotExist, next calls return ErrNotExist for notExistTTL. When it returns another error and
+// errorTTL is greater than zero, next calls return a CachedLoadError for errorTTL. The wrapped
+// cache needs to implement SetterCacheInterface as tombstones are stored in its store.
+// When a load error wraps one of the given sentinel errors, the CachedLoadError wraps it too.
+func WithLoadableNegativeCache[T any](notExistTTL time.Duration, errorTTL time.Duration, errs ...error) LoadableCacheOption[T] {
+	return func(cache *LoadableCache[T]) {
+		cache.negative = &negativeCache{notExistTTL: notExistTTL, errorTTL: errorTTL, errors: errs}
+	}
+}
+
+// WithLoadableRefreshAhead refreshes in background the values read within the given window,
+// once the given ratio of their TTL has elapsed (0.8 refreshes values at 80% of their TTL),
+// so reads never pay the latency of a miss after expiry. The ratio has to be greater than 0
+// and lower than 1, 0.8 is used otherwise. At most concurrency values are
+// refreshed at the same time, and keys not read within the window are not refreshed anymore.
+// The wrapped cache needs to implement SetterCacheInterface so the TTL of values can be retrieved.
+func WithLoadableRefreshAhead[T any](ratio float64, window time.Duration, concurrency int) LoadableCacheOption[T] {
+	return func(cache *LoadableCache[T]) {
+		cache.refreshAhead = newRefreshAhead(ratio, window, concurrency)
+	}
 }
 
 // NewLoadable instanciates a new cache that uses a function to load data
-func NewLoadable[T any](loadFunc LoadFunction[T], cache CacheInterface[T]) *LoadableCache[T] {
+func NewLoadable[T any](loadFunc LoadFunction[T], cache CacheInterface[T], opts ...LoadableCacheOption[T]) *LoadableCache[T] {
+	loadable := newLoadable(cache, opts...)
+	loadable.loadFunc = loadFunc
+
+	return loadable
+}
+
+// NewLoadableWithOptions instanciates a new cache that uses a function to load data,
+// loaded values being set in cache using the options returned by the function
+func NewLoadableWithOptions[T any](loadFunc LoadFunctionWithOptions[T], cache CacheInterface[T], opts ...LoadableCacheOption[T]) *LoadableCache[T] {
+	loadable := newLoadable(cache, opts...)
+	loadable.loadFuncWithOptions = loadFunc
+
+	return loadable
+}
+
+func newLoadable[T any](cache CacheInterface[T], opts ...LoadableCacheOption[T]) *LoadableCache[T] {
 	loadable := &LoadableCache[T]{
-		loadFunc:   loadFunc,
 		cache:      cache,
 		setChannel: make(chan *loadableKeyValue[T], 10000),
-		setterWg:   &sync.WaitGroup{},
+		cacheKey:   cacheKeyFunc(cache),
+	}
+	for _, opt := range opts {
+		opt(loadable)
 	}
 
-	loadable.setterWg.Add(1)
-	go loadable.setter()
+	if loadable.loadLock != nil {
+		loadable.loadLock.useKeysOf(cache)
+	}
+	if loadable.negative != nil {
+		loadable.negative.useKeysOf(cache)
+	}
+
+	loadable.lifecycle.goroutine(loadable.setter)
+
+	if loadable.refreshAhead != nil {
+		loadable.lifecycle.goroutine(loadable.refreshAheadScheduler)
+	}
 
 	return loadable
 }
 
 func (c *LoadableCache[T]) setter() {
-	defer c.setterWg.Done()
-
 	for item := range c.setChannel {
-		c.Set(context.Background(), item.key, item.value)
+		if !c.lifecycle.abandoned() {
+			c.setLoaded(context.Background(), item)
+		}
 	}
 }
 
 // Get returns the object stored in cache if it exists
 func (c *LoadableCache[T]) Get(ctx context.Context, key any) (T, error) {
+	if c.lifecycle.isClosed() {
+		return *new(T), ErrClosed
+	}
+
 	var err error
 
-	object, err := c.cache.Get(ctx, key)
+	object, err := c.get(ctx, key)
 	if err == nil {
 		return object, err
 	}
 
+	// The load function already told us this value does not exist or failed recently
+	if negativeStore := c.negativeStore(); negativeStore != nil {
+		if err := c.negative.get(ctx, negativeStore, key); err != nil {
+			return *new(T), err
+		}
+	}
+
+	if c.loadLock != nil {
+		return c.lockedLoad(ctx, key)
+	}
+
 	// Unable to find in cache, try to load it from load function
-	object, err = c.loadFunc(ctx, key)
+	item, err := c.load(ctx, key)
 	if err != nil {
-		return object, err
+		return item.value, err
 	}
 
 	// Then, put it back in cache
-	c.setChannel <- &loadableKeyValue[T]{key, object}
+	c.lifecycle.do(func() {
+		c.setChannel <- item
+	})
+
+	return item.value, err
+}
+
+// lockedLoad calls the load function while holding the distributed lock on the key.
+// When the lock is held by someone else, it waits for the value to be put in cache.
+func (c *LoadableCache[T]) lockedLoad(ctx context.Context, key any) (T, error) {
+	token, err := c.loadLock.obtain(ctx, key)
+	if errors.Is(err, lock.ErrNotObtained) {
+		if object, ok := waitFor(ctx, c.loadLock.wait, func(ctx context.Context) (T, error) {
+			return c.cache.Get(ctx, key)
+		}); ok {
+			return object, nil
+		}
+	} else if err == nil {
+		defer c.loadLock.release(ctx, key, token)
+
+		// The value may have been loaded while we were trying to obtain the lock
+		if object, err := c.cache.Get(ctx, key); err == nil {
+			return object, nil
+		}
+	}
+
+	item, err := c.load(ctx, key)
+	if err != nil {
+		return item.value, err
+	}
+
+	// Put it back in cache before releasing the lock so waiting instances can read it
+	if !c.lifecycle.isClosed() {
+		_ = c.setLoaded(ctx, item)
+	}
+
+	return item.value, nil
+}
+
+// get returns the object stored in cache. When XFetch is enabled, the value is
+// refreshed in background if it is about to expire. When refresh-ahead is enabled,
+// the access is recorded so the value gets refreshed before it expires.
+func (c *LoadableCache[T]) get(ctx context.Context, key any) (T, error) {
+	if c.xfetch == nil && c.refreshAhead == nil {
+		return c.cache.Get(ctx, key)
+	}
+
+	var (
+		object       T
+		ttl          time.Duration
+		loadDuration time.Duration
+		err          error
+	)
+
+	if xfetchCache, ok := c.cache.(xfetchCacheInterface[T]); ok && c.xfetch != nil {
+		object, ttl, loadDuration, err = xfetchCache.getWithLoadDuration(ctx, key)
+	} else if setterCache, ok := c.cache.(SetterCacheInterface[T]); ok {
+		object, ttl, err = setterCache.GetWithTTL(ctx, key)
+	} else {
+		return c.cache.Get(ctx, key)
+	}
+
+	if err != nil {
+		return object, err
+	}
+
+	if c.xfetch != nil && c.xfetch.shouldRefresh(loadDuration, ttl) {
+		c.refresh(key)
+	}
+	if c.refreshAhead != nil {
+		c.refreshAhead.touch(c.cacheKey(key), key, ttl)
+	}
 
 	return object, err
 }
 
+// load calls the load function, records the time it took and caches its error if needed.
+// It returns the loaded value with the options to set it with.
+func (c *LoadableCache[T]) load(ctx context.Context, key any) (*loadableKeyValue[T], error) {
+	start := time.Now()
+
+	object, options, err := callLoadFunction(ctx, key, c.loadFunc, c.loadFuncWithOptions)
+	if err != nil {
+		if negativeStore := c.negativeStore(); negativeStore != nil {
+			c.negative.set(ctx, negativeStore, key, err)
+		}
+		return &loadableKeyValue[T]{key: key, value: object}, err
+	}
+
+	return &loadableKeyValue[T]{
+		key:          key,
+		value:        object,
+		options:      options,
+		loadDuration: time.Since(start),
+	}, nil
+}
+
+// setLoaded puts the given loaded value in the wrapped cache. When XFetch is enabled,
+// the time its load took is recorded alongside it.
+func (c *LoadableCache[T]) setLoaded(ctx context.Context, item *loadableKeyValue[T]) error {
+	if xfetchCache, ok := c.cache.(xfetchCacheInterface[T]); ok && c.xfetch != nil {
+		return xfetchCache.setWithLoadDuration(ctx, item.key, item.value, item.loadDuration, item.options...)
+	}
+
+	return c.cache.Set(ctx, item.key, item.value, item.options...)
+}
+
+// callLoadFunction calls the load function returning options if any, or the other one.
+// Options are capped so setting a value never appends to a slice owned by the load function.
+func callLoadFunction[T any](ctx context.Context, key any, loadFunc LoadFunction[T], loadFuncWithOptions LoadFunctionWithOptions[T]) (T, []store.Option, error) {
+	if loadFuncWithOptions == nil {
+		loadFuncWithOptions = loadFunc.withOptions()
+	}
+
+	object, options, err := loadFuncWithOptions(ctx, key)
+
+	return object, options[:len(options):len(options)], err
+}
+
+// negativeStore returns the store used to cache load function misses and errors,
+// or nil if negative caching is disabled or not available for the wrapped cache
+func (c *LoadableCache[T]) negativeStore() store.StoreInterface {
+	if c.negative == nil {
+		return nil
+	}
+
+	setterCache, ok := c.cache.(SetterCacheInterface[T])
+	if !ok {
+		return nil
+	}
+
+	return setterCache.GetCodec().GetStore()
+}
+
+// refresh loads the value in background and puts it back in cache,
+// unless a refresh of the same key is already in progress
+func (c *LoadableCache[T]) refresh(key any) {
+	cacheKey := c.cacheKey(key)
+	if _, inProgress := c.refreshing.LoadOrStore(cacheKey, true); inProgress {
+		return
+	}
+
+	started := c.lifecycle.goroutine(func() {
+		defer c.refreshing.Delete(cacheKey)
+
+		ctx := context.Background()
+		if item, err := c.load(ctx, key); err == nil {
+			_ = c.setLoaded(ctx, item)
+		}
+	})
+	if !started {
+		c.refreshing.Delete(cacheKey)
+	}
+}
+
+// refreshAheadScheduler refreshes the values scheduled by refresh-ahead, when they are due,
+// until the cache is closed
+func (c *LoadableCache[T]) refreshAheadScheduler() {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		entry, wait := c.refreshAhead.next()
+		if entry != nil {
+			select {
+			case c.refreshAhead.slots <- struct{}{}:
+			case <-c.refreshAhead.stop:
+				return
+			}
+
+			started := c.lifecycle.goroutine(func() {
+				defer func() { <-c.refreshAhead.slots }()
+				c.refreshAheadEntry(entry)
+			})
+			if !started {
+				return
+			}
+			continue
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if wait < 0 {
+			wait = time.Hour
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-timer.C:
+		case <-c.refreshAhead.wakeup:
+		case <-c.refreshAhead.stop:
+			return
+		}
+	}
+}
+
+// refreshAheadEntry loads the value of the given entry, puts it back in cache and
+// reschedules it using the TTL of the new value
+func (c *LoadableCache[T]) refreshAheadEntry(entry *refreshAheadEntry) {
+	var ttl time.Duration
+	defer func() {
+		c.refreshAhead.done(entry, ttl)
+	}()
+
+	// A refresh of the same key is already in progress
+	if _, inProgress := c.refreshing.LoadOrStore(entry.cacheKey, true); inProgress {
+		return
+	}
+	defer c.refreshing.Delete(entry.cacheKey)
+
+	if c.lifecycle.abandoned() {
+		return
+	}
+
+	ctx := context.Background()
+
+	item, err := c.load(ctx, entry.key)
+	if err != nil {
+		return
+	}
+
+	if err := c.setLoaded(ctx, item); err != nil {
+		return
+	}
+
+	if setterCache, ok := c.cache.(SetterCacheInterface[T]); ok {
+		_, ttl, _ = setterCache.GetWithTTL(ctx, entry.key)
+	}
+}
+
 // Set sets a value in available caches
 func (c *LoadableCache[T]) Set(ctx context.Context, key any, object T, options ...store.Option) error {
+	if c.lifecycle.isClosed() {
+		return ErrClosed
+	}
+
 	return c.cache.Set(ctx, key, object, options...)
 }
 
 // Delete removes a value from cache
 func (c *LoadableCache[T]) Delete(ctx context.Context, key any) error {
+	if c.lifecycle.isClosed() {
+		return ErrClosed
+	}
+
+	if c.refreshAhead != nil {
+		c.refreshAhead.forget(c.cacheKey(key))
+	}
+	if negativeStore := c.negativeStore(); negativeStore != nil {
+		c.negative.delete(ctx, negativeStore, key)
+	}
 	return c.cache.Delete(ctx, key)
 }
 
 // Invalidate invalidates cache item from given options
 func (c *LoadableCache[T]) Invalidate(ctx context.Context, options ...store.InvalidateOption) error {
+	if c.lifecycle.isClosed() {
+		return ErrClosed
+	}
+
 	return c.cache.Invalidate(ctx, options...)
 }
 
 // Clear resets all cache data
 func (c *LoadableCache[T]) Clear(ctx context.Context) error {
+	if c.lifecycle.isClosed() {
+		return ErrClosed
+	}
+
+	if c.refreshAhead != nil {
+		c.refreshAhead.reset()
+	}
+
 	return c.cache.Clear(ctx)
 }
 
@@ -96,9 +473,25 @@ func (c *LoadableCache[T]) GetType() string {
 	return LoadableType
 }
 
+// Close waits for the loaded values to be put in cache and for the background refreshes
+// to complete, then closes the wrapped cache. Next operations return ErrClosed.
 func (c *LoadableCache[T]) Close() error {
-	close(c.setChannel)
-	c.setterWg.Wait()
+	return closeWithTimeout(c.CloseWithContext)
+}
+
+// CloseWithContext waits for the loaded values to be put in cache and for the background
+// refreshes to complete until the context is done, then closes the wrapped cache.
+// Next operations return ErrClosed.
+func (c *LoadableCache[T]) CloseWithContext(ctx context.Context) error {
+	closed, err := c.lifecycle.close(ctx, func() {
+		close(c.setChannel)
+		if c.refreshAhead != nil {
+			close(c.refreshAhead.stop)
+		}
+	})
+	if !closed {
+		return nil
+	}
 
-	return nil
+	return firstError(err, closeWrapped(ctx, c.cache))
 }
@@ -3,9 +3,12 @@ package cache
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/eko/gocache/v3/lock"
 	"github.com/eko/gocache/v3/store"
 	mocksCache "github.com/eko/gocache/v3/test/mocks/cache"
 	"github.com/golang/mock/gomock"
@@ -122,6 +125,265 @@ func TestLoadableGetWhenAvailableInLoadFunc(t *testing.T) {
 	assert.Equal(t, cacheValue, value)
 }
 
+func TestLoadableGetWithLocker(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	cacheValue := "my-value"
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().Get(ctx, "my-key").Times(2).Return(nil, errors.New("unable to find in cache 1"))
+	cache1.EXPECT().Set(ctx, "my-key", cacheValue).Return(nil)
+
+	loadFunc := func(_ context.Context, key any) (any, error) {
+		return cacheValue, nil
+	}
+
+	locker := lock.NewMemory()
+
+	cache := NewLoadable[any](loadFunc, cache1, WithLoadableLocker[any](locker, time.Minute, time.Second))
+
+	// When
+	value, err := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, cacheValue, value)
+
+	// Lock should have been released
+	_, err = locker.Obtain(ctx, "gocache_lock_my-key", time.Minute)
+	assert.Nil(t, err)
+}
+
+func TestLoadableGetWithLockerWhenLockedByAnotherInstance(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	cacheValue := "my-value"
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	gomock.InOrder(
+		cache1.EXPECT().Get(gomock.Any(), "my-key").Return(nil, errors.New("unable to find in cache 1")),
+		cache1.EXPECT().Get(gomock.Any(), "my-key").Return(cacheValue, nil),
+	)
+
+	loadFunc := func(_ context.Context, key any) (any, error) {
+		return nil, errors.New("should not be called")
+	}
+
+	locker := lock.NewMemory()
+	_, err := locker.Obtain(ctx, "gocache_lock_my-key", time.Minute)
+	assert.Nil(t, err)
+
+	cache := NewLoadable[any](loadFunc, cache1, WithLoadableLocker[any](locker, time.Minute, time.Second))
+
+	// When
+	value, err := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, cacheValue, value)
+}
+
+func TestLoadableGetWithLockerWhenWaitIsElapsed(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	cacheValue := "my-value"
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().Get(gomock.Any(), "my-key").MinTimes(1).Return(nil, errors.New("unable to find in cache 1"))
+	cache1.EXPECT().Set(ctx, "my-key", cacheValue).Return(nil)
+
+	loadFunc := func(_ context.Context, key any) (any, error) {
+		return cacheValue, nil
+	}
+
+	locker := lock.NewMemory()
+	_, err := locker.Obtain(ctx, "gocache_lock_my-key", time.Minute)
+	assert.Nil(t, err)
+
+	cache := NewLoadable[any](loadFunc, cache1, WithLoadableLocker[any](locker, time.Minute, 100*time.Millisecond))
+
+	// When
+	value, err := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, cacheValue, value)
+}
+
+func TestLoadableGetWithXFetch(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	shared := New[string](store.NewGoCache(gocache.New(gocache.NoExpiration, time.Minute)), WithEnvelope[string](1))
+
+	// The value has been loaded by another instance, its load took one second
+	err := shared.setWithLoadDuration(ctx, "my-key", "my-value", time.Second, store.WithExpiration(10*time.Millisecond))
+	assert.Nil(t, err)
+
+	refreshed := make(chan bool)
+	loadFunc := func(_ context.Context, key any) (string, error) {
+		defer close(refreshed)
+		return "updated-value", nil
+	}
+
+	cache := NewLoadable[string](loadFunc, shared, WithLoadableXFetch[string](1.0))
+	cache.xfetch.random = func() float64 { return 0.5 }
+
+	// When
+	value, err := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "my-value", value)
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("value should have been refreshed in background")
+	}
+
+	assert.Eventually(t, func() bool {
+		value, _, loadDuration, err := shared.getWithLoadDuration(ctx, "my-key")
+		return err == nil && value == "updated-value" && loadDuration > 0
+	}, time.Second, time.Millisecond)
+}
+
+func TestLoadableGetWithXFetchWhenFarFromExpiry(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	shared := New[string](store.NewGoCache(gocache.New(gocache.NoExpiration, time.Minute)), WithEnvelope[string](1))
+
+	err := shared.setWithLoadDuration(ctx, "my-key", "my-value", time.Second, store.WithExpiration(time.Hour))
+	assert.Nil(t, err)
+
+	loadFunc := func(_ context.Context, key any) (string, error) {
+		return "", errors.New("should not be called")
+	}
+
+	cache := NewLoadable[string](loadFunc, shared, WithLoadableXFetch[string](1.0))
+	cache.xfetch.random = func() float64 { return 0.5 }
+
+	// When
+	value, err := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "my-value", value)
+}
+
+func TestLoadableGetWithXFetchWhenLoadDurationIsNotRecorded(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	shared := New[string](store.NewGoCache(gocache.New(gocache.NoExpiration, time.Minute)), WithEnvelope[string](1))
+
+	err := shared.Set(ctx, "my-key", "my-value", store.WithExpiration(10*time.Millisecond))
+	assert.Nil(t, err)
+
+	loadFunc := func(_ context.Context, key any) (string, error) {
+		return "", errors.New("should not be called")
+	}
+
+	cache := NewLoadable[string](loadFunc, shared, WithLoadableXFetch[string](1.0))
+	cache.xfetch.random = func() float64 { return 0.5 }
+
+	// When
+	value, err := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "my-value", value)
+}
+
+func TestLoadableGetWithNegativeCache(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	gocacheStore := store.NewGoCache(gocache.New(gocache.NoExpiration, time.Minute))
+
+	loadCalls := 0
+	loadFunc := func(_ context.Context, key any) (string, error) {
+		loadCalls++
+		return "", ErrNotExist
+	}
+
+	cache := NewLoadable[string](loadFunc, New[string](gocacheStore), WithLoadableNegativeCache[string](time.Minute, 0))
+
+	// When
+	_, err := cache.Get(ctx, "my-key")
+	_, secondErr := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Equal(t, ErrNotExist, err)
+	assert.Equal(t, ErrNotExist, secondErr)
+	assert.Equal(t, 1, loadCalls)
+}
+
+func TestLoadableGetWithNegativeCacheWhenError(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	gocacheStore := store.NewGoCache(gocache.New(gocache.NoExpiration, time.Minute))
+
+	loadCalls := 0
+	loadFunc := func(_ context.Context, key any) (string, error) {
+		loadCalls++
+		return "", errors.New("database is down")
+	}
+
+	cache := NewLoadable[string](loadFunc, New[string](gocacheStore), WithLoadableNegativeCache[string](time.Minute, time.Second))
+
+	// When
+	_, err := cache.Get(ctx, "my-key")
+	_, secondErr := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.EqualError(t, err, "database is down")
+
+	var cachedErr *CachedLoadError
+	assert.ErrorAs(t, secondErr, &cachedErr)
+	assert.EqualError(t, secondErr, "database is down")
+	assert.Equal(t, 1, loadCalls)
+}
+
+func TestLoadableDeleteWithNegativeCache(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	gocacheStore := store.NewGoCache(gocache.New(gocache.NoExpiration, time.Minute))
+
+	loadCalls := 0
+	loadFunc := func(_ context.Context, key any) (string, error) {
+		loadCalls++
+		return "", ErrNotExist
+	}
+
+	cache := NewLoadable[string](loadFunc, New[string](gocacheStore), WithLoadableNegativeCache[string](time.Minute, 0))
+
+	_, err := cache.Get(ctx, "my-key")
+	assert.Equal(t, ErrNotExist, err)
+
+	// When
+	err = cache.Delete(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+
+	_, err = cache.Get(ctx, "my-key")
+	assert.Equal(t, ErrNotExist, err)
+	assert.Equal(t, 2, loadCalls)
+}
+
 func TestLoadableDelete(t *testing.T) {
 	// Given
 	ctrl := gomock.NewController(t)
@@ -299,3 +561,227 @@ func TestLoadableGocache(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, cacheValue, value)
 }
+
+func TestLoadableClose(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	set := false
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().Get(ctx, "my-key").Return(nil, errors.New("unable to find in cache 1"))
+	cache1.EXPECT().Set(ctx, "my-key", "my-value").DoAndReturn(func(_ context.Context, _ any, _ any, _ ...store.Option) error {
+		time.Sleep(20 * time.Millisecond)
+		set = true
+		return nil
+	})
+	cache1.EXPECT().Close().Return(nil)
+
+	loadFunc := func(_ context.Context, key any) (any, error) {
+		return "my-value", nil
+	}
+
+	cache := NewLoadable[any](loadFunc, cache1)
+
+	_, err := cache.Get(ctx, "my-key")
+	assert.Nil(t, err)
+
+	// When
+	err = cache.Close()
+
+	// Then
+	assert.Nil(t, err)
+	assert.True(t, set)
+
+	_, err = cache.Get(ctx, "my-key")
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.ErrorIs(t, cache.Set(ctx, "my-key", "my-value"), ErrClosed)
+
+	// Closing twice is a no-op
+	assert.Nil(t, cache.Close())
+}
+
+func TestLoadableGetWithRefreshAhead(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	var calls int32
+	loadFunc := func(_ context.Context, key any) (string, error) {
+		return fmt.Sprintf("value-%d", atomic.AddInt32(&calls, 1)), nil
+	}
+
+	wrapped := New[string](store.NewGoCache(gocache.New(200*time.Millisecond, time.Minute)))
+	cache := NewLoadable[string](loadFunc, wrapped, WithLoadableRefreshAhead[string](0.5, time.Minute, 2))
+	defer cache.Close()
+
+	value, err := cache.Get(ctx, "my-key")
+	assert.Nil(t, err)
+	assert.Equal(t, "value-1", value)
+
+	assert.Eventually(t, func() bool {
+		_, err := wrapped.Get(ctx, "my-key")
+		return err == nil
+	}, time.Second, time.Millisecond)
+
+	// When
+	value, err = cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "value-1", value)
+
+	assert.Eventually(t, func() bool {
+		value, err := wrapped.Get(ctx, "my-key")
+		return err == nil && value == "value-2"
+	}, time.Second, time.Millisecond)
+
+	// The value is refreshed before it expires as long as it is read
+	for i := 0; i < 10; i++ {
+		_, err := wrapped.Get(ctx, "my-key")
+		assert.Nil(t, err)
+		_, err = cache.Get(ctx, "my-key")
+		assert.Nil(t, err)
+		time.Sleep(30 * time.Millisecond)
+	}
+	assert.GreaterOrEqual(t, atomic.LoadInt32(&calls), int32(3))
+}
+
+func TestLoadableGetWithRefreshAheadWhenKeyIsIdle(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	var calls int32
+	loadFunc := func(_ context.Context, key any) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		return "my-value", nil
+	}
+
+	wrapped := New[string](store.NewGoCache(gocache.New(100*time.Millisecond, time.Minute)))
+	cache := NewLoadable[string](loadFunc, wrapped, WithLoadableRefreshAhead[string](0.5, 10*time.Millisecond, 2))
+	defer cache.Close()
+
+	assert.Nil(t, wrapped.Set(ctx, "my-key", "my-value"))
+
+	// When
+	_, err := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+
+	time.Sleep(150 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+
+	_, err = wrapped.Get(ctx, "my-key")
+	assert.True(t, errors.Is(err, store.NotFound{}))
+}
+
+func TestLoadableGetWithRefreshAheadUsesKeysOfWrappedCache(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	type bookKey struct {
+		ID *int
+	}
+
+	loadFunc := func(_ context.Context, key any) (string, error) {
+		return "my-value", nil
+	}
+
+	wrapped := New[string](store.NewGoCache(gocache.New(time.Minute, time.Minute)), WithKeyGenerator[string](JSONKeyGenerator{}))
+	cache := NewLoadable[string](loadFunc, wrapped, WithLoadableRefreshAhead[string](0.5, time.Minute, 2))
+	defer cache.Close()
+
+	id, otherID := 42, 42
+	assert.Nil(t, wrapped.Set(ctx, bookKey{ID: &id}, "my-value"))
+
+	// When
+	_, err := cache.Get(ctx, bookKey{ID: &id})
+	assert.Nil(t, err)
+	_, err = cache.Get(ctx, bookKey{ID: &otherID})
+	assert.Nil(t, err)
+
+	// Then
+	cache.refreshAhead.mu.Lock()
+	defer cache.refreshAhead.mu.Unlock()
+
+	assert.Len(t, cache.refreshAhead.entries, 1)
+	assert.Contains(t, cache.refreshAhead.entries, JSONKeyGenerator{}.GenerateKey(bookKey{ID: &id}))
+}
+
+func TestNewLoadableWithOptions(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+
+	loadFunc := func(_ context.Context, key any) (any, []store.Option, error) {
+		return "test data loaded", nil, nil
+	}
+
+	// When
+	cache := NewLoadableWithOptions[any](loadFunc, cache1)
+
+	// Then
+	assert.IsType(t, new(LoadableCache[any]), cache)
+
+	assert.Nil(t, cache.loadFunc)
+	assert.IsType(t, new(LoadFunctionWithOptions[any]), &cache.loadFuncWithOptions)
+	assert.Equal(t, cache1, cache.cache)
+}
+
+func TestLoadableGetWithLoadFunctionWithOptions(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	loaderOptions := make([]store.Option, 0, 10)
+	loaderOptions = append(loaderOptions, store.WithExpiration(time.Hour), store.WithTags([]string{"my-tag"}))
+
+	loadFunc := func(_ context.Context, key any) (string, []store.Option, error) {
+		return "my-value", loaderOptions, nil
+	}
+
+	wrapped := New[string](store.NewGoCache(gocache.New(gocache.NoExpiration, time.Minute)))
+	cache := NewLoadableWithOptions[string](loadFunc, wrapped)
+	defer cache.Close()
+
+	// When
+	value, err := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "my-value", value)
+
+	assert.Eventually(t, func() bool {
+		_, err := wrapped.Get(ctx, "my-key")
+		return err == nil
+	}, time.Second, time.Millisecond)
+
+	_, ttl, err := wrapped.GetWithTTL(ctx, "my-key")
+	assert.Nil(t, err)
+	assert.InDelta(t, time.Hour, ttl, float64(time.Second))
+
+	assert.Nil(t, cache.Invalidate(ctx, store.WithInvalidateTags([]string{"my-tag"})))
+
+	_, err = wrapped.Get(ctx, "my-key")
+	assert.True(t, errors.Is(err, store.NotFound{}))
+}
+
+func TestCallLoadFunctionCapsOptions(t *testing.T) {
+	// Given
+	loaderOptions := make([]store.Option, 1, 10)
+	loaderOptions[0] = store.WithExpiration(time.Hour)
+
+	loadFunc := func(_ context.Context, key any) (string, []store.Option, error) {
+		return "my-value", loaderOptions, nil
+	}
+
+	// When
+	_, options, err := callLoadFunction[string](context.Background(), "my-key", nil, loadFunc)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Len(t, options, 1)
+	assert.Equal(t, 1, cap(options))
+}
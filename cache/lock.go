@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eko/gocache/v3/lock"
+	"github.com/eko/gocache/v3/store"
+)
+
+const (
+	// LockKeyPattern represents the key pattern used to lock a cache key while loading it
+	LockKeyPattern = "gocache_lock_%s"
+
+	lockRetryInterval = 50 * time.Millisecond
+)
+
+// loadLock holds the configuration needed to take a distributed lock
+// before calling a load function
+type loadLock struct {
+	locker     lock.LockerInterface
+	ttl        time.Duration
+	wait       time.Duration
+	normalizer *keyNormalizer
+	// cacheKey returns the cache key of a key, using the key generator of the wrapped cache,
+	// so every instance locks the same key whatever the way key objects are printed
+	cacheKey func(key any) string
+}
+
+// useKeysOf builds lock keys from the cache keys of the given cache, and normalizes
+// them when the given cache normalizes its keys, respecting the key constraints of
+// the locker if it declares some, or the ones of the cache store otherwise
+func (l *loadLock) useKeysOf(cache any) {
+	l.cacheKey = cacheKeyFunc(cache)
+
+	provider, ok := cache.(keyNormalizerProvider)
+	if !ok || provider.getKeyNormalizer() == nil {
+		return
+	}
+
+	normalizer := *provider.getKeyNormalizer()
+	if constrained, ok := l.locker.(store.KeyConstraintsInterface); ok {
+		constraints := constrained.GetKeyConstraints()
+		normalizer.constraints = &constraints
+	}
+
+	l.normalizer = &normalizer
+}
+
+// obtain takes the lock on the given key and returns the owner token
+func (l *loadLock) obtain(ctx context.Context, key any) (string, error) {
+	return l.locker.Obtain(ctx, l.key(key), l.ttl)
+}
+
+// release releases the lock on the given key. Errors are ignored as
+// the lock expires by itself anyway.
+func (l *loadLock) release(ctx context.Context, key any, token string) {
+	_ = l.locker.Release(ctx, l.key(key), token)
+}
+
+// key returns the lock key of the given key
+func (l *loadLock) key(key any) string {
+	cacheKey := getCacheKey(key)
+	if l.cacheKey != nil {
+		cacheKey = l.cacheKey(key)
+	}
+
+	lockKey := fmt.Sprintf(LockKeyPattern, cacheKey)
+
+	if l.normalizer != nil {
+		lockKey, _ = l.normalizer.normalize(lockKey)
+	}
+
+	return lockKey
+}
+
+// waitFor calls get until it succeeds or until the wait duration is elapsed
+func waitFor[T any](ctx context.Context, wait time.Duration, get func(ctx context.Context) (T, error)) (T, bool) {
+	ticker := time.NewTicker(lockRetryInterval)
+	defer ticker.Stop()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return *new(T), false
+		case <-timer.C:
+			return *new(T), false
+		case <-ticker.C:
+			if value, err := get(ctx); err == nil {
+				return value, true
+			}
+		}
+	}
+}
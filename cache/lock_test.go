@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eko/gocache/v3/lock"
+	"github.com/eko/gocache/v3/store"
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadLockKey(t *testing.T) {
+	// Given
+	l := &loadLock{locker: lock.NewMemory()}
+	l.useKeysOf(New[string](store.NewGoCache(gocache.New(time.Minute, time.Minute))))
+
+	// When
+	key := l.key("my key")
+
+	// Then
+	assert.Equal(t, "gocache_lock_my key", key)
+}
+
+func TestLoadLockKeyWhenCacheNormalizesKeys(t *testing.T) {
+	// Given
+	cacheKey := strings.Repeat("a", 300)
+
+	cache := New[string](store.NewGoCache(gocache.New(time.Minute, time.Minute)), WithKeyNormalizer[string]())
+
+	l := &loadLock{locker: lock.NewMemcache(nil)}
+	l.useKeysOf(cache)
+
+	// When
+	key := l.key(cacheKey)
+
+	// Then
+	assert.LessOrEqual(t, len(key), store.MemcacheMaxKeyLength)
+	assert.True(t, strings.HasPrefix(key, "gocache_lock_aaa"))
+	assert.Nil(t, l.locker.(*lock.MemcacheLocker).GetKeyConstraints().Validate(key))
+}
+
+func TestLoadLockKeyWhenCacheHasKeyGenerator(t *testing.T) {
+	// Given
+	type bookKey struct {
+		ID *int
+	}
+
+	id := 42
+	cache := New[string](
+		store.NewGoCache(gocache.New(time.Minute, time.Minute)),
+		WithKeyGenerator[string](JSONKeyGenerator{}),
+	)
+
+	l := &loadLock{locker: lock.NewMemory()}
+	l.useKeysOf(cache)
+
+	otherID := 42
+
+	// When
+	key := l.key(bookKey{ID: &id})
+	otherKey := l.key(bookKey{ID: &otherID})
+
+	// Then
+	assert.Equal(t, "gocache_lock_"+JSONKeyGenerator{}.GenerateKey(bookKey{ID: &id}), key)
+	assert.Equal(t, key, otherKey)
+}
@@ -14,8 +14,9 @@ const (
 
 // MetricCache is the struct that specifies metrics available for different caches
 type MetricCache[T any] struct {
-	metrics metrics.MetricsInterface
-	cache   CacheInterface[T]
+	metrics   metrics.MetricsInterface
+	cache     CacheInterface[T]
+	lifecycle lifecycle
 }
 
 // NewMetric creates a new cache with metrics and a given cache storage
@@ -28,6 +29,10 @@ func NewMetric[T any](metrics metrics.MetricsInterface, cache CacheInterface[T])
 
 // Get obtains a value from cache and also records metrics
 func (c *MetricCache[T]) Get(ctx context.Context, key any) (T, error) {
+	if c.lifecycle.isClosed() {
+		return *new(T), ErrClosed
+	}
+
 	result, err := c.cache.Get(ctx, key)
 
 	c.updateMetrics(c.cache)
@@ -37,29 +42,68 @@ func (c *MetricCache[T]) Get(ctx context.Context, key any) (T, error) {
 
 // Set sets a value from the cache
 func (c *MetricCache[T]) Set(ctx context.Context, key any, object T, options ...store.Option) error {
+	if c.lifecycle.isClosed() {
+		return ErrClosed
+	}
+
 	return c.cache.Set(ctx, key, object, options...)
 }
 
 // Delete removes a value from the cache
 func (c *MetricCache[T]) Delete(ctx context.Context, key any) error {
+	if c.lifecycle.isClosed() {
+		return ErrClosed
+	}
+
 	return c.cache.Delete(ctx, key)
 }
 
 // Invalidate invalidates cache item from given options
 func (c *MetricCache[T]) Invalidate(ctx context.Context, options ...store.InvalidateOption) error {
+	if c.lifecycle.isClosed() {
+		return ErrClosed
+	}
+
 	return c.cache.Invalidate(ctx, options...)
 }
 
 // Clear resets all cache data
 func (c *MetricCache[T]) Clear(ctx context.Context) error {
+	if c.lifecycle.isClosed() {
+		return ErrClosed
+	}
+
 	return c.cache.Clear(ctx)
 }
 
+// Close closes the wrapped cache. The metrics provider is not closed as it can be shared.
+// Next operations return ErrClosed.
+func (c *MetricCache[T]) Close() error {
+	return closeWithTimeout(c.CloseWithContext)
+}
+
+// CloseWithContext closes the wrapped cache before the context deadline.
+// Next operations return ErrClosed.
+func (c *MetricCache[T]) CloseWithContext(ctx context.Context) error {
+	if closed, _ := c.lifecycle.close(ctx, nil); !closed {
+		return nil
+	}
+
+	return closeWrapped(ctx, c.cache)
+}
+
 // Get obtains a value from cache and also records metrics
 func (c *MetricCache[T]) updateMetrics(cache CacheInterface[T]) {
 	switch current := cache.(type) {
 	case *ChainCache[T]:
-		for _, cache := range current.GetCaches() {
+		named, ok := c.metrics.(metrics.NamedMetricsInterface)
+
+		for i, cache := range current.GetCaches() {
+			if ok {
+				named.RecordFromCodecWithName(current.layerName(i), cache.GetCodec())
+				continue
+			}
+
 			c.updateMetrics(cache)
 		}
 
@@ -99,6 +99,41 @@ func TestMetricGetWhenChainCache(t *testing.T) {
 	assert.Equal(t, cacheValue, value)
 }
 
+func TestMetricGetWhenChainCacheWithNamedMetrics(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	codec1 := mocksCodec.NewMockCodecInterface(ctrl)
+	codec2 := mocksCodec.NewMockCodecInterface(ctrl)
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().GetWithTTL(ctx, "my-key").Return("my-value", 0*time.Second, nil)
+	cache1.EXPECT().GetCodec().Return(codec1)
+
+	cache2 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache2.EXPECT().GetCodec().Return(codec2)
+
+	chainCache := NewChainWithOptions[any](
+		[]SetterCacheInterface[any]{cache1, cache2},
+		WithChainLayerNames[any]("regional", "global"),
+	)
+
+	metrics := mocksMetrics.NewMockNamedMetricsInterface(ctrl)
+	metrics.EXPECT().RecordFromCodecWithName("regional", codec1)
+	metrics.EXPECT().RecordFromCodecWithName("global", codec2)
+
+	cache := NewMetric[any](metrics, chainCache)
+
+	// When
+	value, err := cache.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "my-value", value)
+}
+
 func TestMetricSet(t *testing.T) {
 	// Given
 	ctrl := gomock.NewController(t)
@@ -263,3 +298,28 @@ func TestMetricGetType(t *testing.T) {
 	// When - Then
 	assert.Equal(t, MetricType, cache.GetType())
 }
+
+func TestMetricClose(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	cache1 := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache1.EXPECT().Close().Return(nil)
+
+	metrics := mocksMetrics.NewMockMetricsInterface(ctrl)
+
+	cache := NewMetric[any](metrics, cache1)
+
+	// When
+	err := cache.Close()
+	secondErr := cache.Close()
+
+	// Then
+	assert.Nil(t, err)
+	assert.Nil(t, secondErr)
+
+	_, getErr := cache.Get(context.Background(), "my-key")
+	assert.Equal(t, ErrClosed, getErr)
+	assert.Equal(t, ErrClosed, cache.Set(context.Background(), "my-key", "my-value"))
+	assert.Equal(t, ErrClosed, cache.Clear(context.Background()))
+}
@@ -0,0 +1,155 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eko/gocache/v3/store"
+)
+
+const (
+	// NegativeCacheKeyPattern represents the key pattern used to store tombstones of loader misses and errors
+	NegativeCacheKeyPattern = "gocache_negative_%s"
+
+	negativeNotExistValue = "not-exist"
+	negativeErrorPrefix   = "error:"
+	// negativeErrorCodeSeparator separates the code of a cached error from its message
+	negativeErrorCodeSeparator = "\x1f"
+)
+
+// ErrNotExist can be returned by load functions when the requested value does not exist
+// in the data source. When negative caching is enabled, it is cached so next calls for
+// the same key return it without calling the load function.
+var ErrNotExist = errors.New("value does not exist")
+
+// CachedLoadError is returned instead of calling the load function when a previous
+// load function error has been cached
+type CachedLoadError struct {
+	// Code is the message of the sentinel error wrapped by the load error, when it is one of
+	// the errors given to the negative cache option, or empty otherwise
+	Code string
+
+	message string
+	err     error
+}
+
+func (e *CachedLoadError) Error() string {
+	return e.message
+}
+
+// Unwrap returns the sentinel error wrapped by the load error, so errors.Is can be used
+// against it. It is nil when the load error did not wrap any of the given errors.
+func (e *CachedLoadError) Unwrap() error {
+	return e.err
+}
+
+// negativeCache stores tombstones for loader misses and errors directly in
+// the store of the wrapped cache, under a dedicated key
+type negativeCache struct {
+	notExistTTL time.Duration
+	errorTTL    time.Duration
+	// errors are the sentinel errors recorded alongside the cached errors wrapping them
+	errors []error
+	// keys returns the cache key and the store key of a key, using the key generator
+	// and the key normalizer of the wrapped cache
+	keys func(key any) (string, string)
+}
+
+// useKeysOf builds tombstone keys the way the given cache builds its keys, if it is a Cache
+func (n *negativeCache) useKeysOf(cache any) {
+	if keyer, ok := cache.(cacheKeyer); ok {
+		n.keys = keyer.getKeys
+	}
+}
+
+// get returns the cached load error of the given key, if any
+func (n *negativeCache) get(ctx context.Context, s store.StoreInterface, key any) error {
+	value, err := s.Get(ctx, n.key(key))
+	if err != nil {
+		return nil
+	}
+
+	var tombstone string
+	switch v := value.(type) {
+	case []byte:
+		tombstone = string(v)
+	case string:
+		tombstone = v
+	default:
+		return nil
+	}
+
+	if tombstone == negativeNotExistValue {
+		return ErrNotExist
+	}
+	if strings.HasPrefix(tombstone, negativeErrorPrefix) {
+		cachedErr := &CachedLoadError{message: strings.TrimPrefix(tombstone, negativeErrorPrefix)}
+
+		if code, message, found := strings.Cut(cachedErr.message, negativeErrorCodeSeparator); found {
+			cachedErr.Code = code
+			cachedErr.message = message
+			cachedErr.err = n.sentinel(code)
+		}
+
+		return cachedErr
+	}
+
+	return nil
+}
+
+// set stores a tombstone for the given load error if this kind of error has to be cached
+func (n *negativeCache) set(ctx context.Context, s store.StoreInterface, key any, loadErr error) {
+	var cachedErr *CachedLoadError
+
+	switch {
+	case errors.Is(loadErr, ErrNotExist):
+		if n.notExistTTL > 0 {
+			_ = s.Set(ctx, n.key(key), []byte(negativeNotExistValue), store.WithExpiration(n.notExistTTL))
+		}
+	case errors.As(loadErr, &cachedErr):
+		// already coming from the cache
+	default:
+		if n.errorTTL > 0 {
+			tombstone := negativeErrorPrefix + loadErr.Error()
+			for _, sentinel := range n.errors {
+				if errors.Is(loadErr, sentinel) {
+					tombstone = negativeErrorPrefix + sentinel.Error() + negativeErrorCodeSeparator + loadErr.Error()
+					break
+				}
+			}
+
+			_ = s.Set(ctx, n.key(key), []byte(tombstone), store.WithExpiration(n.errorTTL))
+		}
+	}
+}
+
+// delete removes the tombstone of the given key
+func (n *negativeCache) delete(ctx context.Context, s store.StoreInterface, key any) {
+	_ = s.Delete(ctx, n.key(key))
+}
+
+// sentinel returns the sentinel error having the given code, if any
+func (n *negativeCache) sentinel(code string) error {
+	for _, sentinel := range n.errors {
+		if sentinel.Error() == code {
+			return sentinel
+		}
+	}
+
+	return nil
+}
+
+// key returns the store key of the tombstone of the given key
+func (n *negativeCache) key(key any) string {
+	if n.keys == nil {
+		return fmt.Sprintf(NegativeCacheKeyPattern, getCacheKey(key))
+	}
+
+	cacheKey, _ := n.keys(key)
+	_, storeKey := n.keys(fmt.Sprintf(NegativeCacheKeyPattern, cacheKey))
+
+	return storeKey
+}
@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eko/gocache/v3/store"
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegativeCacheSetWhenNotExist(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	gocacheStore := store.NewGoCache(gocache.New(gocache.NoExpiration, time.Minute))
+
+	n := &negativeCache{notExistTTL: time.Minute}
+
+	// When
+	n.set(ctx, gocacheStore, "my-key", ErrNotExist)
+
+	// Then
+	assert.Equal(t, ErrNotExist, n.get(ctx, gocacheStore, "my-key"))
+
+	_, ttl, err := gocacheStore.GetWithTTL(ctx, "gocache_negative_my-key")
+	assert.Nil(t, err)
+	assert.LessOrEqual(t, ttl, time.Minute)
+}
+
+func TestNegativeCacheSetWhenWrappedNotExist(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	gocacheStore := store.NewGoCache(gocache.New(gocache.NoExpiration, time.Minute))
+
+	n := &negativeCache{notExistTTL: time.Minute}
+
+	// When
+	n.set(ctx, gocacheStore, "my-key", fmt.Errorf("book 42: %w", ErrNotExist))
+
+	// Then
+	assert.ErrorIs(t, n.get(ctx, gocacheStore, "my-key"), ErrNotExist)
+}
+
+func TestNegativeCacheSetWhenError(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	gocacheStore := store.NewGoCache(gocache.New(gocache.NoExpiration, time.Minute))
+
+	n := &negativeCache{notExistTTL: time.Minute, errorTTL: time.Second}
+
+	// When
+	n.set(ctx, gocacheStore, "my-key", errors.New("database is down"))
+
+	// Then
+	err := n.get(ctx, gocacheStore, "my-key")
+
+	var cachedErr *CachedLoadError
+	assert.ErrorAs(t, err, &cachedErr)
+	assert.EqualError(t, err, "database is down")
+}
+
+func TestNegativeCacheSetWhenErrorAndErrorsNotCached(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	gocacheStore := store.NewGoCache(gocache.New(gocache.NoExpiration, time.Minute))
+
+	n := &negativeCache{notExistTTL: time.Minute}
+
+	// When
+	n.set(ctx, gocacheStore, "my-key", errors.New("database is down"))
+
+	// Then
+	assert.Nil(t, n.get(ctx, gocacheStore, "my-key"))
+}
+
+func TestNegativeCacheDelete(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	gocacheStore := store.NewGoCache(gocache.New(gocache.NoExpiration, time.Minute))
+
+	n := &negativeCache{notExistTTL: time.Minute}
+	n.set(ctx, gocacheStore, "my-key", ErrNotExist)
+
+	// When
+	n.delete(ctx, gocacheStore, "my-key")
+
+	// Then
+	assert.Nil(t, n.get(ctx, gocacheStore, "my-key"))
+}
+
+func TestNegativeCacheSetWhenErrorWrapsSentinel(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	gocacheStore := store.NewGoCache(gocache.New(gocache.NoExpiration, time.Minute))
+
+	errRateLimited := errors.New("rate limited")
+
+	n := &negativeCache{errorTTL: time.Second, errors: []error{errRateLimited}}
+
+	// When
+	n.set(ctx, gocacheStore, "my-key", fmt.Errorf("book 42: %w", errRateLimited))
+
+	// Then
+	err := n.get(ctx, gocacheStore, "my-key")
+
+	var cachedErr *CachedLoadError
+	assert.ErrorAs(t, err, &cachedErr)
+	assert.ErrorIs(t, err, errRateLimited)
+	assert.Equal(t, "rate limited", cachedErr.Code)
+	assert.EqualError(t, err, "book 42: rate limited")
+}
+
+func TestNegativeCacheKeyUsesWrappedCacheKeys(t *testing.T) {
+	// Given
+	gocacheStore := store.NewGoCache(gocache.New(gocache.NoExpiration, time.Minute))
+
+	cache := New[string](gocacheStore,
+		WithKeyGenerator[string](JSONKeyGenerator{}),
+		WithKeyNormalizer[string](WithKeyNormalizerConstraints(store.KeyConstraints{MaxLength: 100})),
+	)
+
+	n := &negativeCache{notExistTTL: time.Minute}
+	n.useKeysOf(cache)
+
+	longKey := strings.Repeat("a", 200)
+
+	// When - Then
+	assert.Equal(t, "gocache_negative_"+JSONKeyGenerator{}.GenerateKey(map[string]int{"id": 42}), n.key(map[string]int{"id": 42}))
+	assert.LessOrEqual(t, len(n.key(longKey)), 100)
+	assert.True(t, strings.HasPrefix(n.key(longKey), "gocache_negative_aaa"))
+}
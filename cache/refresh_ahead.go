@@ -0,0 +1,202 @@
+package cache
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// defaultRefreshAheadRatio is the ratio of the TTL of values after which they are refreshed,
+// used when the given ratio is not between 0 and 1
+const defaultRefreshAheadRatio = 0.8
+
+// refreshAheadEntry represents a key read recently, to be refreshed at refreshAt
+type refreshAheadEntry struct {
+	cacheKey   string
+	key        any
+	refreshAt  time.Time
+	lastAccess time.Time
+	refreshing bool
+	// index is the position of the entry in the queue, or -1 when it is not queued
+	index int
+}
+
+// refreshAheadQueue is a priority queue of entries ordered by refresh date, see container/heap
+type refreshAheadQueue []*refreshAheadEntry
+
+func (q refreshAheadQueue) Len() int {
+	return len(q)
+}
+
+func (q refreshAheadQueue) Less(i, j int) bool {
+	return q[i].refreshAt.Before(q[j].refreshAt)
+}
+
+func (q refreshAheadQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *refreshAheadQueue) Push(x any) {
+	entry := x.(*refreshAheadEntry)
+	entry.index = len(*q)
+	*q = append(*q, entry)
+}
+
+func (q *refreshAheadQueue) Pop() any {
+	old := *q
+	entry := old[len(old)-1]
+	old[len(old)-1] = nil
+	entry.index = -1
+	*q = old[:len(old)-1]
+	return entry
+}
+
+// refreshAhead schedules the refresh of the keys read within the access window,
+// once the given ratio of their TTL has elapsed
+type refreshAhead struct {
+	ratio  float64
+	window time.Duration
+	slots  chan struct{}
+	wakeup chan struct{}
+	stop   chan struct{}
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*refreshAheadEntry
+	queue   refreshAheadQueue
+}
+
+func newRefreshAhead(ratio float64, window time.Duration, concurrency int) *refreshAhead {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if !(ratio > 0 && ratio < 1) {
+		// Values would be refreshed in a loop (ratio <= 0) or after they expired (ratio >= 1)
+		ratio = defaultRefreshAheadRatio
+	}
+
+	return &refreshAhead{
+		ratio:   ratio,
+		window:  window,
+		slots:   make(chan struct{}, concurrency),
+		wakeup:  make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		now:     time.Now,
+		entries: make(map[string]*refreshAheadEntry),
+	}
+}
+
+// touch records an access to the given key, which expires in ttl, and schedules its refresh
+// if it is not already scheduled
+func (r *refreshAhead) touch(cacheKey string, key any, ttl time.Duration) {
+	now := r.now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.entries[cacheKey]
+	if !ok {
+		entry = &refreshAheadEntry{cacheKey: cacheKey, key: key, index: -1}
+		r.entries[cacheKey] = entry
+	}
+	entry.lastAccess = now
+
+	if entry.index < 0 && !entry.refreshing {
+		r.schedule(entry, now, ttl)
+	}
+}
+
+// schedule queues the refresh of the given entry once the ratio of the given TTL has elapsed
+func (r *refreshAhead) schedule(entry *refreshAheadEntry, now time.Time, ttl time.Duration) {
+	if ttl <= 0 {
+		// The value does not expire, there is nothing to refresh
+		delete(r.entries, entry.cacheKey)
+		return
+	}
+
+	entry.refreshAt = now.Add(time.Duration(r.ratio * float64(ttl)))
+	heap.Push(&r.queue, entry)
+
+	if entry.index == 0 {
+		select {
+		case r.wakeup <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// next returns the next entry to refresh, or the duration to wait for it. Entries
+// that have not been accessed within the window are removed instead of being returned.
+// It returns a negative duration when no entry is scheduled.
+func (r *refreshAhead) next() (*refreshAheadEntry, time.Duration) {
+	now := r.now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for len(r.queue) > 0 {
+		entry := r.queue[0]
+		if wait := entry.refreshAt.Sub(now); wait > 0 {
+			return nil, wait
+		}
+
+		heap.Pop(&r.queue)
+
+		if now.Sub(entry.lastAccess) > r.window {
+			delete(r.entries, entry.cacheKey)
+			continue
+		}
+
+		entry.refreshing = true
+		return entry, 0
+	}
+
+	return nil, -1
+}
+
+// done reschedules the given refreshed entry using the TTL of the refreshed value,
+// or removes it when the refresh failed
+func (r *refreshAhead) done(entry *refreshAheadEntry, ttl time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry.refreshing = false
+
+	if r.entries[entry.cacheKey] != entry {
+		// The key has been forgotten while it was refreshed
+		return
+	}
+
+	r.schedule(entry, r.now(), ttl)
+}
+
+// forget stops refreshing the given key
+func (r *refreshAhead) forget(cacheKey string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.entries[cacheKey]
+	if !ok {
+		return
+	}
+
+	delete(r.entries, cacheKey)
+	if entry.index >= 0 {
+		heap.Remove(&r.queue, entry.index)
+	}
+}
+
+// reset stops refreshing every key
+func (r *refreshAhead) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, entry := range r.queue {
+		entry.index = -1
+	}
+
+	r.entries = make(map[string]*refreshAheadEntry)
+	r.queue = nil
+}
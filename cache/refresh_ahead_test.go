@@ -0,0 +1,174 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRefreshAhead(now *time.Time) *refreshAhead {
+	refresh := newRefreshAhead(0.8, time.Minute, 2)
+	refresh.now = func() time.Time { return *now }
+
+	return refresh
+}
+
+func TestRefreshAheadNext(t *testing.T) {
+	// Given
+	now := time.Now()
+	refresh := newTestRefreshAhead(&now)
+
+	refresh.touch("key-1", "key-1", 10*time.Second)
+	refresh.touch("key-2", "key-2", 5*time.Second)
+
+	// When - Then
+	entry, wait := refresh.next()
+	assert.Nil(t, entry)
+	assert.Equal(t, 4*time.Second, wait)
+
+	now = now.Add(4 * time.Second)
+	entry, _ = refresh.next()
+	assert.Equal(t, "key-2", entry.key)
+	assert.True(t, entry.refreshing)
+
+	entry, wait = refresh.next()
+	assert.Nil(t, entry)
+	assert.Equal(t, 4*time.Second, wait)
+}
+
+func TestRefreshAheadWhenRatioIsInvalid(t *testing.T) {
+	for _, ratio := range []float64{-1, 0, 1, 1.5} {
+		// Given
+		now := time.Now()
+
+		refresh := newRefreshAhead(ratio, time.Minute, 2)
+		refresh.now = func() time.Time { return now }
+
+		// When
+		refresh.touch("my-key", "my-key", 10*time.Second)
+
+		// Then
+		entry, wait := refresh.next()
+		assert.Nil(t, entry, ratio)
+		assert.Equal(t, 8*time.Second, wait, ratio)
+	}
+}
+
+func TestRefreshAheadNextWhenEmpty(t *testing.T) {
+	// Given
+	now := time.Now()
+	refresh := newTestRefreshAhead(&now)
+
+	// When
+	entry, wait := refresh.next()
+
+	// Then
+	assert.Nil(t, entry)
+	assert.Less(t, wait, time.Duration(0))
+}
+
+func TestRefreshAheadTouchWhenValueDoesNotExpire(t *testing.T) {
+	// Given
+	now := time.Now()
+	refresh := newTestRefreshAhead(&now)
+
+	// When
+	refresh.touch("my-key", "my-key", 0)
+
+	// Then
+	assert.Empty(t, refresh.entries)
+	assert.Empty(t, refresh.queue)
+}
+
+func TestRefreshAheadRemovesIdleKeys(t *testing.T) {
+	// Given
+	now := time.Now()
+	refresh := newTestRefreshAhead(&now)
+
+	refresh.touch("idle-key", "idle-key", 2*time.Minute)
+	refresh.touch("read-key", "read-key", 2*time.Minute)
+
+	now = now.Add(time.Minute)
+	refresh.touch("read-key", "read-key", time.Minute)
+
+	// When
+	now = now.Add(40 * time.Second)
+	entry, _ := refresh.next()
+
+	// Then
+	assert.Equal(t, "read-key", entry.key)
+	assert.NotContains(t, refresh.entries, "idle-key")
+	assert.Empty(t, refresh.queue)
+}
+
+func TestRefreshAheadDone(t *testing.T) {
+	// Given
+	now := time.Now()
+	refresh := newTestRefreshAhead(&now)
+
+	refresh.touch("my-key", "my-key", 10*time.Second)
+
+	now = now.Add(8 * time.Second)
+	entry, _ := refresh.next()
+
+	// When
+	refresh.done(entry, 10*time.Second)
+
+	// Then
+	assert.False(t, entry.refreshing)
+	assert.Equal(t, now.Add(8*time.Second), entry.refreshAt)
+	assert.Len(t, refresh.queue, 1)
+
+	// When the refresh failed
+	now = now.Add(8 * time.Second)
+	entry, _ = refresh.next()
+	refresh.done(entry, 0)
+
+	// Then
+	assert.Empty(t, refresh.entries)
+	assert.Empty(t, refresh.queue)
+}
+
+func TestRefreshAheadForget(t *testing.T) {
+	// Given
+	now := time.Now()
+	refresh := newTestRefreshAhead(&now)
+
+	refresh.touch("key-1", "key-1", 10*time.Second)
+	refresh.touch("key-2", "key-2", 20*time.Second)
+
+	// When
+	refresh.forget("key-1")
+
+	// Then
+	assert.NotContains(t, refresh.entries, "key-1")
+	assert.Len(t, refresh.queue, 1)
+	assert.Equal(t, "key-2", refresh.queue[0].key)
+
+	// When forgotten while refreshing
+	now = now.Add(16 * time.Second)
+	entry, _ := refresh.next()
+	refresh.forget("key-2")
+	refresh.done(entry, 20*time.Second)
+
+	// Then
+	assert.Empty(t, refresh.entries)
+	assert.Empty(t, refresh.queue)
+}
+
+func TestRefreshAheadReset(t *testing.T) {
+	// Given
+	now := time.Now()
+	refresh := newTestRefreshAhead(&now)
+
+	refresh.touch("key-1", "key-1", 10*time.Second)
+	refresh.touch("key-2", "key-2", 20*time.Second)
+
+	// When
+	refresh.reset()
+
+	// Then
+	assert.Empty(t, refresh.entries)
+	assert.Empty(t, refresh.queue)
+}
@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type refreshJob struct {
+	key  string
+	run  func(ctx context.Context)
+	done func()
+}
+
+// refreshPoolConfig holds the settings of a refresh pool until it is started
+type refreshPoolConfig struct {
+	workers   int
+	queueSize int
+	timeout   time.Duration
+}
+
+// refreshPool runs background refreshes with a bounded number of workers
+// and a bounded queue. A key can only be queued once at a time.
+type refreshPool struct {
+	jobs    chan *refreshJob
+	pending sync.Map
+	timeout time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.RWMutex
+	closed  bool
+	workers sync.WaitGroup
+}
+
+func newRefreshPool(workers int, queueSize int, timeout time.Duration) *refreshPool {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	pool := &refreshPool{
+		jobs:    make(chan *refreshJob, queueSize),
+		timeout: timeout,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+
+	pool.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go pool.worker()
+	}
+
+	return pool
+}
+
+func (p *refreshPool) worker() {
+	defer p.workers.Done()
+
+	for job := range p.jobs {
+		p.runJob(job)
+	}
+}
+
+func (p *refreshPool) runJob(job *refreshJob) {
+	defer func() {
+		p.pending.Delete(job.key)
+		job.done()
+	}()
+
+	// the pool has been closed with cancellation, skip remaining jobs
+	if p.ctx.Err() != nil {
+		return
+	}
+
+	ctx := p.ctx
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	job.run(ctx)
+}
+
+// submit queues a refresh for the given key. It returns false, without calling done,
+// if the key is already queued, if the queue is full or if the pool is closed.
+func (p *refreshPool) submit(key string, run func(ctx context.Context), done func()) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return false
+	}
+
+	if _, queued := p.pending.LoadOrStore(key, true); queued {
+		return false
+	}
+
+	select {
+	case p.jobs <- &refreshJob{key: key, run: run, done: done}:
+		return true
+	default:
+		p.pending.Delete(key)
+		return false
+	}
+}
+
+// close stops accepting refreshes and waits for the workers to finish. Queued and
+// in-flight refreshes are drained, or cancelled when cancel is true or when the
+// context is done.
+func (p *refreshPool) close(ctx context.Context, cancel bool) error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.jobs)
+	p.mu.Unlock()
+
+	defer p.cancel()
+
+	if cancel {
+		p.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("unable to drain background refreshes: %w", ctx.Err())
+	}
+}
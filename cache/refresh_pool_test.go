@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshPoolSubmit(t *testing.T) {
+	// Given
+	pool := newRefreshPool(2, 10, time.Second)
+
+	var runs, dones int32
+
+	// When
+	submitted := pool.submit("my-key", func(ctx context.Context) {
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline)
+		atomic.AddInt32(&runs, 1)
+	}, func() {
+		atomic.AddInt32(&dones, 1)
+	})
+	pool.close(context.Background(), false)
+
+	// Then
+	assert.True(t, submitted)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&dones))
+}
+
+func TestRefreshPoolSubmitWhenAlreadyQueued(t *testing.T) {
+	// Given
+	pool := newRefreshPool(1, 10, 0)
+
+	release := make(chan bool)
+	noop := func() {}
+
+	assert.True(t, pool.submit("my-key", func(_ context.Context) { <-release }, noop))
+
+	// When
+	submitted := pool.submit("my-key", func(_ context.Context) {}, noop)
+
+	// Then
+	assert.False(t, submitted)
+
+	close(release)
+	pool.close(context.Background(), false)
+}
+
+func TestRefreshPoolSubmitWhenQueueIsFull(t *testing.T) {
+	// Given
+	pool := newRefreshPool(1, 1, 0)
+
+	started := make(chan bool)
+	release := make(chan bool)
+	noop := func() {}
+
+	// first job is taken by the worker, second one fills the queue
+	assert.True(t, pool.submit("key-1", func(_ context.Context) {
+		close(started)
+		<-release
+	}, noop))
+	<-started
+	assert.True(t, pool.submit("key-2", func(_ context.Context) {}, noop))
+
+	// When
+	submitted := pool.submit("key-3", func(_ context.Context) {}, noop)
+
+	// Then
+	assert.False(t, submitted)
+
+	close(release)
+	pool.close(context.Background(), false)
+}
+
+func TestRefreshPoolSubmitWhenClosed(t *testing.T) {
+	// Given
+	pool := newRefreshPool(1, 1, 0)
+	pool.close(context.Background(), false)
+
+	// When
+	submitted := pool.submit("my-key", func(_ context.Context) {}, func() {})
+
+	// Then
+	assert.False(t, submitted)
+
+	// closing twice is a no-op
+	pool.close(context.Background(), false)
+}
+
+func TestRefreshPoolCloseWithCancel(t *testing.T) {
+	// Given
+	pool := newRefreshPool(1, 10, 0)
+
+	started := make(chan bool)
+	var cancelled, queuedRuns, dones int32
+
+	pool.submit("key-1", func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		atomic.AddInt32(&cancelled, 1)
+	}, func() { atomic.AddInt32(&dones, 1) })
+	<-started
+	pool.submit("key-2", func(_ context.Context) {
+		atomic.AddInt32(&queuedRuns, 1)
+	}, func() { atomic.AddInt32(&dones, 1) })
+
+	// When
+	pool.close(context.Background(), true)
+
+	// Then
+	assert.Equal(t, int32(1), atomic.LoadInt32(&cancelled))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&queuedRuns))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&dones))
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/vmihailenco/msgpack"
+)
+
+// ErrDecode is returned when a value retrieved from the store cannot be decoded
+// into the cache value type
+var ErrDecode = errors.New("unable to decode cache value")
+
+// Serializer represents a way to encode cache values into bytes before they are
+// set in the store, and to decode them back when they are retrieved
+type Serializer[T any] interface {
+	Marshal(value T) ([]byte, error)
+	Unmarshal(data []byte) (T, error)
+}
+
+// JSONSerializer encodes cache values using encoding/json
+type JSONSerializer[T any] struct{}
+
+// Marshal encodes the given value
+func (JSONSerializer[T]) Marshal(value T) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Unmarshal decodes the given data
+func (JSONSerializer[T]) Unmarshal(data []byte) (T, error) {
+	var value T
+	err := json.Unmarshal(data, &value)
+	return value, err
+}
+
+// MsgpackSerializer encodes cache values using MessagePack
+type MsgpackSerializer[T any] struct{}
+
+// Marshal encodes the given value
+func (MsgpackSerializer[T]) Marshal(value T) ([]byte, error) {
+	return msgpack.Marshal(value)
+}
+
+// Unmarshal decodes the given data
+func (MsgpackSerializer[T]) Unmarshal(data []byte) (T, error) {
+	var value T
+	err := msgpack.Unmarshal(data, &value)
+	return value, err
+}
+
+// GobSerializer encodes cache values using encoding/gob
+type GobSerializer[T any] struct{}
+
+// Marshal encodes the given value
+func (GobSerializer[T]) Marshal(value T) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(value); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Unmarshal decodes the given data
+func (GobSerializer[T]) Unmarshal(data []byte) (T, error) {
+	var value T
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value)
+	return value, err
+}
+
+// RawSerializer stores []byte cache values as they are
+type RawSerializer struct{}
+
+// Marshal returns the given value
+func (RawSerializer) Marshal(value []byte) ([]byte, error) {
+	return value, nil
+}
+
+// Unmarshal returns the given data
+func (RawSerializer) Unmarshal(data []byte) ([]byte, error) {
+	return data, nil
+}
+
+// decodeValue decodes a value retrieved from the store using the given serializer
+func decodeValue[T any](serializer Serializer[T], value any) (T, error) {
+	var data []byte
+
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return *new(T), fmt.Errorf("%w: unexpected %T value in store", ErrDecode, value)
+	}
+
+	object, err := serializer.Unmarshal(data)
+	if err != nil {
+		return *new(T), fmt.Errorf("%w: %v", ErrDecode, err)
+	}
+
+	return object, nil
+}
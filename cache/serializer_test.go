@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type serializerTestBook struct {
+	ID    int
+	Name  string
+	Tags  []string
+	Price float64
+}
+
+func TestSerializersRoundTrip(t *testing.T) {
+	book := serializerTestBook{ID: 1, Name: "My book", Tags: []string{"go", "cache"}, Price: 12.5}
+
+	testCases := map[string]Serializer[serializerTestBook]{
+		"json":    JSONSerializer[serializerTestBook]{},
+		"msgpack": MsgpackSerializer[serializerTestBook]{},
+		"gob":     GobSerializer[serializerTestBook]{},
+	}
+
+	for name, serializer := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// When
+			data, err := serializer.Marshal(book)
+			assert.Nil(t, err)
+
+			value, err := serializer.Unmarshal(data)
+
+			// Then
+			assert.Nil(t, err)
+			assert.Equal(t, book, value)
+		})
+	}
+}
+
+func TestRawSerializer(t *testing.T) {
+	// Given
+	serializer := RawSerializer{}
+
+	// When
+	data, err := serializer.Marshal([]byte("my-value"))
+	assert.Nil(t, err)
+
+	value, err := serializer.Unmarshal(data)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("my-value"), value)
+}
+
+func TestDecodeValueWhenUnexpectedType(t *testing.T) {
+	// When
+	value, err := decodeValue[serializerTestBook](JSONSerializer[serializerTestBook]{}, 12)
+
+	// Then
+	assert.ErrorIs(t, err, ErrDecode)
+	assert.Equal(t, serializerTestBook{}, value)
+}
+
+func TestDecodeValueWhenInvalidData(t *testing.T) {
+	// When
+	_, err := decodeValue[serializerTestBook](GobSerializer[serializerTestBook]{}, "not gob")
+
+	// Then
+	assert.ErrorIs(t, err, ErrDecode)
+}
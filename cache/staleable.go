@@ -2,9 +2,12 @@ package cache
 
 import (
 	"context"
-	"github.com/eko/gocache/v3/store"
+	"errors"
 	"sync"
 	"time"
+
+	"github.com/eko/gocache/v3/lock"
+	"github.com/eko/gocache/v3/store"
 )
 
 const (
@@ -14,6 +17,17 @@ const (
 
 type ShouldCachePredicate[T any] func(key any, value T) bool
 
+// StaleIfErrorCallback is called each time a stale value is served because the load function failed
+type StaleIfErrorCallback func(ctx context.Context, key any, err error)
+
+type staleIfErrorEntry struct {
+	err   error
+	until time.Time
+	// expiresAt is the date after which the stale value cannot be served anymore,
+	// so the entry can be removed
+	expiresAt time.Time
+}
+
 type mapEntry[T any] struct {
 	lockChannel chan bool
 	value       T
@@ -25,10 +39,31 @@ type StaleableCache[T any] struct {
 	cache       SetterCacheInterface[T]
 	minimumTTL  time.Duration
 	refreshTTL  time.Duration
-	loadFunc    LoadFunction[T]
+	loadFunc    LoadFunctionWithOptions[T]
 	shouldCache ShouldCachePredicate[T]
+	loadLock    *loadLock
+	xfetch      *xfetch
+	negative    *negativeCache
+
+	staleIfError         bool
+	staleIfErrorTTL      time.Duration
+	staleIfErrorBackoff  time.Duration
+	staleIfErrorCallback StaleIfErrorCallback
 
+	refreshPoolConfig    *refreshPoolConfig
+	refreshPool          *refreshPool
+	cancelRefreshOnClose bool
+
+	// inprogressMap and errorMap are indexed by the cache key computed by cacheKey,
+	// the way the wrapped cache computes it
+	cacheKey      func(key any) string
 	inprogressMap sync.Map
+	errorMap      sync.Map
+	// errorSweepAt is the date of the next removal of the expired errorMap entries
+	errorSweepMutex sync.Mutex
+	errorSweepAt    time.Time
+
+	lifecycle lifecycle
 }
 
 type StaleableCacheOption[T any] func(cache *StaleableCache[T])
@@ -46,6 +81,14 @@ func WithMaxStaleCacheTTL[T any](ttl time.Duration) StaleableCacheOption[T] {
 }
 
 func WithStaleCacheLoadFunction[T any](f LoadFunction[T]) StaleableCacheOption[T] {
+	return func(cache *StaleableCache[T]) {
+		cache.loadFunc = f.withOptions()
+	}
+}
+
+// WithStaleCacheLoadFunctionWithOptions allows loading values using a function also returning
+// the options to set them with. A returned expiration replaces the refresh TTL.
+func WithStaleCacheLoadFunctionWithOptions[T any](f LoadFunctionWithOptions[T]) StaleableCacheOption[T] {
 	return func(cache *StaleableCache[T]) {
 		cache.loadFunc = f
 	}
@@ -57,20 +100,110 @@ func WithStaleCachePredicate[T any](f ShouldCachePredicate[T]) StaleableCacheOpt
 	}
 }
 
+// WithStaleCacheLocker takes a lock on the key using the given locker before calling
+// the load function, so only one instance refreshes a given key at a time. Instances
+// that do not obtain the lock keep serving the stale value, or wait up to the wait
+// duration for the value to be available when there is nothing to serve.
+func WithStaleCacheLocker[T any](locker lock.LockerInterface, ttl time.Duration, wait time.Duration) StaleableCacheOption[T] {
+	return func(cache *StaleableCache[T]) {
+		cache.loadLock = &loadLock{locker: locker, ttl: ttl, wait: wait}
+	}
+}
+
+// WithStaleIfError serves the stale value when the load function fails instead of returning
+// the error. Values are kept in the underlying cache for an extra grace duration after the
+// max stale TTL so they can still be served during an origin outage, and the load function
+// is not called again for the given key before the backoff duration is elapsed.
+func WithStaleIfError[T any](grace time.Duration, backoff time.Duration) StaleableCacheOption[T] {
+	return func(cache *StaleableCache[T]) {
+		cache.staleIfError = true
+		cache.staleIfErrorTTL = grace
+		cache.staleIfErrorBackoff = backoff
+	}
+}
+
+// WithStaleIfErrorCallback provides a function called each time a stale value is served
+// because the load function failed, for instance to record a metric
+func WithStaleIfErrorCallback[T any](f StaleIfErrorCallback) StaleableCacheOption[T] {
+	return func(cache *StaleableCache[T]) {
+		cache.staleIfErrorCallback = f
+	}
+}
+
+// WithStaleCacheXFetch refreshes values in background before they become stale, with a
+// probability that rises as the refresh TTL approaches, weighted by the time the last load
+// took and the given beta factor (1.0 is a good default, higher values refresh earlier).
+// The underlying cache needs to be a Cache using WithEnvelope, as the load duration and the
+// expiration date are recorded in the envelope of values, so every instance reading them
+// refreshes them early.
+func WithStaleCacheXFetch[T any](beta float64) StaleableCacheOption[T] {
+	return func(cache *StaleableCache[T]) {
+		cache.xfetch = newXFetch(beta)
+	}
+}
+
+// WithStaleCacheRefreshPool runs background refreshes in a pool of the given number of workers
+// instead of starting a new goroutine for each stale key. At most queueSize refreshes can be
+// waiting for a worker, other ones are dropped and the stale value keeps being served until
+// a next call. Each refresh is cancelled after the given timeout, if it is greater than zero.
+func WithStaleCacheRefreshPool[T any](workers int, queueSize int, timeout time.Duration) StaleableCacheOption[T] {
+	return func(cache *StaleableCache[T]) {
+		cache.refreshPoolConfig = &refreshPoolConfig{workers: workers, queueSize: queueSize, timeout: timeout}
+	}
+}
+
+// WithStaleCacheCancelRefreshOnClose cancels queued and in-flight background refreshes
+// on Close instead of waiting for them to finish
+func WithStaleCacheCancelRefreshOnClose[T any]() StaleableCacheOption[T] {
+	return func(cache *StaleableCache[T]) {
+		cache.cancelRefreshOnClose = true
+	}
+}
+
+// WithStaleCacheNegativeCache caches load function misses and errors so the load function is
+// not called again for a key that is not in cache during the given durations. When the load
+// function returns ErrNotExist, next calls return ErrNotExist for notExistTTL. When it returns
+// another error and errorTTL is greater than zero, next calls return a CachedLoadError for errorTTL.
+// When a load error wraps one of the given sentinel errors, the CachedLoadError wraps it too.
+func WithStaleCacheNegativeCache[T any](notExistTTL time.Duration, errorTTL time.Duration, errs ...error) StaleableCacheOption[T] {
+	return func(cache *StaleableCache[T]) {
+		cache.negative = &negativeCache{notExistTTL: notExistTTL, errorTTL: errorTTL, errors: errs}
+	}
+}
+
 // NewStaleable creates a new wrapper cache StaleableCache instance
 func NewStaleable[T any](underlyingCache SetterCacheInterface[T], opts ...StaleableCacheOption[T]) *StaleableCache[T] {
 	staleableCache := &StaleableCache[T]{
-		cache: underlyingCache,
+		cache:    underlyingCache,
+		cacheKey: cacheKeyFunc(underlyingCache),
 	}
 	for _, opt := range opts {
 		opt(staleableCache)
 	}
+
+	if staleableCache.loadLock != nil {
+		staleableCache.loadLock.useKeysOf(underlyingCache)
+	}
+	if staleableCache.negative != nil {
+		staleableCache.negative.useKeysOf(underlyingCache)
+	}
+
+	// The workers are only started once all the options are applied, so options
+	// that are never used or given twice do not leak goroutines
+	if config := staleableCache.refreshPoolConfig; config != nil {
+		staleableCache.refreshPool = newRefreshPool(config.workers, config.queueSize, config.timeout)
+	}
+
 	return staleableCache
 }
 
 // Get returns data stored from a given key. If it's ttl is negative, refreshes the value in background
 func (s *StaleableCache[T]) Get(ctx context.Context, key any) (T, error) {
-	stringKey := getCacheKey(key)
+	if s.lifecycle.isClosed() {
+		return *new(T), ErrClosed
+	}
+
+	stringKey := s.cacheKey(key)
 	entry, inProgress := s.inprogressMap.LoadOrStore(stringKey, &mapEntry[T]{lockChannel: make(chan bool)})
 	mEntry := entry.(*mapEntry[T])
 	if inProgress {
@@ -78,25 +211,28 @@ func (s *StaleableCache[T]) Get(ctx context.Context, key any) (T, error) {
 		return mEntry.value, mEntry.err
 	}
 
-	object, ttl, err := s.GetWithTTL(ctx, key)
+	object, ttl, loadDuration, err := s.getWithLoadDuration(ctx, key)
 	mEntry.value = object
 	mEntry.err = err
 	if err != nil {
-		if _, ok := err.(*store.NotFound); ok {
-			// record does not exist, need to load it synchronously
-			mEntry.value, mEntry.err = s.loadAndStore(ctx, key)
+		if notFound := new(store.NotFound); errors.As(err, &notFound) {
+			if negativeErr := s.negativeError(ctx, key); negativeErr != nil {
+				// the load function already told us this value does not exist or failed recently
+				mEntry.err = negativeErr
+			} else {
+				// record does not exist, need to load it synchronously
+				mEntry.value, mEntry.err = s.lockedLoadAndStore(ctx, key, true)
+			}
 		}
 		s.inprogressMap.Delete(stringKey)
 	} else if ttl+s.minimumTTL < 0 {
-		// record was expired but not removed automatically from the store
-		mEntry.value, mEntry.err = s.loadAndStore(ctx, key)
+		// record was expired but not removed automatically from the store,
+		// or is kept for the stale if error grace duration
+		mEntry.value, mEntry.err = s.loadOrServeStale(ctx, key, object)
 		s.inprogressMap.Delete(stringKey)
-	} else if ttl < 0 {
-		// record is staled, need to refresh in background
-		go func() {
-			_, _ = s.loadAndStore(context.Background(), key)
-			s.inprogressMap.Delete(stringKey)
-		}()
+	} else if ttl < 0 || (s.xfetch != nil && s.xfetch.shouldRefresh(loadDuration, ttl)) {
+		// record is staled or about to be, need to refresh in background
+		s.refreshInBackground(key, stringKey)
 	} else {
 		s.inprogressMap.Delete(stringKey)
 	}
@@ -106,6 +242,174 @@ func (s *StaleableCache[T]) Get(ctx context.Context, key any) (T, error) {
 	return mEntry.value, mEntry.err
 }
 
+// refreshInBackground loads and stores the value of the given key in background,
+// using the refresh pool if one is configured
+func (s *StaleableCache[T]) refreshInBackground(key any, stringKey string) {
+	refresh := func(ctx context.Context) {
+		if s.backoffError(stringKey) == nil {
+			_, err := s.lockedLoadAndStore(ctx, key, false)
+			s.recordLoadError(stringKey, err)
+		}
+	}
+	done := func() {
+		s.inprogressMap.Delete(stringKey)
+	}
+
+	if s.refreshPool == nil {
+		started := s.lifecycle.goroutine(func() {
+			refresh(context.Background())
+			done()
+		})
+		if !started {
+			done()
+		}
+		return
+	}
+
+	if !s.refreshPool.submit(stringKey, refresh, done) {
+		done()
+	}
+}
+
+// negativeError returns the cached load function miss or error of the given key, if any
+func (s *StaleableCache[T]) negativeError(ctx context.Context, key any) error {
+	if s.negative == nil || s.loadFunc == nil {
+		return nil
+	}
+
+	return s.negative.get(ctx, s.cache.GetCodec().GetStore(), key)
+}
+
+// loadOrServeStale loads the value synchronously. If stale if error is enabled and the load
+// function fails or is in backoff, the given stale value is returned instead of the error.
+func (s *StaleableCache[T]) loadOrServeStale(ctx context.Context, key any, stale T) (T, error) {
+	if !s.staleIfError {
+		return s.lockedLoadAndStore(ctx, key, true)
+	}
+
+	stringKey := s.cacheKey(key)
+
+	err := s.backoffError(stringKey)
+	if err == nil {
+		var value T
+		value, err = s.lockedLoadAndStore(ctx, key, true)
+		s.recordLoadError(stringKey, err)
+		if err == nil {
+			return value, nil
+		}
+	}
+
+	if s.staleIfErrorCallback != nil {
+		s.staleIfErrorCallback(ctx, key, err)
+	}
+
+	return stale, nil
+}
+
+// backoffError returns the last load error of the given key if the load function
+// should not be called again yet
+func (s *StaleableCache[T]) backoffError(stringKey string) error {
+	if !s.staleIfError {
+		return nil
+	}
+
+	entry, ok := s.errorMap.Load(stringKey)
+	if !ok {
+		return nil
+	}
+
+	now := time.Now()
+	e := entry.(*staleIfErrorEntry)
+	if now.Before(e.until) {
+		return e.err
+	}
+
+	if !now.Before(e.expiresAt) {
+		s.errorMap.Delete(stringKey)
+	}
+
+	return nil
+}
+
+// recordLoadError starts the backoff of the given key when the load function failed,
+// or resets it when the load succeeded
+func (s *StaleableCache[T]) recordLoadError(stringKey string, err error) {
+	if !s.staleIfError || errors.Is(err, lock.ErrNotObtained) {
+		return
+	}
+
+	if err == nil {
+		s.errorMap.Delete(stringKey)
+		return
+	}
+
+	now := time.Now()
+	s.errorMap.Store(stringKey, &staleIfErrorEntry{
+		err:       err,
+		until:     now.Add(s.staleIfErrorBackoff),
+		expiresAt: now.Add(s.staleIfErrorBackoff + s.staleIfErrorTTL),
+	})
+
+	s.sweepLoadErrors(now)
+}
+
+// sweepLoadErrors removes the load errors of the keys whose value cannot be served stale
+// anymore, so keys failing once and never read again do not stay in memory. Errors are
+// swept at most once per grace plus backoff duration.
+func (s *StaleableCache[T]) sweepLoadErrors(now time.Time) {
+	s.errorSweepMutex.Lock()
+	if now.Before(s.errorSweepAt) {
+		s.errorSweepMutex.Unlock()
+		return
+	}
+	s.errorSweepAt = now.Add(s.staleIfErrorBackoff + s.staleIfErrorTTL)
+	s.errorSweepMutex.Unlock()
+
+	s.errorMap.Range(func(key, entry any) bool {
+		if !now.Before(entry.(*staleIfErrorEntry).expiresAt) {
+			s.errorMap.Delete(key)
+		}
+		return true
+	})
+}
+
+// lockedLoadAndStore calls loadAndStore while holding the distributed lock on the key,
+// if a locker is configured.
+//
+// If the lock is held by someone else and wait is true, it waits for the lock owner
+// to store the value, otherwise it leaves the refresh to the lock owner.
+func (s *StaleableCache[T]) lockedLoadAndStore(ctx context.Context, key any, wait bool) (T, error) {
+	if s.loadLock == nil || s.loadFunc == nil {
+		return s.loadAndStore(ctx, key)
+	}
+
+	token, err := s.loadLock.obtain(ctx, key)
+	if errors.Is(err, lock.ErrNotObtained) {
+		if !wait {
+			return *new(T), err
+		}
+
+		if value, ok := waitFor(ctx, s.loadLock.wait, func(ctx context.Context) (T, error) {
+			value, ttl, err := s.getWithTTL(ctx, key)
+			if err == nil && ttl+s.minimumTTL < 0 {
+				err = &store.NotFound{}
+			}
+			return value, err
+		}); ok {
+			return value, nil
+		}
+	} else if err == nil {
+		defer s.loadLock.release(ctx, key, token)
+
+		// The value may have been refreshed while we were trying to obtain the lock
+		if value, ttl, err := s.getWithTTL(ctx, key); err == nil && ttl >= 0 {
+			return value, nil
+		}
+	}
+
+	return s.loadAndStore(ctx, key)
+}
+
 // loadAndStore calls loadFunc and stores result in cache
 //
 // If there is no loadFunc, don't do anything
@@ -118,32 +422,76 @@ func (s *StaleableCache[T]) loadAndStore(ctx context.Context, key any) (T, error
 		return *new(T), nil
 	}
 
-	res, err := s.loadFunc(ctx, key)
+	start := time.Now()
+
+	res, options, err := callLoadFunction(ctx, key, nil, s.loadFunc)
 	if err != nil {
+		if s.negative != nil {
+			s.negative.set(ctx, s.cache.GetCodec().GetStore(), key, err)
+		}
 		return res, err
 	}
+	loadDuration := time.Since(start)
 	if s.shouldCache != nil && !s.shouldCache(key, res) {
 		return res, err
 	}
-	_ = s.Set(ctx, key, res)
+	_ = s.set(ctx, key, res, loadDuration, options...)
 
 	return res, err
 }
 
 // GetWithTTL returns data stored from a given key and its corresponding TTL.
-// when negative TTL is returned, this means that the original cache TTL expired and should be refreshed
+// when negative TTL is returned, this means that the original cache TTL expired and should be refreshed.
+// When the TTL is lower than the negative max stale TTL, the value is only kept to be served if the
+// load function fails.
 func (s *StaleableCache[T]) GetWithTTL(ctx context.Context, key any) (T, time.Duration, error) {
-	value, storeCacheDuration, err := s.cache.GetWithTTL(ctx, key)
+	if s.lifecycle.isClosed() {
+		return *new(T), 0, ErrClosed
+	}
+
+	return s.getWithTTL(ctx, key)
+}
+
+func (s *StaleableCache[T]) getWithTTL(ctx context.Context, key any) (T, time.Duration, error) {
+	value, ttl, _, err := s.getWithLoadDuration(ctx, key)
+	return value, ttl, err
+}
+
+// getWithLoadDuration returns the value like getWithTTL, and the time its last load took
+// when XFetch is enabled and it has been recorded alongside the value
+func (s *StaleableCache[T]) getWithLoadDuration(ctx context.Context, key any) (T, time.Duration, time.Duration, error) {
+	var (
+		value              T
+		storeCacheDuration time.Duration
+		loadDuration       time.Duration
+		err                error
+	)
+
+	if xfetchCache, ok := s.cache.(xfetchCacheInterface[T]); ok && s.xfetch != nil {
+		value, storeCacheDuration, loadDuration, err = xfetchCache.getWithLoadDuration(ctx, key)
+	} else {
+		value, storeCacheDuration, err = s.cache.GetWithTTL(ctx, key)
+	}
 	if err != nil {
-		return *new(T), 0, err
+		return *new(T), 0, 0, err
 	}
 
-	storeCacheDuration = storeCacheDuration - s.minimumTTL
-	return value, storeCacheDuration, nil
+	storeCacheDuration = storeCacheDuration - s.minimumTTL - s.staleIfErrorTTL
+	return value, storeCacheDuration, loadDuration, nil
 }
 
 // Set data in the underlying cache with expiry
 func (s *StaleableCache[T]) Set(ctx context.Context, key any, value T, options ...store.Option) error {
+	if s.lifecycle.isClosed() {
+		return ErrClosed
+	}
+
+	return s.set(ctx, key, value, 0, options...)
+}
+
+// set sets the value in the underlying cache, with the time its load took when it is
+// greater than zero and XFetch is enabled
+func (s *StaleableCache[T]) set(ctx context.Context, key any, value T, loadDuration time.Duration, options ...store.Option) error {
 	opts := store.ApplyOptions(options...)
 	var expiration time.Duration
 	if opts != nil {
@@ -153,17 +501,39 @@ func (s *StaleableCache[T]) Set(ctx context.Context, key any, value T, options .
 		expiration = s.refreshTTL
 	}
 
-	options = append(options, store.WithExpiration(s.minimumTTL+expiration))
+	// Only the refresh TTL is randomised: the jitter must not be applied again by the store,
+	// and the default jitter of the store must not be applied to the whole expiration
+	options = append(options,
+		store.WithExpiration(s.minimumTTL+s.staleIfErrorTTL+opts.JitteredExpiration(expiration)),
+		store.WithExpirationJitter(0),
+	)
+
+	if xfetchCache, ok := s.cache.(xfetchCacheInterface[T]); ok && s.xfetch != nil && loadDuration > 0 {
+		return xfetchCache.setWithLoadDuration(ctx, key, value, loadDuration, options...)
+	}
+
 	return s.cache.Set(ctx, key, value, options...)
 }
 
 // Delete removes data in underlying cache for given key identifier
 func (s *StaleableCache[T]) Delete(ctx context.Context, key any) error {
+	if s.lifecycle.isClosed() {
+		return ErrClosed
+	}
+
+	s.errorMap.Delete(s.cacheKey(key))
+	if s.negative != nil {
+		s.negative.delete(ctx, s.cache.GetCodec().GetStore(), key)
+	}
 	return s.cache.Delete(ctx, key)
 }
 
 // Invalidate invalidates some cache data in underlying cache for given options
 func (s *StaleableCache[T]) Invalidate(ctx context.Context, options ...store.InvalidateOption) error {
+	if s.lifecycle.isClosed() {
+		return ErrClosed
+	}
+
 	return s.cache.Invalidate(ctx, options...)
 }
 
@@ -174,5 +544,36 @@ func (s *StaleableCache[T]) GetType() string {
 
 // Clear resets all data in the store
 func (s *StaleableCache[T]) Clear(ctx context.Context) error {
+	if s.lifecycle.isClosed() {
+		return ErrClosed
+	}
+
+	s.errorMap.Range(func(key, _ any) bool {
+		s.errorMap.Delete(key)
+		return true
+	})
+
 	return s.cache.Clear(ctx)
 }
+
+// Close stops the background refreshes and closes the underlying cache. Queued and in-flight
+// refreshes are waited for, unless WithStaleCacheCancelRefreshOnClose is used.
+// Next operations return ErrClosed.
+func (s *StaleableCache[T]) Close() error {
+	return closeWithTimeout(s.CloseWithContext)
+}
+
+// CloseWithContext stops the background refreshes and closes the underlying cache. Refreshes
+// still running when the context is done are cancelled. Next operations return ErrClosed.
+func (s *StaleableCache[T]) CloseWithContext(ctx context.Context) error {
+	closed, err := s.lifecycle.close(ctx, nil)
+	if !closed {
+		return nil
+	}
+
+	if s.refreshPool != nil {
+		err = firstError(err, s.refreshPool.close(ctx, s.cancelRefreshOnClose))
+	}
+
+	return firstError(err, closeWrapped(ctx, s.cache))
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"testing"
+	"time"
+
+	"github.com/eko/gocache/v3/lock"
 	"github.com/eko/gocache/v3/store"
 	mocksCache "github.com/eko/gocache/v3/test/mocks/cache"
 	"github.com/golang/mock/gomock"
+	gocache "github.com/patrickmn/go-cache"
 	"github.com/stretchr/testify/assert"
-	"testing"
-	"time"
 )
 
 type TTLOptionMatcher struct {
@@ -365,6 +368,479 @@ func TestStaleCacheGetWithParallelRequestsWithShouldNotCachePredicate(t *testing
 	time.Sleep(2 * time.Second)
 }
 
+func TestStaleCacheGetWithLockerWhenLockedByAnotherInstance(t *testing.T) {
+	// Given
+	ic := getMockCache[any](t)
+	cacheKey := "my-key"
+	cacheValue := "my-cache-value"
+
+	ctx := context.Background()
+	gomock.InOrder(
+		ic.EXPECT().GetWithTTL(gomock.Any(), cacheKey).Return(nil, 0*time.Second, &store.NotFound{}),
+		ic.EXPECT().GetWithTTL(gomock.Any(), cacheKey).Return(cacheValue, 5*time.Second, nil),
+	)
+
+	locker := lock.NewMemory()
+	_, err := locker.Obtain(ctx, "gocache_lock_my-key", time.Minute)
+	assert.Nil(t, err)
+
+	// When
+	s := NewStaleable[any](ic,
+		WithMaxStaleCacheTTL[any](3*time.Second),
+		WithStaleCacheLocker[any](locker, time.Minute, time.Second),
+		WithStaleCacheLoadFunction[any](func(_ context.Context, key any) (any, error) {
+			return nil, errors.New("should not be called")
+		}))
+	value, err := s.Get(ctx, cacheKey)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, cacheValue, value)
+}
+
+func TestStaleCacheGetWithLockerWhenStaleAndLockedByAnotherInstance(t *testing.T) {
+	// Given
+	ic := getMockCache[any](t)
+	cacheKey := "my-key"
+	cacheValue := "my-cache-value"
+
+	ctx := context.Background()
+	ic.EXPECT().GetWithTTL(ctx, cacheKey).Return(cacheValue, 2*time.Second, nil)
+
+	locker := lock.NewMemory()
+	_, err := locker.Obtain(ctx, "gocache_lock_my-key", time.Minute)
+	assert.Nil(t, err)
+
+	// When
+	s := NewStaleable[any](ic,
+		WithTTL[any](time.Second),
+		WithMaxStaleCacheTTL[any](time.Minute),
+		WithStaleCacheLocker[any](locker, time.Minute, time.Second),
+		WithStaleCacheLoadFunction[any](func(_ context.Context, key any) (any, error) {
+			return nil, errors.New("should not be called")
+		}))
+	value, err := s.Get(ctx, cacheKey)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, cacheValue, value)
+
+	// wait for the background refresh to be skipped
+	time.Sleep(10 * time.Millisecond)
+}
+
+func TestStaleCacheGetWithLockerWhenStale(t *testing.T) {
+	// Given
+	ic := getMockCache[any](t)
+	cacheKey := "my-key"
+	cacheValue := "my-cache-value"
+	updatedCacheValue := "updated-cache-value"
+	ttlValue := time.Second
+	staleTTLValue := time.Minute
+
+	ctx := context.Background()
+	ic.EXPECT().GetWithTTL(gomock.Any(), cacheKey).Times(2).Return(cacheValue, 2*time.Second, nil)
+	ic.EXPECT().Set(gomock.Any(), cacheKey, updatedCacheValue, &TTLOptionMatcher{TTL: ttlValue + staleTTLValue}).Return(nil)
+
+	locker := lock.NewMemory()
+
+	// When
+	s := NewStaleable[any](ic,
+		WithTTL[any](ttlValue),
+		WithMaxStaleCacheTTL[any](staleTTLValue),
+		WithStaleCacheLocker[any](locker, time.Minute, time.Second),
+		WithStaleCacheLoadFunction[any](func(_ context.Context, key any) (any, error) {
+			return updatedCacheValue, nil
+		}))
+	value, err := s.Get(ctx, cacheKey)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, cacheValue, value)
+
+	// wait for the background refresh to finish
+	time.Sleep(10 * time.Millisecond)
+
+	_, err = locker.Obtain(ctx, "gocache_lock_my-key", time.Minute)
+	assert.Nil(t, err)
+}
+
+func TestStaleCacheGetWithStaleIfErrorWhenLoaderFails(t *testing.T) {
+	// Given
+	ic := getMockCache[any](t)
+	ctx := context.Background()
+
+	cacheKey := "my-key"
+	cacheValue := "my-cache-value"
+	loadErr := errors.New("origin is down")
+	staleTTLValue := time.Minute
+	graceValue := time.Hour
+
+	// expired since 10 seconds after the max stale TTL but still within the grace duration
+	ic.EXPECT().GetWithTTL(ctx, cacheKey).Times(2).Return(cacheValue, graceValue-10*time.Second, nil)
+
+	loadCalls := 0
+	var callbackErrs []error
+
+	s := NewStaleable[any](ic,
+		WithMaxStaleCacheTTL[any](staleTTLValue),
+		WithStaleIfError[any](graceValue, time.Minute),
+		WithStaleIfErrorCallback[any](func(_ context.Context, key any, err error) {
+			callbackErrs = append(callbackErrs, err)
+		}),
+		WithStaleCacheLoadFunction[any](func(_ context.Context, key any) (any, error) {
+			loadCalls++
+			return nil, loadErr
+		}),
+	)
+
+	// When
+	value, err := s.Get(ctx, cacheKey)
+	secondValue, secondErr := s.Get(ctx, cacheKey)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, cacheValue, value)
+	assert.Nil(t, secondErr)
+	assert.Equal(t, cacheValue, secondValue)
+
+	// second call is in backoff so the load function is only called once
+	assert.Equal(t, 1, loadCalls)
+	assert.Equal(t, []error{loadErr, loadErr}, callbackErrs)
+}
+
+func TestStaleCacheGetWithStaleIfErrorWhenBackoffIsElapsed(t *testing.T) {
+	// Given
+	ic := getMockCache[any](t)
+	ctx := context.Background()
+
+	cacheKey := "my-key"
+	cacheValue := "my-cache-value"
+	updatedCacheValue := "updated-cache-value"
+	graceValue := time.Hour
+
+	ic.EXPECT().GetWithTTL(ctx, cacheKey).Times(2).Return(cacheValue, graceValue-10*time.Second, nil)
+	ic.EXPECT().Set(ctx, cacheKey, updatedCacheValue, gomock.Any()).Return(nil)
+
+	loadCalls := 0
+
+	s := NewStaleable[any](ic,
+		WithMaxStaleCacheTTL[any](time.Minute),
+		WithStaleIfError[any](graceValue, time.Millisecond),
+		WithStaleCacheLoadFunction[any](func(_ context.Context, key any) (any, error) {
+			loadCalls++
+			if loadCalls == 1 {
+				return nil, errors.New("origin is down")
+			}
+			return updatedCacheValue, nil
+		}),
+	)
+
+	// When
+	value, err := s.Get(ctx, cacheKey)
+	time.Sleep(2 * time.Millisecond)
+	secondValue, secondErr := s.Get(ctx, cacheKey)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, cacheValue, value)
+	assert.Nil(t, secondErr)
+	assert.Equal(t, updatedCacheValue, secondValue)
+	assert.Equal(t, 2, loadCalls)
+}
+
+func TestStaleCacheGetWithStaleIfErrorClearsErrorOnSuccessfulRefresh(t *testing.T) {
+	// Given
+	ic := getMockCache[any](t)
+	ctx := context.Background()
+
+	cacheKey := "my-key"
+	graceValue := time.Hour
+
+	ic.EXPECT().GetWithTTL(ctx, cacheKey).Times(2).Return("my-cache-value", graceValue-10*time.Second, nil)
+	ic.EXPECT().Set(ctx, cacheKey, "updated-cache-value", gomock.Any()).Return(nil)
+
+	loadCalls := 0
+
+	s := NewStaleable[any](ic,
+		WithMaxStaleCacheTTL[any](time.Minute),
+		WithStaleIfError[any](graceValue, time.Millisecond),
+		WithStaleCacheLoadFunction[any](func(_ context.Context, key any) (any, error) {
+			loadCalls++
+			if loadCalls == 1 {
+				return nil, errors.New("origin is down")
+			}
+			return "updated-cache-value", nil
+		}),
+	)
+
+	_, _ = s.Get(ctx, cacheKey)
+	_, hasError := s.errorMap.Load(cacheKey)
+	assert.True(t, hasError)
+
+	time.Sleep(2 * time.Millisecond)
+
+	// When
+	_, err := s.Get(ctx, cacheKey)
+
+	// Then
+	assert.Nil(t, err)
+
+	_, hasError = s.errorMap.Load(cacheKey)
+	assert.False(t, hasError)
+}
+
+func TestStaleCacheRecordLoadErrorRemovesExpiredErrors(t *testing.T) {
+	// Given
+	s := NewStaleable[any](getMockCache[any](t),
+		WithStaleIfError[any](time.Millisecond, time.Millisecond),
+	)
+
+	s.recordLoadError("failed-key", errors.New("origin is down"))
+
+	time.Sleep(3 * time.Millisecond)
+
+	// When
+	s.recordLoadError("my-key", errors.New("origin is down"))
+
+	// Then
+	_, hasError := s.errorMap.Load("failed-key")
+	assert.False(t, hasError)
+
+	_, hasError = s.errorMap.Load("my-key")
+	assert.True(t, hasError)
+}
+
+func TestStaleCacheGetWithStaleIfErrorWhenBackgroundRefreshFails(t *testing.T) {
+	// Given
+	ic := getMockCache[any](t)
+	ctx := context.Background()
+
+	cacheKey := "my-key"
+	cacheValue := "my-cache-value"
+	staleTTLValue := time.Minute
+	graceValue := time.Hour
+
+	// within the max stale TTL
+	ic.EXPECT().GetWithTTL(ctx, cacheKey).Times(2).Return(cacheValue, graceValue+staleTTLValue-time.Second, nil)
+
+	loadCalls := 0
+
+	s := NewStaleable[any](ic,
+		WithTTL[any](time.Second),
+		WithMaxStaleCacheTTL[any](staleTTLValue),
+		WithStaleIfError[any](graceValue, time.Minute),
+		WithStaleCacheLoadFunction[any](func(_ context.Context, key any) (any, error) {
+			loadCalls++
+			return nil, errors.New("origin is down")
+		}),
+	)
+
+	// When
+	value, err := s.Get(ctx, cacheKey)
+	time.Sleep(10 * time.Millisecond)
+	secondValue, secondErr := s.Get(ctx, cacheKey)
+	time.Sleep(10 * time.Millisecond)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, cacheValue, value)
+	assert.Nil(t, secondErr)
+	assert.Equal(t, cacheValue, secondValue)
+	assert.Equal(t, 1, loadCalls)
+}
+
+func TestStaleCacheSetWithStaleIfError(t *testing.T) {
+	// Given
+	ic := getMockCache[any](t)
+	ctx := context.Background()
+
+	cacheKey := "my-key"
+	cacheValue := "my-cache-value"
+	ic.EXPECT().Set(ctx, cacheKey, cacheValue, &TTLOptionMatcher{TTL: time.Hour + 5*time.Second + time.Second}).Return(nil)
+
+	// When
+	s := NewStaleable[any](ic,
+		WithTTL[any](time.Second),
+		WithMaxStaleCacheTTL[any](5*time.Second),
+		WithStaleIfError[any](time.Hour, time.Minute),
+	)
+	err := s.Set(ctx, cacheKey, cacheValue)
+
+	// Then
+	assert.Nil(t, err)
+}
+
+func TestStaleCacheGetWithXFetch(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	cacheKey := "my-key"
+	staleTTLValue := time.Minute
+
+	shared := New[string](store.NewGoCache(gocache.New(gocache.NoExpiration, time.Minute)), WithEnvelope[string](1))
+
+	// The value has been loaded by another instance, it is not stale yet but only 10ms left before it is
+	err := shared.setWithLoadDuration(ctx, cacheKey, "my-cache-value", time.Second, store.WithExpiration(staleTTLValue+10*time.Millisecond))
+	assert.Nil(t, err)
+
+	refreshed := make(chan bool)
+
+	s := NewStaleable[string](shared,
+		WithTTL[string](time.Second),
+		WithMaxStaleCacheTTL[string](staleTTLValue),
+		WithStaleCacheXFetch[string](1.0),
+		WithStaleCacheLoadFunction[string](func(_ context.Context, key any) (string, error) {
+			defer close(refreshed)
+			return "updated-cache-value", nil
+		}),
+	)
+	s.xfetch.random = func() float64 { return 0.5 }
+
+	// When
+	value, err := s.Get(ctx, cacheKey)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "my-cache-value", value)
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("value should have been refreshed in background")
+	}
+
+	assert.Eventually(t, func() bool {
+		value, _, loadDuration, err := shared.getWithLoadDuration(ctx, cacheKey)
+		return err == nil && value == "updated-cache-value" && loadDuration > 0
+	}, time.Second, time.Millisecond)
+}
+
+func TestStaleCacheGetWithRefreshPool(t *testing.T) {
+	// Given
+	ic := getMockCache[any](t)
+	ctx := context.Background()
+
+	cacheKey := "my-key"
+	cacheValue := "my-cache-value"
+	updatedCacheValue := "updated-cache-value"
+
+	ic.EXPECT().GetWithTTL(ctx, cacheKey).Return(cacheValue, 2*time.Second, nil)
+	ic.EXPECT().Set(gomock.Any(), cacheKey, updatedCacheValue, gomock.Any()).Return(nil)
+	ic.EXPECT().Close().Return(nil)
+
+	s := NewStaleable[any](ic,
+		WithTTL[any](time.Second),
+		WithMaxStaleCacheTTL[any](time.Minute),
+		WithStaleCacheRefreshPool[any](1, 10, time.Second),
+		WithStaleCacheLoadFunction[any](func(ctx context.Context, key any) (any, error) {
+			_, hasDeadline := ctx.Deadline()
+			assert.True(t, hasDeadline)
+			return updatedCacheValue, nil
+		}),
+	)
+
+	// When
+	value, err := s.Get(ctx, cacheKey)
+	closeErr := s.Close()
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, cacheValue, value)
+	assert.Nil(t, closeErr)
+
+	_, inProgress := s.inprogressMap.Load(cacheKey)
+	assert.False(t, inProgress)
+}
+
+func TestWithStaleCacheRefreshPoolDoesNotStartWorkers(t *testing.T) {
+	// Given
+	s := &StaleableCache[any]{}
+
+	// When
+	WithStaleCacheRefreshPool[any](4, 10, time.Second)(s)
+
+	// Then
+	assert.Nil(t, s.refreshPool)
+	assert.Equal(t, &refreshPoolConfig{workers: 4, queueSize: 10, timeout: time.Second}, s.refreshPoolConfig)
+}
+
+func TestNewStaleableWithRefreshPoolGivenTwice(t *testing.T) {
+	// Given
+	ic := getMockCache[any](t)
+	ic.EXPECT().Close().Return(nil)
+
+	// When
+	s := NewStaleable[any](ic,
+		WithStaleCacheRefreshPool[any](1, 10, time.Second),
+		WithStaleCacheRefreshPool[any](2, 20, time.Second),
+	)
+
+	// Then
+	assert.NotNil(t, s.refreshPool)
+	assert.Equal(t, 20, cap(s.refreshPool.jobs))
+	assert.Nil(t, s.Close())
+}
+
+func TestStaleCacheGetWithRefreshPoolWhenClosed(t *testing.T) {
+	// Given
+	ic := getMockCache[any](t)
+	ctx := context.Background()
+
+	cacheKey := "my-key"
+
+	ic.EXPECT().Close().Return(nil)
+
+	s := NewStaleable[any](ic,
+		WithTTL[any](time.Second),
+		WithMaxStaleCacheTTL[any](time.Minute),
+		WithStaleCacheRefreshPool[any](1, 10, time.Second),
+		WithStaleCacheLoadFunction[any](func(_ context.Context, key any) (any, error) {
+			return nil, errors.New("should not be called")
+		}),
+	)
+	assert.Nil(t, s.Close())
+
+	// When
+	value, err := s.Get(ctx, cacheKey)
+
+	// Then
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.Nil(t, value)
+
+	_, inProgress := s.inprogressMap.Load(cacheKey)
+	assert.False(t, inProgress)
+
+	assert.Nil(t, s.Close())
+}
+
+func TestStaleCacheGetWithNegativeCache(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	gocacheStore := store.NewGoCache(gocache.New(gocache.NoExpiration, time.Minute))
+
+	loadCalls := 0
+
+	s := NewStaleable[string](New[string](gocacheStore),
+		WithMaxStaleCacheTTL[string](time.Minute),
+		WithStaleCacheNegativeCache[string](time.Minute, 0),
+		WithStaleCacheLoadFunction[string](func(_ context.Context, key any) (string, error) {
+			loadCalls++
+			return "", ErrNotExist
+		}),
+	)
+
+	// When
+	_, err := s.Get(ctx, "my-key")
+	_, secondErr := s.Get(ctx, "my-key")
+
+	// Then
+	assert.Equal(t, ErrNotExist, err)
+	assert.Equal(t, ErrNotExist, secondErr)
+	assert.Equal(t, 1, loadCalls)
+}
+
 func TestStaleCacheSet(t *testing.T) {
 	// Given
 	ic := getMockCache[any](t)
@@ -374,7 +850,7 @@ func TestStaleCacheSet(t *testing.T) {
 	cacheKey := "my-key"
 	cacheValue := "my-cache-value"
 	var options []store.Option
-	call := ic.EXPECT().Set(ctx, cacheKey, cacheValue, gomock.Any(), gomock.Any()).Return(nil)
+	call := ic.EXPECT().Set(ctx, cacheKey, cacheValue, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 	call.Do(func(ctx, cacheKey, cacheValue any, opts ...store.Option) {
 		options = opts
 	})
@@ -385,11 +861,57 @@ func TestStaleCacheSet(t *testing.T) {
 
 	// Then
 	assert.Nil(t, err)
-	assert.Equal(t, 2, len(options))
+	assert.Equal(t, 3, len(options))
 	opts := store.ApplyOptions(options...)
 	assert.Equal(t, opts.Expiration(), 6*time.Second)
 }
 
+func TestStaleCacheSetWithExpirationJitter(t *testing.T) {
+	// Given
+	ic := getMockCache[any](t)
+	ctx := context.Background()
+
+	cacheKey := "my-key"
+	cacheValue := "my-cache-value"
+	var options []store.Option
+	ic.EXPECT().Set(ctx, cacheKey, cacheValue, gomock.Any()).Return(nil).Do(func(_, _, _ any, opts ...store.Option) {
+		options = opts
+	})
+
+	// When
+	s := NewStaleable[any](ic, WithTTL[any](10*time.Second), WithMaxStaleCacheTTL[any](time.Minute))
+	err := s.Set(ctx, cacheKey, cacheValue, store.WithExpirationJitterFraction(0.5))
+
+	// Then
+	assert.Nil(t, err)
+
+	// the jitter is only applied to the refresh TTL and disabled for the store
+	opts := store.ApplyOptions(options...)
+	assert.GreaterOrEqual(t, opts.Expiration(), time.Minute+10*time.Second)
+	assert.Less(t, opts.Expiration(), time.Minute+15*time.Second)
+	assert.Equal(t, time.Duration(0), opts.ExpirationJitter())
+	assert.Equal(t, float64(0), opts.ExpirationJitterFraction())
+}
+
+func TestStaleCacheSetWhenStoreHasDefaultExpirationJitter(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	underlying := New[any](store.NewGoCache(gocache.New(gocache.NoExpiration, time.Minute), store.WithExpirationJitterFraction(0.5)))
+
+	// When
+	s := NewStaleable[any](underlying, WithTTL[any](10*time.Second), WithMaxStaleCacheTTL[any](time.Minute))
+	err := s.Set(ctx, "my-key", "my-cache-value")
+
+	// Then
+	assert.Nil(t, err)
+
+	_, ttl, err := underlying.GetWithTTL(ctx, "my-key")
+	assert.Nil(t, err)
+	assert.LessOrEqual(t, ttl, time.Minute+10*time.Second)
+	assert.Greater(t, ttl, time.Minute+9*time.Second)
+}
+
 func TestStaleCacheDelete(t *testing.T) {
 	// Given
 	ic := getMockCache[any](t)
@@ -467,3 +989,35 @@ func TestStaleCacheGetType(t *testing.T) {
 	s := NewStaleable[any](ic, WithMaxStaleCacheTTL[any](time.Second))
 	assert.Equal(t, StaleableType, s.GetType())
 }
+
+func TestStaleCacheGetWithLoadFunctionWithOptions(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	wrapped := New[string](store.NewGoCache(gocache.New(gocache.NoExpiration, time.Minute)))
+
+	s := NewStaleable[string](wrapped,
+		WithTTL[string](time.Second),
+		WithMaxStaleCacheTTL[string](5*time.Second),
+		WithStaleCacheLoadFunctionWithOptions[string](func(_ context.Context, key any) (string, []store.Option, error) {
+			return "my-value", []store.Option{store.WithExpiration(time.Hour), store.WithTags([]string{"my-tag"})}, nil
+		}),
+	)
+	defer s.Close()
+
+	// When
+	value, err := s.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "my-value", value)
+
+	_, ttl, err := wrapped.GetWithTTL(ctx, "my-key")
+	assert.Nil(t, err)
+	assert.InDelta(t, time.Hour+5*time.Second, ttl, float64(time.Second))
+
+	assert.Nil(t, s.Invalidate(ctx, store.WithInvalidateTags([]string{"my-tag"})))
+
+	_, err = wrapped.Get(ctx, "my-key")
+	assert.True(t, errors.Is(err, store.NotFound{}))
+}
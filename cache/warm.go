@@ -0,0 +1,338 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultWarmConcurrency = 8
+
+// ErrWarmIncomplete is returned by WarmReadiness.Wait when the warm-up ended without
+// passing the readiness percentage
+var ErrWarmIncomplete = errors.New("cache warm-up ended below the readiness percentage")
+
+// KeyIterator returns the next key to warm, or false when there are no more keys
+type KeyIterator func(ctx context.Context) (key any, ok bool, err error)
+
+// SliceKeyIterator returns an iterator over the given keys
+func SliceKeyIterator(keys ...any) KeyIterator {
+	var index int
+
+	return func(_ context.Context) (any, bool, error) {
+		if index >= len(keys) {
+			return nil, false, nil
+		}
+
+		index++
+		return keys[index-1], true, nil
+	}
+}
+
+// ChannelKeyIterator returns an iterator over the keys received from the given channel,
+// until it is closed
+func ChannelKeyIterator(keys <-chan any) KeyIterator {
+	return func(ctx context.Context) (any, bool, error) {
+		select {
+		case key, ok := <-keys:
+			return key, ok, nil
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+}
+
+// WarmOptions represents the options of a cache warm-up
+type WarmOptions struct {
+	// Concurrency is the maximum number of values loaded at the same time. Default is 8.
+	Concurrency int
+	// RateLimit is the maximum number of values loaded per second. Zero means no limit.
+	RateLimit float64
+	// Timeout is the maximum duration of the warm-up. Zero means no limit other than the context one.
+	Timeout time.Duration
+	// Total is the expected number of keys, used to compute the progress percentage
+	Total int
+	// OnProgress is called each time a key has been warmed, loaded or not
+	OnProgress func(progress WarmProgress)
+	// Readiness is marked as ready once the warm-up passes its percentage
+	Readiness *WarmReadiness
+}
+
+// WarmProgress represents the progress of a cache warm-up
+type WarmProgress struct {
+	// Total is the expected number of keys, or zero if unknown
+	Total int
+	// Loaded is the number of values loaded or already present in cache
+	Loaded int
+	// Failed is the number of values the load function failed to load
+	Failed int
+}
+
+// Percent returns the percentage of the expected keys that have been loaded,
+// or zero if the expected number of keys is unknown
+func (p WarmProgress) Percent() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+
+	return 100 * float64(p.Loaded) / float64(p.Total)
+}
+
+// WarmFailure represents a key the load function failed to load during a warm-up
+type WarmFailure struct {
+	Key any
+	Err error
+}
+
+// WarmResult represents the result of a cache warm-up
+type WarmResult struct {
+	WarmProgress
+	Failures []WarmFailure
+	Duration time.Duration
+}
+
+// Err returns an error describing the failures of the warm-up, if any
+func (r *WarmResult) Err() error {
+	if len(r.Failures) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("unable to warm %d of %d keys, first error on key %v: %w",
+		len(r.Failures), r.Loaded+r.Failed, r.Failures[0].Key, r.Failures[0].Err)
+}
+
+// Warm loads the keys given by the iterator using the load function of the loadable cache,
+// and sets them in the wrapped cache. Keys already present in cache are not loaded again.
+// Load failures are reported in the result, while an error is returned when the warm-up
+// is aborted because the context is done, the timeout expired or the iterator failed.
+func Warm[T any](ctx context.Context, loadable *LoadableCache[T], keys KeyIterator, options WarmOptions) (*WarmResult, error) {
+	if options.Concurrency <= 0 {
+		options.Concurrency = defaultWarmConcurrency
+	}
+
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+
+	warmer := &warmer[T]{
+		loadable: loadable,
+		options:  options,
+		result:   &WarmResult{WarmProgress: WarmProgress{Total: options.Total}},
+	}
+
+	start := time.Now()
+	err := warmer.run(ctx, keys)
+	warmer.result.Duration = time.Since(start)
+
+	if options.Readiness != nil {
+		options.Readiness.finish(warmer.result.WarmProgress)
+	}
+
+	return warmer.result, err
+}
+
+type warmer[T any] struct {
+	loadable *LoadableCache[T]
+	options  WarmOptions
+
+	mu     sync.Mutex
+	result *WarmResult
+}
+
+// run dispatches the keys to the workers, at the rate limit if any
+func (w *warmer[T]) run(ctx context.Context, keys KeyIterator) error {
+	queue := make(chan any)
+
+	var workers sync.WaitGroup
+	for i := 0; i < w.options.Concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for key := range queue {
+				w.warm(ctx, key)
+			}
+		}()
+	}
+
+	var ticks <-chan time.Time
+	if w.options.RateLimit > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / w.options.RateLimit))
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+
+	err := w.dispatch(ctx, keys, queue, ticks)
+
+	close(queue)
+	workers.Wait()
+
+	return err
+}
+
+func (w *warmer[T]) dispatch(ctx context.Context, keys KeyIterator, queue chan<- any, ticks <-chan time.Time) error {
+	for first := true; ; first = false {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		key, ok, err := keys(ctx)
+		if err != nil {
+			return err
+		} else if !ok {
+			return nil
+		}
+
+		// The first key is loaded right away, next ones wait for the rate limiter
+		if ticks != nil && !first {
+			select {
+			case <-ticks:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		select {
+		case queue <- key:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// warm loads the given key unless it is already present in cache
+func (w *warmer[T]) warm(ctx context.Context, key any) {
+	err := w.load(ctx, key)
+	if err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		// The warm-up has been aborted, the key has not been warmed
+		return
+	}
+
+	w.mu.Lock()
+	if err != nil {
+		w.result.Failed++
+		w.result.Failures = append(w.result.Failures, WarmFailure{Key: key, Err: err})
+	} else {
+		w.result.Loaded++
+	}
+	progress := w.result.WarmProgress
+	w.mu.Unlock()
+
+	if w.options.Readiness != nil {
+		w.options.Readiness.update(progress)
+	}
+
+	if w.options.OnProgress != nil {
+		w.options.OnProgress(progress)
+	}
+}
+
+func (w *warmer[T]) load(ctx context.Context, key any) error {
+	if _, err := w.loadable.cache.Get(ctx, key); err == nil {
+		return nil
+	}
+
+	item, err := w.loadable.load(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	if w.loadable.lifecycle.isClosed() {
+		return ErrClosed
+	}
+
+	return w.loadable.setLoaded(ctx, item)
+}
+
+// WarmReadiness tells whether a cache warm-up passed a given percentage of the expected
+// keys. It can be used as the handler of a readiness probe. When the warm-up ends below
+// the percentage, the readiness stays not ready and Wait returns ErrWarmIncomplete:
+// call MarkReady to serve traffic anyway.
+type WarmReadiness struct {
+	percent float64
+	ready   uint32
+	done    chan struct{}
+	once    sync.Once
+
+	mu  sync.Mutex
+	err error
+}
+
+// NewWarmReadiness creates a readiness becoming ready once the warm-up loaded the given
+// percentage of the expected keys. When the number of expected keys is unknown, it
+// becomes ready when the warm-up ends having loaded the given percentage of the keys.
+func NewWarmReadiness(percent float64) *WarmReadiness {
+	return &WarmReadiness{
+		percent: percent,
+		done:    make(chan struct{}),
+	}
+}
+
+// Ready returns true once the warm-up passed the percentage
+func (r *WarmReadiness) Ready() bool {
+	return atomic.LoadUint32(&r.ready) == 1
+}
+
+// Wait blocks until the warm-up passed the percentage or ended, or the context is done.
+// An error wrapping ErrWarmIncomplete is returned when the warm-up ended below the percentage.
+func (r *WarmReadiness) Wait(ctx context.Context) error {
+	select {
+	case <-r.done:
+		if r.Ready() {
+			return nil
+		}
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		return r.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// MarkReady marks the readiness as ready, whatever the progress of the warm-up.
+// It can be used to serve traffic once a warm-up ended below the percentage.
+func (r *WarmReadiness) MarkReady() {
+	atomic.StoreUint32(&r.ready, 1)
+	r.once.Do(func() { close(r.done) })
+}
+
+// ServeHTTP responds with a 200 status code once the warm-up passed the percentage or
+// MarkReady has been called, and with a 503 status code otherwise
+func (r *WarmReadiness) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	if !r.Ready() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (r *WarmReadiness) update(progress WarmProgress) {
+	if progress.Total > 0 && progress.Percent() >= r.percent {
+		r.MarkReady()
+	}
+}
+
+func (r *WarmReadiness) finish(progress WarmProgress) {
+	if progress.Total <= 0 {
+		progress.Total = progress.Loaded + progress.Failed
+	}
+
+	if progress.Total == 0 || progress.Percent() >= r.percent {
+		r.MarkReady()
+		return
+	}
+
+	r.mu.Lock()
+	r.err = fmt.Errorf("%w: %.1f%% of the keys loaded, %.1f%% expected", ErrWarmIncomplete, progress.Percent(), r.percent)
+	r.mu.Unlock()
+
+	r.once.Do(func() { close(r.done) })
+}
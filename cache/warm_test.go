@@ -0,0 +1,276 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eko/gocache/v3/store"
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func newWarmTestCache(t *testing.T, loadFunc LoadFunction[string]) (*LoadableCache[string], *Cache[string]) {
+	wrapped := New[string](store.NewGoCache(gocache.New(gocache.NoExpiration, time.Minute)))
+	loadable := NewLoadable[string](loadFunc, wrapped)
+	t.Cleanup(func() { _ = loadable.Close() })
+
+	return loadable, wrapped
+}
+
+func TestWarm(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	var calls int32
+	loadable, wrapped := newWarmTestCache(t, func(_ context.Context, key any) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		if key == "failing-key" {
+			return "", errors.New("unable to load")
+		}
+		return fmt.Sprintf("value of %v", key), nil
+	})
+
+	assert.Nil(t, wrapped.Set(ctx, "cached-key", "cached value"))
+
+	var mu sync.Mutex
+	var progresses []WarmProgress
+
+	// When
+	result, err := Warm(ctx, loadable, SliceKeyIterator("key-1", "key-2", "cached-key", "failing-key"), WarmOptions{
+		Concurrency: 2,
+		Total:       4,
+		OnProgress: func(progress WarmProgress) {
+			mu.Lock()
+			defer mu.Unlock()
+			progresses = append(progresses, progress)
+		},
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, WarmProgress{Total: 4, Loaded: 3, Failed: 1}, result.WarmProgress)
+	assert.Equal(t, float64(75), result.Percent())
+	assert.Len(t, result.Failures, 1)
+	assert.Equal(t, "failing-key", result.Failures[0].Key)
+	assert.EqualError(t, result.Err(), "unable to warm 1 of 4 keys, first error on key failing-key: unable to load")
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	assert.Len(t, progresses, 4)
+
+	value, err := wrapped.Get(ctx, "key-2")
+	assert.Nil(t, err)
+	assert.Equal(t, "value of key-2", value)
+
+	value, err = wrapped.Get(ctx, "cached-key")
+	assert.Nil(t, err)
+	assert.Equal(t, "cached value", value)
+}
+
+func TestWarmWithConcurrency(t *testing.T) {
+	// Given
+	var running, maxRunning int32
+	loadable, _ := newWarmTestCache(t, func(_ context.Context, key any) (string, error) {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		return "my-value", nil
+	})
+
+	keys := make([]any, 20)
+	for i := range keys {
+		keys[i] = i
+	}
+
+	// When
+	result, err := Warm(context.Background(), loadable, SliceKeyIterator(keys...), WarmOptions{Concurrency: 3})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, 20, result.Loaded)
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxRunning), int32(3))
+}
+
+func TestWarmWithRateLimit(t *testing.T) {
+	// Given
+	loadable, _ := newWarmTestCache(t, func(_ context.Context, key any) (string, error) {
+		return "my-value", nil
+	})
+
+	// When
+	result, err := Warm(context.Background(), loadable, SliceKeyIterator(1, 2, 3, 4, 5), WarmOptions{RateLimit: 100})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, 5, result.Loaded)
+	assert.GreaterOrEqual(t, result.Duration, 40*time.Millisecond)
+}
+
+func TestWarmWhenTimeoutExpires(t *testing.T) {
+	// Given
+	loadable, _ := newWarmTestCache(t, func(ctx context.Context, key any) (string, error) {
+		if key == "slow-key" {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return "my-value", nil
+	})
+
+	keys := make(chan any)
+	go func() {
+		keys <- "my-key"
+		keys <- "slow-key"
+	}()
+
+	// When
+	result, err := Warm(context.Background(), loadable, ChannelKeyIterator(keys), WarmOptions{
+		Concurrency: 1,
+		Timeout:     50 * time.Millisecond,
+	})
+
+	// Then
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, 1, result.Loaded)
+	assert.Equal(t, 0, result.Failed)
+	assert.Nil(t, result.Err())
+}
+
+func TestWarmWhenIteratorFails(t *testing.T) {
+	// Given
+	loadable, _ := newWarmTestCache(t, func(_ context.Context, key any) (string, error) {
+		return "my-value", nil
+	})
+
+	iteratorErr := errors.New("unable to list keys")
+	keys := func(_ context.Context) (any, bool, error) {
+		return nil, false, iteratorErr
+	}
+
+	// When
+	result, err := Warm(context.Background(), loadable, keys, WarmOptions{})
+
+	// Then
+	assert.Equal(t, iteratorErr, err)
+	assert.Equal(t, 0, result.Loaded)
+}
+
+func TestWarmReadiness(t *testing.T) {
+	// Given
+	release := make(chan struct{})
+	loadable, _ := newWarmTestCache(t, func(_ context.Context, key any) (string, error) {
+		if key == "slow-key" {
+			<-release
+		}
+		return "my-value", nil
+	})
+
+	readiness := NewWarmReadiness(50)
+
+	probe := func() int {
+		recorder := httptest.NewRecorder()
+		readiness.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		return recorder.Code
+	}
+	assert.Equal(t, http.StatusServiceUnavailable, probe())
+
+	done := make(chan struct{})
+
+	// When
+	go func() {
+		defer close(done)
+		_, _ = Warm(context.Background(), loadable, SliceKeyIterator("key-1", "slow-key"), WarmOptions{
+			Concurrency: 1,
+			Total:       2,
+			Readiness:   readiness,
+		})
+	}()
+
+	// Then
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	assert.Nil(t, readiness.Wait(ctx))
+	assert.True(t, readiness.Ready())
+	assert.Equal(t, http.StatusOK, probe())
+
+	close(release)
+	<-done
+}
+
+func TestWarmReadinessWhenTotalIsUnknown(t *testing.T) {
+	// Given
+	loadable, _ := newWarmTestCache(t, func(_ context.Context, key any) (string, error) {
+		if key == "failing-key" {
+			return "", errors.New("unable to load")
+		}
+		return "my-value", nil
+	})
+
+	readiness := NewWarmReadiness(90)
+
+	// When
+	_, err := Warm(context.Background(), loadable, SliceKeyIterator("my-key", "failing-key"), WarmOptions{
+		Readiness: readiness,
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.False(t, readiness.Ready())
+	assert.True(t, errors.Is(readiness.Wait(context.Background()), ErrWarmIncomplete))
+}
+
+func TestWarmReadinessWhenWarmUpEndsBelowPercentage(t *testing.T) {
+	// Given
+	loadable, _ := newWarmTestCache(t, func(_ context.Context, key any) (string, error) {
+		if key == "failing-key" {
+			return "", errors.New("unable to load")
+		}
+		return "my-value", nil
+	})
+
+	readiness := NewWarmReadiness(90)
+
+	probe := func() int {
+		recorder := httptest.NewRecorder()
+		readiness.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+		return recorder.Code
+	}
+
+	// When
+	_, err := Warm(context.Background(), loadable, SliceKeyIterator("my-key", "failing-key"), WarmOptions{
+		Total:     2,
+		Readiness: readiness,
+	})
+
+	// Then
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	waitErr := readiness.Wait(ctx)
+	assert.True(t, errors.Is(waitErr, ErrWarmIncomplete))
+	assert.Equal(t, "cache warm-up ended below the readiness percentage: 50.0% of the keys loaded, 90.0% expected", waitErr.Error())
+	assert.False(t, readiness.Ready())
+	assert.Equal(t, http.StatusServiceUnavailable, probe())
+
+	readiness.MarkReady()
+
+	assert.Nil(t, readiness.Wait(ctx))
+	assert.True(t, readiness.Ready())
+	assert.Equal(t, http.StatusOK, probe())
+}
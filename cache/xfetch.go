@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/eko/gocache/v3/store"
+)
+
+// xfetchCacheInterface is implemented by caches able to record the time the load of a value
+// took alongside the value, so every instance reading the value can refresh it early
+type xfetchCacheInterface[T any] interface {
+	setWithLoadDuration(ctx context.Context, key any, object T, loadDuration time.Duration, options ...store.Option) error
+	getWithLoadDuration(ctx context.Context, key any) (T, time.Duration, time.Duration, error)
+}
+
+// xfetch implements the probabilistic early expiration algorithm described in
+// "Optimal Probabilistic Cache Stampede Prevention" (Vattani, Chierichetti, Lowenstein).
+//
+// A value is refreshed early when: -delta * beta * ln(rand()) >= ttl
+// where delta is the time the last load of the value took. The higher the delta or
+// beta, the earlier the value gets refreshed. Load durations are recorded in the
+// envelope of the cached values, see xfetchCacheInterface.
+type xfetch struct {
+	beta   float64
+	random func() float64
+}
+
+func newXFetch(beta float64) *xfetch {
+	return &xfetch{
+		beta:   beta,
+		random: rand.Float64,
+	}
+}
+
+// shouldRefresh returns true if a value whose load took loadDuration, expiring in ttl,
+// needs to be refreshed now
+func (x *xfetch) shouldRefresh(loadDuration time.Duration, ttl time.Duration) bool {
+	if ttl <= 0 || loadDuration <= 0 {
+		return false
+	}
+
+	return -float64(loadDuration)*x.beta*math.Log(x.random()) >= float64(ttl)
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXFetchShouldRefresh(t *testing.T) {
+	testCases := []struct {
+		name     string
+		delta    time.Duration
+		ttl      time.Duration
+		random   float64
+		expected bool
+	}{
+		{
+			name:     "far from expiry",
+			delta:    100 * time.Millisecond,
+			ttl:      time.Minute,
+			random:   0.5,
+			expected: false,
+		},
+		{
+			name:     "close to expiry",
+			delta:    100 * time.Millisecond,
+			ttl:      50 * time.Millisecond,
+			random:   0.5,
+			expected: true,
+		},
+		{
+			name:     "close to expiry with a lucky draw",
+			delta:    100 * time.Millisecond,
+			ttl:      50 * time.Millisecond,
+			random:   0.9,
+			expected: false,
+		},
+		{
+			name:     "already expired",
+			delta:    100 * time.Millisecond,
+			ttl:      0,
+			random:   0.5,
+			expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given
+			x := newXFetch(1.0)
+			x.random = func() float64 { return tc.random }
+
+			// When - Then
+			assert.Equal(t, tc.expected, x.shouldRefresh(tc.delta, tc.ttl))
+		})
+	}
+}
+
+func TestXFetchShouldRefreshWhenLoadDurationIsUnknown(t *testing.T) {
+	// Given
+	x := newXFetch(1.0)
+	x.random = func() float64 { return 0 }
+
+	// When - Then
+	assert.False(t, x.shouldRefresh(0, time.Millisecond))
+}
@@ -0,0 +1,14 @@
+package lock
+
+import (
+	"context"
+	"time"
+)
+
+// LockerInterface represents the interface for all available lockers
+// (memory, redis, memcache, ...)
+type LockerInterface interface {
+	Obtain(ctx context.Context, key string, ttl time.Duration) (string, error)
+	Release(ctx context.Context, key string, token string) error
+	GetType() string
+}
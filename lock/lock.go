@@ -0,0 +1,27 @@
+package lock
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+)
+
+var (
+	// ErrNotObtained is returned when the lock is already held by someone else
+	ErrNotObtained = errors.New("lock not obtained")
+
+	// ErrNotHeld is returned when releasing a lock that expired or that is
+	// now held by someone else
+	ErrNotHeld = errors.New("lock not held")
+)
+
+// newToken returns a random token used to identify the lock owner, so that
+// only the owner is able to release it
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,92 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/eko/gocache/v3/store"
+)
+
+// MemcacheClientInterface represents a bradfitz/gomemcache client
+type MemcacheClientInterface interface {
+	Get(key string) (item *memcache.Item, err error)
+	Add(item *memcache.Item) error
+	CompareAndSwap(item *memcache.Item) error
+}
+
+const (
+	// MemcacheType represents the locker type as a string value
+	MemcacheType = "memcache"
+)
+
+// MemcacheLocker is a locker relying on Memcache Add
+type MemcacheLocker struct {
+	client MemcacheClientInterface
+}
+
+// NewMemcache creates a new locker using Memcache instance(s)
+func NewMemcache(client MemcacheClientInterface) *MemcacheLocker {
+	return &MemcacheLocker{
+		client: client,
+	}
+}
+
+// Obtain takes the lock for the given key and returns its owner token.
+// Memcache expirations have a one second granularity so ttl is rounded up.
+func (l *MemcacheLocker) Obtain(_ context.Context, key string, ttl time.Duration) (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = l.client.Add(&memcache.Item{
+		Key:        key,
+		Value:      []byte(token),
+		Expiration: int32((ttl + time.Second - 1) / time.Second),
+	})
+	if errors.Is(err, memcache.ErrNotStored) {
+		return "", ErrNotObtained
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// Release releases the lock for the given key if it is still held by token.
+// The item is expired through CompareAndSwap so a lock taken by someone else
+// in the meantime is never removed.
+func (l *MemcacheLocker) Release(_ context.Context, key string, token string) error {
+	item, err := l.client.Get(key)
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		return ErrNotHeld
+	}
+	if err != nil {
+		return err
+	}
+	if string(item.Value) != token {
+		return ErrNotHeld
+	}
+
+	item.Expiration = -1
+
+	err = l.client.CompareAndSwap(item)
+	if errors.Is(err, memcache.ErrCASConflict) || errors.Is(err, memcache.ErrNotStored) {
+		return ErrNotHeld
+	}
+
+	return err
+}
+
+// GetType returns the locker type
+func (l *MemcacheLocker) GetType() string {
+	return MemcacheType
+}
+
+// GetKeyConstraints returns the constraints Memcache keys have to respect
+func (l *MemcacheLocker) GetKeyConstraints() store.KeyConstraints {
+	return store.KeyConstraints{MaxLength: store.MemcacheMaxKeyLength, DisallowWhitespace: true}
+}
@@ -0,0 +1,139 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	mocksLock "github.com/eko/gocache/v3/test/mocks/lock/clients"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMemcache(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	client := mocksLock.NewMockMemcacheClientInterface(ctrl)
+
+	// When
+	locker := NewMemcache(client)
+
+	// Then
+	assert.IsType(t, new(MemcacheLocker), locker)
+	assert.Equal(t, client, locker.client)
+}
+
+func TestMemcacheObtain(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	client := mocksLock.NewMockMemcacheClientInterface(ctrl)
+	client.EXPECT().Add(gomock.Any()).DoAndReturn(func(item *memcache.Item) error {
+		assert.Equal(t, "my-key", item.Key)
+		assert.Equal(t, int32(2), item.Expiration)
+		return nil
+	})
+
+	locker := NewMemcache(client)
+
+	// When
+	token, err := locker.Obtain(ctx, "my-key", 1500*time.Millisecond)
+
+	// Then
+	assert.Nil(t, err)
+	assert.NotEmpty(t, token)
+}
+
+func TestMemcacheObtainWhenAlreadyLocked(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	client := mocksLock.NewMockMemcacheClientInterface(ctrl)
+	client.EXPECT().Add(gomock.Any()).Return(memcache.ErrNotStored)
+
+	locker := NewMemcache(client)
+
+	// When
+	token, err := locker.Obtain(ctx, "my-key", time.Second)
+
+	// Then
+	assert.Equal(t, ErrNotObtained, err)
+	assert.Empty(t, token)
+}
+
+func TestMemcacheRelease(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	item := &memcache.Item{Key: "my-key", Value: []byte("my-token")}
+
+	client := mocksLock.NewMockMemcacheClientInterface(ctrl)
+	client.EXPECT().Get("my-key").Return(item, nil)
+	client.EXPECT().CompareAndSwap(item).Return(nil)
+
+	locker := NewMemcache(client)
+
+	// When
+	err := locker.Release(ctx, "my-key", "my-token")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, int32(-1), item.Expiration)
+}
+
+func TestMemcacheReleaseWhenNotOwner(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	client := mocksLock.NewMockMemcacheClientInterface(ctrl)
+	client.EXPECT().Get("my-key").Return(&memcache.Item{Key: "my-key", Value: []byte("another-token")}, nil)
+
+	locker := NewMemcache(client)
+
+	// When
+	err := locker.Release(ctx, "my-key", "my-token")
+
+	// Then
+	assert.Equal(t, ErrNotHeld, err)
+}
+
+func TestMemcacheReleaseWhenTakenInBetween(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	client := mocksLock.NewMockMemcacheClientInterface(ctrl)
+	client.EXPECT().Get("my-key").Return(&memcache.Item{Key: "my-key", Value: []byte("my-token")}, nil)
+	client.EXPECT().CompareAndSwap(gomock.Any()).Return(memcache.ErrCASConflict)
+
+	locker := NewMemcache(client)
+
+	// When
+	err := locker.Release(ctx, "my-key", "my-token")
+
+	// Then
+	assert.Equal(t, ErrNotHeld, err)
+}
+
+func TestMemcacheGetType(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	client := mocksLock.NewMockMemcacheClientInterface(ctrl)
+
+	locker := NewMemcache(client)
+
+	// When - Then
+	assert.Equal(t, MemcacheType, locker.GetType())
+}
@@ -0,0 +1,99 @@
+package lock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// MemoryType represents the locker type as a string value
+	MemoryType = "memory"
+
+	// memorySweepInterval is the minimum duration between two purges of the expired locks
+	memorySweepInterval = time.Minute
+)
+
+type memoryLock struct {
+	token     string
+	expiresAt time.Time
+}
+
+// MemoryLocker is an in-process locker, useful for tests and single instance
+// deployments
+type MemoryLocker struct {
+	mu        sync.Mutex
+	locks     map[string]memoryLock
+	lastSweep time.Time
+}
+
+// NewMemory creates a new in-process locker
+func NewMemory() *MemoryLocker {
+	return &MemoryLocker{
+		locks: make(map[string]memoryLock),
+	}
+}
+
+// Obtain takes the lock for the given key and returns its owner token
+func (l *MemoryLocker) Obtain(_ context.Context, key string, ttl time.Duration) (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	if current, exists := l.locks[key]; exists && now.Before(current.expiresAt) {
+		return "", ErrNotObtained
+	}
+
+	l.locks[key] = memoryLock{
+		token:     token,
+		expiresAt: now.Add(ttl),
+	}
+
+	return token, nil
+}
+
+// Release releases the lock for the given key if it is still held by token
+func (l *MemoryLocker) Release(_ context.Context, key string, token string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	current, exists := l.locks[key]
+	if exists && !time.Now().Before(current.expiresAt) {
+		delete(l.locks, key)
+		return ErrNotHeld
+	}
+	if !exists || current.token != token {
+		return ErrNotHeld
+	}
+
+	delete(l.locks, key)
+
+	return nil
+}
+
+// GetType returns the locker type
+func (l *MemoryLocker) GetType() string {
+	return MemoryType
+}
+
+// sweep removes the expired locks, so locks of keys that are never locked again
+// do not stay in memory. It must be called with the mutex held.
+func (l *MemoryLocker) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < memorySweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, current := range l.locks {
+		if !now.Before(current.expiresAt) {
+			delete(l.locks, key)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMemory(t *testing.T) {
+	// When
+	locker := NewMemory()
+
+	// Then
+	assert.IsType(t, new(MemoryLocker), locker)
+	assert.Empty(t, locker.locks)
+}
+
+func TestMemoryObtain(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	locker := NewMemory()
+
+	// When
+	token, err := locker.Obtain(ctx, "my-key", time.Minute)
+
+	// Then
+	assert.Nil(t, err)
+	assert.NotEmpty(t, token)
+}
+
+func TestMemoryObtainWhenAlreadyLocked(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	locker := NewMemory()
+
+	_, err := locker.Obtain(ctx, "my-key", time.Minute)
+	assert.Nil(t, err)
+
+	// When
+	token, err := locker.Obtain(ctx, "my-key", time.Minute)
+
+	// Then
+	assert.Equal(t, ErrNotObtained, err)
+	assert.Empty(t, token)
+}
+
+func TestMemoryObtainWhenExpired(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	locker := NewMemory()
+
+	firstToken, err := locker.Obtain(ctx, "my-key", time.Millisecond)
+	assert.Nil(t, err)
+
+	time.Sleep(2 * time.Millisecond)
+
+	// When
+	token, err := locker.Obtain(ctx, "my-key", time.Minute)
+
+	// Then
+	assert.Nil(t, err)
+	assert.NotEqual(t, firstToken, token)
+}
+
+func TestMemoryRelease(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	locker := NewMemory()
+
+	token, err := locker.Obtain(ctx, "my-key", time.Minute)
+	assert.Nil(t, err)
+
+	// When
+	err = locker.Release(ctx, "my-key", token)
+
+	// Then
+	assert.Nil(t, err)
+
+	_, err = locker.Obtain(ctx, "my-key", time.Minute)
+	assert.Nil(t, err)
+}
+
+func TestMemoryReleaseWhenNotOwner(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	locker := NewMemory()
+
+	_, err := locker.Obtain(ctx, "my-key", time.Minute)
+	assert.Nil(t, err)
+
+	// When
+	err = locker.Release(ctx, "my-key", "another-token")
+
+	// Then
+	assert.Equal(t, ErrNotHeld, err)
+
+	_, err = locker.Obtain(ctx, "my-key", time.Minute)
+	assert.Equal(t, ErrNotObtained, err)
+}
+
+func TestMemoryGetType(t *testing.T) {
+	// When - Then
+	assert.Equal(t, MemoryType, NewMemory().GetType())
+}
+
+func TestMemoryObtainPurgesExpiredLocks(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	locker := NewMemory()
+
+	_, err := locker.Obtain(ctx, "expired-key", time.Millisecond)
+	assert.Nil(t, err)
+
+	time.Sleep(2 * time.Millisecond)
+	locker.lastSweep = time.Now().Add(-memorySweepInterval)
+
+	// When
+	_, err = locker.Obtain(ctx, "my-key", time.Minute)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Len(t, locker.locks, 1)
+	assert.Contains(t, locker.locks, "my-key")
+}
+
+func TestMemoryReleaseWhenExpired(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	locker := NewMemory()
+
+	token, err := locker.Obtain(ctx, "my-key", time.Millisecond)
+	assert.Nil(t, err)
+
+	time.Sleep(2 * time.Millisecond)
+
+	// When
+	err = locker.Release(ctx, "my-key", token)
+
+	// Then
+	assert.Equal(t, ErrNotHeld, err)
+	assert.Empty(t, locker.locks)
+}
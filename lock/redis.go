@@ -0,0 +1,70 @@
+package lock
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisClientInterface represents a go-redis/redis client
+type RedisClientInterface interface {
+	SetNX(ctx context.Context, key string, value any, expiration time.Duration) *redis.BoolCmd
+	Eval(ctx context.Context, script string, keys []string, args ...any) *redis.Cmd
+}
+
+const (
+	// RedisType represents the locker type as a string value
+	RedisType = "redis"
+
+	// redisReleaseScript deletes the lock key only if it still holds the owner token
+	redisReleaseScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+)
+
+// RedisLocker is a locker relying on Redis SET NX PX
+type RedisLocker struct {
+	client RedisClientInterface
+}
+
+// NewRedis creates a new locker using Redis instance(s)
+func NewRedis(client RedisClientInterface) *RedisLocker {
+	return &RedisLocker{
+		client: client,
+	}
+}
+
+// Obtain takes the lock for the given key and returns its owner token
+func (l *RedisLocker) Obtain(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+
+	obtained, err := l.client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		return "", err
+	}
+	if !obtained {
+		return "", ErrNotObtained
+	}
+
+	return token, nil
+}
+
+// Release releases the lock for the given key if it is still held by token
+func (l *RedisLocker) Release(ctx context.Context, key string, token string) error {
+	deleted, err := l.client.Eval(ctx, redisReleaseScript, []string{key}, token).Int64()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrNotHeld
+	}
+
+	return nil
+}
+
+// GetType returns the locker type
+func (l *RedisLocker) GetType() string {
+	return RedisType
+}
@@ -0,0 +1,134 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mocksLock "github.com/eko/gocache/v3/test/mocks/lock/clients"
+	"github.com/go-redis/redis/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRedis(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	client := mocksLock.NewMockRedisClientInterface(ctrl)
+
+	// When
+	locker := NewRedis(client)
+
+	// Then
+	assert.IsType(t, new(RedisLocker), locker)
+	assert.Equal(t, client, locker.client)
+}
+
+func TestRedisObtain(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	client := mocksLock.NewMockRedisClientInterface(ctrl)
+	client.EXPECT().SetNX(ctx, "my-key", gomock.Any(), 5*time.Second).Return(redis.NewBoolResult(true, nil))
+
+	locker := NewRedis(client)
+
+	// When
+	token, err := locker.Obtain(ctx, "my-key", 5*time.Second)
+
+	// Then
+	assert.Nil(t, err)
+	assert.NotEmpty(t, token)
+}
+
+func TestRedisObtainWhenAlreadyLocked(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	client := mocksLock.NewMockRedisClientInterface(ctrl)
+	client.EXPECT().SetNX(ctx, "my-key", gomock.Any(), 5*time.Second).Return(redis.NewBoolResult(false, nil))
+
+	locker := NewRedis(client)
+
+	// When
+	token, err := locker.Obtain(ctx, "my-key", 5*time.Second)
+
+	// Then
+	assert.Equal(t, ErrNotObtained, err)
+	assert.Empty(t, token)
+}
+
+func TestRedisObtainWhenError(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	expectedErr := errors.New("unable to reach redis")
+
+	client := mocksLock.NewMockRedisClientInterface(ctrl)
+	client.EXPECT().SetNX(ctx, "my-key", gomock.Any(), 5*time.Second).Return(redis.NewBoolResult(false, expectedErr))
+
+	locker := NewRedis(client)
+
+	// When
+	token, err := locker.Obtain(ctx, "my-key", 5*time.Second)
+
+	// Then
+	assert.Equal(t, expectedErr, err)
+	assert.Empty(t, token)
+}
+
+func TestRedisRelease(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	client := mocksLock.NewMockRedisClientInterface(ctrl)
+	client.EXPECT().Eval(ctx, redisReleaseScript, []string{"my-key"}, "my-token").Return(redis.NewCmdResult(int64(1), nil))
+
+	locker := NewRedis(client)
+
+	// When
+	err := locker.Release(ctx, "my-key", "my-token")
+
+	// Then
+	assert.Nil(t, err)
+}
+
+func TestRedisReleaseWhenNotOwner(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	client := mocksLock.NewMockRedisClientInterface(ctrl)
+	client.EXPECT().Eval(ctx, redisReleaseScript, []string{"my-key"}, "my-token").Return(redis.NewCmdResult(int64(0), nil))
+
+	locker := NewRedis(client)
+
+	// When
+	err := locker.Release(ctx, "my-key", "my-token")
+
+	// Then
+	assert.Equal(t, ErrNotHeld, err)
+}
+
+func TestRedisGetType(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	client := mocksLock.NewMockRedisClientInterface(ctrl)
+
+	locker := NewRedis(client)
+
+	// When - Then
+	assert.Equal(t, RedisType, locker.GetType())
+}
@@ -0,0 +1,171 @@
+package marshaler
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/vmihailenco/msgpack"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// MagicByte is the first byte of values encoded with a format other than msgpack,
+	// followed by the format identifier. This byte is never used by msgpack so values
+	// encoded by previous versions (msgpack without header) can still be read.
+	MagicByte byte = 0xC1
+
+	// MsgpackFormatID identifies the msgpack format
+	MsgpackFormatID byte = 0x01
+	// JSONFormatID identifies the JSON format
+	JSONFormatID byte = 0x02
+	// GobFormatID identifies the gob format
+	GobFormatID byte = 0x03
+	// ProtobufFormatID identifies the protobuf format
+	ProtobufFormatID byte = 0x04
+)
+
+// ErrNotProtoMessage is returned when using the protobuf format with values
+// that are not proto.Message
+var ErrNotProtoMessage = errors.New("value is not a proto.Message")
+
+// Format represents an encoding format of cache values
+type Format interface {
+	ID() byte
+	Marshal(value any) ([]byte, error)
+	Unmarshal(data []byte, value any) error
+}
+
+// MsgpackFormat encodes values using MessagePack. Values are written without header.
+type MsgpackFormat struct{}
+
+// ID returns the format identifier
+func (MsgpackFormat) ID() byte {
+	return MsgpackFormatID
+}
+
+// Marshal encodes the given value
+func (MsgpackFormat) Marshal(value any) ([]byte, error) {
+	return msgpack.Marshal(value)
+}
+
+// Unmarshal decodes the given data into the value pointer
+func (MsgpackFormat) Unmarshal(data []byte, value any) error {
+	return msgpack.Unmarshal(data, value)
+}
+
+// JSONFormat encodes values using encoding/json
+type JSONFormat struct{}
+
+// ID returns the format identifier
+func (JSONFormat) ID() byte {
+	return JSONFormatID
+}
+
+// Marshal encodes the given value
+func (JSONFormat) Marshal(value any) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Unmarshal decodes the given data into the value pointer
+func (JSONFormat) Unmarshal(data []byte, value any) error {
+	return json.Unmarshal(data, value)
+}
+
+// GobFormat encodes values using encoding/gob
+type GobFormat struct{}
+
+// ID returns the format identifier
+func (GobFormat) ID() byte {
+	return GobFormatID
+}
+
+// Marshal encodes the given value
+func (GobFormat) Marshal(value any) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(value); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Unmarshal decodes the given data into the value pointer
+func (GobFormat) Unmarshal(data []byte, value any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(value)
+}
+
+// ProtobufFormat encodes proto.Message values using protocol buffers
+type ProtobufFormat struct{}
+
+// ID returns the format identifier
+func (ProtobufFormat) ID() byte {
+	return ProtobufFormatID
+}
+
+// Marshal encodes the given value
+func (ProtobufFormat) Marshal(value any) ([]byte, error) {
+	message, ok := value.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrNotProtoMessage, value)
+	}
+
+	return proto.Marshal(message)
+}
+
+// Unmarshal decodes the given data into the value pointer, which can either be
+// a proto.Message or a pointer to a proto.Message that is allocated if nil
+func (ProtobufFormat) Unmarshal(data []byte, value any) error {
+	if message, ok := value.(proto.Message); ok {
+		return proto.Unmarshal(data, message)
+	}
+
+	pointer := reflect.ValueOf(value)
+	if pointer.Kind() != reflect.Ptr || pointer.Elem().Kind() != reflect.Ptr {
+		return fmt.Errorf("%w: %T", ErrNotProtoMessage, value)
+	}
+
+	target := pointer.Elem()
+	if target.IsNil() {
+		target.Set(reflect.New(target.Type().Elem()))
+	}
+
+	message, ok := target.Interface().(proto.Message)
+	if !ok {
+		return fmt.Errorf("%w: %T", ErrNotProtoMessage, value)
+	}
+
+	return proto.Unmarshal(data, message)
+}
+
+// encode encodes the given value with the given format, prefixed by the format header
+// for formats other than msgpack
+func encode(format Format, value any) ([]byte, error) {
+	data, err := format.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if format.ID() == MsgpackFormatID {
+		return data, nil
+	}
+
+	return append([]byte{MagicByte, format.ID()}, data...), nil
+}
+
+// decode detects the format of the given data from its header and decodes it into the value pointer
+func decode(formats map[byte]Format, data []byte, value any) error {
+	if len(data) < 2 || data[0] != MagicByte {
+		return MsgpackFormat{}.Unmarshal(data, value)
+	}
+
+	format, ok := formats[data[1]]
+	if !ok {
+		return fmt.Errorf("unknown format identifier 0x%02x", data[1])
+	}
+
+	return format.Unmarshal(data[2:], value)
+}
@@ -0,0 +1,78 @@
+package marshaler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type testFormatValue struct {
+	ID   int
+	Name string
+}
+
+func TestFormatsRoundTrip(t *testing.T) {
+	value := testFormatValue{ID: 1, Name: "my-name"}
+
+	testCases := []Format{MsgpackFormat{}, JSONFormat{}, GobFormat{}}
+
+	for _, format := range testCases {
+		// When
+		data, err := encode(format, value)
+		assert.Nil(t, err)
+
+		var decoded testFormatValue
+		err = decode(map[byte]Format{format.ID(): format}, data, &decoded)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, value, decoded)
+	}
+}
+
+func TestEncodeWhenMsgpackHasNoHeader(t *testing.T) {
+	// When
+	data, err := encode(MsgpackFormat{}, "test")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0xa4, 0x74, 0x65, 0x73, 0x74}, data)
+}
+
+func TestDecodeWhenUnknownFormat(t *testing.T) {
+	// Given
+	var value testFormatValue
+
+	// When
+	err := decode(map[byte]Format{}, []byte{MagicByte, 0x7f, 0x01}, &value)
+
+	// Then
+	assert.EqualError(t, err, "unknown format identifier 0x7f")
+}
+
+func TestProtobufFormat(t *testing.T) {
+	// Given
+	timestamp := timestamppb.New(time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC))
+
+	// When
+	data, err := encode(ProtobufFormat{}, timestamp)
+	assert.Nil(t, err)
+
+	var decoded *timestamppb.Timestamp
+	err = decode(map[byte]Format{ProtobufFormatID: ProtobufFormat{}}, data, &decoded)
+
+	// Then
+	assert.Nil(t, err)
+	assert.True(t, proto.Equal(timestamp, decoded))
+}
+
+func TestProtobufFormatWhenNotProtoMessage(t *testing.T) {
+	// When
+	_, err := ProtobufFormat{}.Marshal(testFormatValue{})
+
+	// Then
+	assert.ErrorIs(t, err, ErrNotProtoMessage)
+}
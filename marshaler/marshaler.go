@@ -2,48 +2,109 @@ package marshaler
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
 
 	"github.com/eko/gocache/v3/cache"
 	"github.com/eko/gocache/v3/store"
-	"github.com/vmihailenco/msgpack"
 )
 
+// ErrTTLNotSupported is returned by GetWithTTL when the wrapped cache cannot return TTLs
+var ErrTTLNotSupported = errors.New("wrapped cache does not support GetWithTTL")
+
+// MarshalerOption represents a marshaler option function
+type MarshalerOption[T any] func(o *Marshaler[T])
+
+// WithFormat allows setting the format used to encode values. Default format is msgpack.
+// Values encoded with any known format can still be read, so the format can be changed
+// without flushing the cache.
+func WithFormat[T any](format Format) MarshalerOption[T] {
+	return func(o *Marshaler[T]) {
+		o.format = format
+		o.formats[format.ID()] = format
+	}
+}
+
+// WithFormats allows registering custom formats so values encoded with them can be read
+func WithFormats[T any](formats ...Format) MarshalerOption[T] {
+	return func(o *Marshaler[T]) {
+		for _, format := range formats {
+			o.formats[format.ID()] = format
+		}
+	}
+}
+
 // Marshaler is the struct that marshal and unmarshal cache values
-type Marshaler struct {
-	cache cache.CacheInterface[any]
+type Marshaler[T any] struct {
+	cache   cache.CacheInterface[any]
+	format  Format
+	formats map[byte]Format
+	closed  uint32
 }
 
 // New creates a new marshaler that marshals/unmarshals cache values
-func New(cache cache.CacheInterface[any]) *Marshaler {
-	return &Marshaler{
-		cache: cache,
+func New[T any](cache cache.CacheInterface[any], options ...MarshalerOption[T]) *Marshaler[T] {
+	marshaler := &Marshaler[T]{
+		cache:  cache,
+		format: MsgpackFormat{},
+		formats: map[byte]Format{
+			MsgpackFormatID:  MsgpackFormat{},
+			JSONFormatID:     JSONFormat{},
+			GobFormatID:      GobFormat{},
+			ProtobufFormatID: ProtobufFormat{},
+		},
+	}
+
+	for _, option := range options {
+		option(marshaler)
 	}
+
+	return marshaler
 }
 
-// Get obtains a value from cache and unmarshal value with given object
-func (c *Marshaler) Get(ctx context.Context, key any, returnObj any) (any, error) {
+// Get obtains a value from cache and unmarshal it
+func (c *Marshaler[T]) Get(ctx context.Context, key any) (T, error) {
+	if c.isClosed() {
+		return *new(T), cache.ErrClosed
+	}
+
 	result, err := c.cache.Get(ctx, key)
 	if err != nil {
-		return nil, err
+		return *new(T), err
 	}
 
-	switch v := result.(type) {
-	case []byte:
-		err = msgpack.Unmarshal(v, returnObj)
-	case string:
-		err = msgpack.Unmarshal([]byte(v), returnObj)
+	return c.unmarshal(result)
+}
+
+// GetWithTTL obtains a value from cache, unmarshal it and returns its TTL
+func (c *Marshaler[T]) GetWithTTL(ctx context.Context, key any) (T, time.Duration, error) {
+	if c.isClosed() {
+		return *new(T), 0, cache.ErrClosed
+	}
+
+	setterCache, ok := c.cache.(cache.SetterCacheInterface[any])
+	if !ok {
+		return *new(T), 0, ErrTTLNotSupported
 	}
 
+	result, ttl, err := setterCache.GetWithTTL(ctx, key)
 	if err != nil {
-		return nil, err
+		return *new(T), ttl, err
 	}
 
-	return returnObj, nil
+	object, err := c.unmarshal(result)
+	return object, ttl, err
 }
 
 // Set sets a value in cache by marshaling value
-func (c *Marshaler) Set(ctx context.Context, key, object any, options ...store.Option) error {
-	bytes, err := msgpack.Marshal(object)
+func (c *Marshaler[T]) Set(ctx context.Context, key any, object T, options ...store.Option) error {
+	if c.isClosed() {
+		return cache.ErrClosed
+	}
+
+	bytes, err := encode(c.format, object)
 	if err != nil {
 		return err
 	}
@@ -52,16 +113,77 @@ func (c *Marshaler) Set(ctx context.Context, key, object any, options ...store.O
 }
 
 // Delete removes a value from the cache
-func (c *Marshaler) Delete(ctx context.Context, key any) error {
+func (c *Marshaler[T]) Delete(ctx context.Context, key any) error {
+	if c.isClosed() {
+		return cache.ErrClosed
+	}
+
 	return c.cache.Delete(ctx, key)
 }
 
 // Invalidate invalidate cache values using given options
-func (c *Marshaler) Invalidate(ctx context.Context, options ...store.InvalidateOption) error {
+func (c *Marshaler[T]) Invalidate(ctx context.Context, options ...store.InvalidateOption) error {
+	if c.isClosed() {
+		return cache.ErrClosed
+	}
+
 	return c.cache.Invalidate(ctx, options...)
 }
 
 // Clear reset all cache data
-func (c *Marshaler) Clear(ctx context.Context) error {
+func (c *Marshaler[T]) Clear(ctx context.Context) error {
+	if c.isClosed() {
+		return cache.ErrClosed
+	}
+
 	return c.cache.Clear(ctx)
 }
+
+// Close closes the wrapped cache. Next operations return cache.ErrClosed.
+func (c *Marshaler[T]) Close() error {
+	if !atomic.CompareAndSwapUint32(&c.closed, 0, 1) {
+		return nil
+	}
+
+	return c.cache.Close()
+}
+
+// CloseWithContext closes the wrapped cache before the context deadline.
+// Next operations return cache.ErrClosed.
+func (c *Marshaler[T]) CloseWithContext(ctx context.Context) error {
+	if !atomic.CompareAndSwapUint32(&c.closed, 0, 1) {
+		return nil
+	}
+
+	if closer, ok := c.cache.(cache.CloserInterface); ok {
+		return closer.CloseWithContext(ctx)
+	}
+
+	return c.cache.Close()
+}
+
+// isClosed returns true once the marshaler has been closed
+func (c *Marshaler[T]) isClosed() bool {
+	return atomic.LoadUint32(&c.closed) == 1
+}
+
+// unmarshal decodes a value retrieved from the cache
+func (c *Marshaler[T]) unmarshal(result any) (T, error) {
+	var data []byte
+
+	switch v := result.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return *new(T), fmt.Errorf("%w: unexpected %T value in cache", cache.ErrDecode, result)
+	}
+
+	var object T
+	if err := decode(c.formats, data, &object); err != nil {
+		return *new(T), fmt.Errorf("%w: %v", cache.ErrDecode, err)
+	}
+
+	return object, nil
+}
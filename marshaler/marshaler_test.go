@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	gocache "github.com/eko/gocache/v3/cache"
 	"github.com/eko/gocache/v3/store"
 	mocksCache "github.com/eko/gocache/v3/test/mocks/cache"
 	"github.com/golang/mock/gomock"
@@ -24,11 +25,12 @@ func TestNew(t *testing.T) {
 	cache := mocksCache.NewMockCacheInterface[any](ctrl)
 
 	// When
-	marshaler := New(cache)
+	marshaler := New[*testCacheValue](cache)
 
 	// Then
-	assert.IsType(t, new(Marshaler), marshaler)
+	assert.IsType(t, new(Marshaler[*testCacheValue]), marshaler)
 	assert.Equal(t, cache, marshaler.cache)
+	assert.Equal(t, MsgpackFormat{}, marshaler.format)
 }
 
 func TestGetWhenStoreReturnsSliceOfBytes(t *testing.T) {
@@ -49,10 +51,10 @@ func TestGetWhenStoreReturnsSliceOfBytes(t *testing.T) {
 	cache := mocksCache.NewMockCacheInterface[any](ctrl)
 	cache.EXPECT().Get(ctx, "my-key").Return(cacheValueBytes, nil)
 
-	marshaler := New(cache)
+	marshaler := New[*testCacheValue](cache)
 
 	// When
-	value, err := marshaler.Get(ctx, "my-key", new(testCacheValue))
+	value, err := marshaler.Get(ctx, "my-key")
 
 	// Then
 	assert.Nil(t, err)
@@ -77,10 +79,10 @@ func TestGetWhenStoreReturnsString(t *testing.T) {
 	cache := mocksCache.NewMockCacheInterface[any](ctrl)
 	cache.EXPECT().Get(ctx, "my-key").Return(string(cacheValueBytes), nil)
 
-	marshaler := New(cache)
+	marshaler := New[*testCacheValue](cache)
 
 	// When
-	value, err := marshaler.Get(ctx, "my-key", new(testCacheValue))
+	value, err := marshaler.Get(ctx, "my-key")
 
 	// Then
 	assert.Nil(t, err)
@@ -96,13 +98,13 @@ func TestGetWhenUnmarshalingError(t *testing.T) {
 	cache := mocksCache.NewMockCacheInterface[any](ctrl)
 	cache.EXPECT().Get(ctx, "my-key").Return("unknown-string", nil)
 
-	marshaler := New(cache)
+	marshaler := New[*testCacheValue](cache)
 
 	// When
-	value, err := marshaler.Get(ctx, "my-key", new(testCacheValue))
+	value, err := marshaler.Get(ctx, "my-key")
 
 	// Then
-	assert.NotNil(t, err)
+	assert.ErrorIs(t, err, gocache.ErrDecode)
 	assert.Nil(t, value)
 }
 
@@ -117,10 +119,10 @@ func TestGetWhenNotFoundInStore(t *testing.T) {
 	cache := mocksCache.NewMockCacheInterface[any](ctrl)
 	cache.EXPECT().Get(ctx, "my-key").Return(nil, expectedErr)
 
-	marshaler := New(cache)
+	marshaler := New[*testCacheValue](cache)
 
 	// When
-	value, err := marshaler.Get(ctx, "my-key", new(testCacheValue))
+	value, err := marshaler.Get(ctx, "my-key")
 
 	// Then
 	assert.Equal(t, expectedErr, err)
@@ -147,7 +149,7 @@ func TestSetWhenStruct(t *testing.T) {
 		},
 	).Return(nil)
 
-	marshaler := New(cache)
+	marshaler := New[*testCacheValue](cache)
 
 	// When
 	err := marshaler.Set(ctx, "my-key", cacheValue, store.WithExpiration(5*time.Second))
@@ -174,7 +176,7 @@ func TestSetWhenString(t *testing.T) {
 		},
 	).Return(nil)
 
-	marshaler := New(cache)
+	marshaler := New[string](cache)
 
 	// When
 	err := marshaler.Set(ctx, "my-key", cacheValue, store.WithExpiration(5*time.Second))
@@ -201,7 +203,7 @@ func TestSetWhenError(t *testing.T) {
 		store.OptionsMatcher{Expiration: 5 * time.Second},
 	).Return(expectedErr)
 
-	marshaler := New(cache)
+	marshaler := New[string](cache)
 
 	// When
 	err := marshaler.Set(ctx, "my-key", cacheValue, store.WithExpiration(5*time.Second))
@@ -219,7 +221,7 @@ func TestDelete(t *testing.T) {
 	cache := mocksCache.NewMockCacheInterface[any](ctrl)
 	cache.EXPECT().Delete(ctx, "my-key").Return(nil)
 
-	marshaler := New(cache)
+	marshaler := New[any](cache)
 
 	// When
 	err := marshaler.Delete(ctx, "my-key")
@@ -239,7 +241,7 @@ func TestDeleteWhenError(t *testing.T) {
 	cache := mocksCache.NewMockCacheInterface[any](ctrl)
 	cache.EXPECT().Delete(ctx, "my-key").Return(expectedErr)
 
-	marshaler := New(cache)
+	marshaler := New[any](cache)
 
 	// When
 	err := marshaler.Delete(ctx, "my-key")
@@ -259,7 +261,7 @@ func TestInvalidate(t *testing.T) {
 		Tags: []string{"tag1"},
 	}).Return(nil)
 
-	marshaler := New(cache)
+	marshaler := New[any](cache)
 
 	// When
 	err := marshaler.Invalidate(ctx, store.WithInvalidateTags([]string{"tag1"}))
@@ -279,7 +281,7 @@ func TestInvalidatingWhenError(t *testing.T) {
 	cache := mocksCache.NewMockCacheInterface[any](ctrl)
 	cache.EXPECT().Invalidate(ctx, store.InvalidateOptionsMatcher{Tags: []string{"tag1"}}).Return(expectedErr)
 
-	marshaler := New(cache)
+	marshaler := New[any](cache)
 
 	// When
 	err := marshaler.Invalidate(ctx, store.WithInvalidateTags([]string{"tag1"}))
@@ -297,7 +299,7 @@ func TestClear(t *testing.T) {
 	cache := mocksCache.NewMockCacheInterface[any](ctrl)
 	cache.EXPECT().Clear(ctx).Return(nil)
 
-	marshaler := New(cache)
+	marshaler := New[any](cache)
 
 	// When
 	err := marshaler.Clear(ctx)
@@ -317,7 +319,7 @@ func TestClearWhenError(t *testing.T) {
 	cache := mocksCache.NewMockCacheInterface[any](ctrl)
 	cache.EXPECT().Clear(ctx).Return(expectedErr)
 
-	marshaler := New(cache)
+	marshaler := New[any](cache)
 
 	// When
 	err := marshaler.Clear(ctx)
@@ -325,3 +327,114 @@ func TestClearWhenError(t *testing.T) {
 	// Then
 	assert.Equal(t, expectedErr, err)
 }
+
+func TestSetWithFormat(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	cache := mocksCache.NewMockCacheInterface[any](ctrl)
+	cache.EXPECT().Set(
+		ctx,
+		"my-key",
+		append([]byte{MagicByte, JSONFormatID}, []byte(`{"Hello":"world"}`)...),
+	).Return(nil)
+
+	marshaler := New[testCacheValue](cache, WithFormat[testCacheValue](JSONFormat{}))
+
+	// When
+	err := marshaler.Set(ctx, "my-key", testCacheValue{Hello: "world"})
+
+	// Then
+	assert.Nil(t, err)
+}
+
+func TestGetWhenValueHasBeenWrittenWithAnotherFormat(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	legacyValue, err := msgpack.Marshal(testCacheValue{Hello: "world"})
+	assert.Nil(t, err)
+
+	cache := mocksCache.NewMockCacheInterface[any](ctrl)
+	cache.EXPECT().Get(ctx, "my-key").Return(legacyValue, nil)
+
+	marshaler := New[testCacheValue](cache, WithFormat[testCacheValue](JSONFormat{}))
+
+	// When
+	value, err := marshaler.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, testCacheValue{Hello: "world"}, value)
+}
+
+func TestGetWithTTL(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	cacheValueBytes, err := GobFormat{}.Marshal(testCacheValue{Hello: "world"})
+	assert.Nil(t, err)
+
+	cache := mocksCache.NewMockSetterCacheInterface[any](ctrl)
+	cache.EXPECT().GetWithTTL(ctx, "my-key").Return(
+		append([]byte{MagicByte, GobFormatID}, cacheValueBytes...),
+		5*time.Second,
+		nil,
+	)
+
+	marshaler := New[testCacheValue](cache)
+
+	// When
+	value, ttl, err := marshaler.GetWithTTL(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, testCacheValue{Hello: "world"}, value)
+	assert.Equal(t, 5*time.Second, ttl)
+}
+
+func TestGetWithTTLWhenNotSupported(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	cache := mocksCache.NewMockCacheInterface[any](ctrl)
+
+	marshaler := New[testCacheValue](cache)
+
+	// When
+	_, _, err := marshaler.GetWithTTL(ctx, "my-key")
+
+	// Then
+	assert.Equal(t, ErrTTLNotSupported, err)
+}
+
+func TestClose(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	cache := mocksCache.NewMockCacheInterface[any](ctrl)
+	cache.EXPECT().Close().Return(nil)
+
+	marshaler := New[*testCacheValue](cache)
+
+	// When
+	err := marshaler.Close()
+	secondErr := marshaler.Close()
+
+	// Then
+	assert.Nil(t, err)
+	assert.Nil(t, secondErr)
+
+	_, getErr := marshaler.Get(context.Background(), "my-key")
+	assert.Equal(t, gocache.ErrClosed, getErr)
+	assert.Equal(t, gocache.ErrClosed, marshaler.Set(context.Background(), "my-key", &testCacheValue{}))
+	assert.Equal(t, gocache.ErrClosed, marshaler.Delete(context.Background(), "my-key"))
+}
@@ -6,3 +6,10 @@ import "github.com/eko/gocache/v3/codec"
 type MetricsInterface interface {
 	RecordFromCodec(codec codec.CodecInterface)
 }
+
+// NamedMetricsInterface represents a metrics provider able to record metrics
+// under a given name instead of the store type, for instance a chain cache layer name
+type NamedMetricsInterface interface {
+	MetricsInterface
+	RecordFromCodecWithName(name string, codec codec.CodecInterface)
+}
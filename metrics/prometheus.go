@@ -1,7 +1,12 @@
 package metrics
 
 import (
+	"sync"
+	"time"
+	"unicode/utf8"
+
 	"github.com/eko/gocache/v3/codec"
+	"github.com/eko/gocache/v3/store"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
@@ -10,13 +15,33 @@ const (
 	namespaceCache = "cache"
 )
 
-var cacheCollector *prometheus.GaugeVec = initCacheCollector(namespaceCache)
+var (
+	cacheCollector *prometheus.GaugeVec = initCacheCollector(namespaceCache)
+
+	compressionRatioCollector    *prometheus.HistogramVec = initCompressionRatioCollector(namespaceCache)
+	compressionDurationCollector *prometheus.HistogramVec = initCompressionDurationCollector(namespaceCache)
+
+	decryptionFailuresCollector *prometheus.CounterVec = initDecryptionFailuresCollector(namespaceCache)
+)
 
 // Prometheus represents the prometheus struct for collecting metrics
 type Prometheus struct {
 	service      string
 	collector    *prometheus.GaugeVec
-	codecChannel chan codec.CodecInterface
+	codecChannel chan *namedCodec
+	closeMutex   sync.RWMutex
+	closed       bool
+	recorderDone chan struct{}
+
+	compressionRatio    *prometheus.HistogramVec
+	compressionDuration *prometheus.HistogramVec
+
+	decryptionFailures *prometheus.CounterVec
+}
+
+type namedCodec struct {
+	name  string
+	codec codec.CodecInterface
 }
 
 func initCacheCollector(namespace string) *prometheus.GaugeVec {
@@ -31,12 +56,53 @@ func initCacheCollector(namespace string) *prometheus.GaugeVec {
 	return c
 }
 
+func initCompressionRatioCollector(namespace string) *prometheus.HistogramVec {
+	return promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:      "compression_ratio",
+			Namespace: namespace,
+			Help:      "This represent the ratio between compressed and original value sizes",
+			Buckets:   prometheus.LinearBuckets(0.1, 0.1, 10),
+		},
+		[]string{"service", "algorithm"},
+	)
+}
+
+func initCompressionDurationCollector(namespace string) *prometheus.HistogramVec {
+	return promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:      "compression_duration_seconds",
+			Namespace: namespace,
+			Help:      "This represent the time spent compressing and decompressing values",
+			Buckets:   prometheus.ExponentialBuckets(0.00001, 4, 10),
+		},
+		[]string{"service", "algorithm", "operation"},
+	)
+}
+
+func initDecryptionFailuresCollector(namespace string) *prometheus.CounterVec {
+	return promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:      "decryption_failures_total",
+			Namespace: namespace,
+			Help:      "This represent the number of values that could not be decrypted",
+		},
+		[]string{"service", "key_id"},
+	)
+}
+
 // NewPrometheus initializes a new prometheus metric instance
 func NewPrometheus(service string) *Prometheus {
 	prometheus := &Prometheus{
 		service:      service,
 		collector:    cacheCollector,
-		codecChannel: make(chan codec.CodecInterface, 10000),
+		codecChannel: make(chan *namedCodec, 10000),
+		recorderDone: make(chan struct{}),
+
+		compressionRatio:    compressionRatioCollector,
+		compressionDuration: compressionDurationCollector,
+
+		decryptionFailures: decryptionFailuresCollector,
 	}
 
 	go prometheus.recorder()
@@ -51,9 +117,15 @@ func (m *Prometheus) record(store, metric string, value float64) {
 
 // Recorder records metrics in prometheus by retrieving values from the codec channel
 func (m *Prometheus) recorder() {
-	for codec := range m.codecChannel {
-		stats := codec.GetStats()
-		storeType := codec.GetStore().GetType()
+	defer close(m.recorderDone)
+
+	for item := range m.codecChannel {
+		stats := item.codec.GetStats()
+
+		storeType := item.name
+		if storeType == "" {
+			storeType = item.codec.GetStore().GetType()
+		}
 
 		m.record(storeType, "hit_count", float64(stats.Hits))
 		m.record(storeType, "miss_count", float64(stats.Miss))
@@ -71,5 +143,62 @@ func (m *Prometheus) recorder() {
 
 // RecordFromCodec sends the given codec into the codec channel to be read from recorder
 func (m *Prometheus) RecordFromCodec(codec codec.CodecInterface) {
-	m.codecChannel <- codec
+	m.send(&namedCodec{codec: codec})
+}
+
+// RecordFromCodecWithName sends the given codec into the codec channel to be read from recorder,
+// using the given name as store label instead of the store type
+func (m *Prometheus) RecordFromCodecWithName(name string, codec codec.CodecInterface) {
+	m.send(&namedCodec{name: name, codec: codec})
+}
+
+// send sends the given codec into the codec channel, unless the recorder has been closed
+func (m *Prometheus) send(item *namedCodec) {
+	m.closeMutex.RLock()
+	defer m.closeMutex.RUnlock()
+
+	if !m.closed {
+		m.codecChannel <- item
+	}
+}
+
+// Close stops the recorder after it has recorded the codecs waiting in the channel.
+// Next codecs are not recorded anymore.
+func (m *Prometheus) Close() error {
+	m.closeMutex.Lock()
+	if m.closed {
+		m.closeMutex.Unlock()
+		return nil
+	}
+	m.closed = true
+	close(m.codecChannel)
+	m.closeMutex.Unlock()
+
+	<-m.recorderDone
+
+	return nil
+}
+
+// RecordCompression records the compression ratio and duration of a value compressed
+// by a store.CompressionStore
+func (m *Prometheus) RecordCompression(algorithm string, inputSize, outputSize int, duration time.Duration) {
+	if inputSize > 0 {
+		m.compressionRatio.WithLabelValues(m.service, algorithm).Observe(float64(outputSize) / float64(inputSize))
+	}
+	m.compressionDuration.WithLabelValues(m.service, algorithm, "compress").Observe(duration.Seconds())
+}
+
+// RecordDecompression records the decompression duration of a value read by a store.CompressionStore
+func (m *Prometheus) RecordDecompression(algorithm string, duration time.Duration) {
+	m.compressionDuration.WithLabelValues(m.service, algorithm, "decompress").Observe(duration.Seconds())
+}
+
+// RecordDecryptionFailure records a value that could not be decrypted by a store.EncryptionStore.
+// The key identifier is the one of a key of the keyring, or store.UnknownEncryptionKeyID.
+func (m *Prometheus) RecordDecryptionFailure(keyID string) {
+	if !utf8.ValidString(keyID) {
+		keyID = store.UnknownEncryptionKeyID
+	}
+
+	m.decryptionFailures.WithLabelValues(m.service, keyID).Inc()
 }
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/eko/gocache/v3/codec"
+	"github.com/eko/gocache/v3/store"
 	mocksCodec "github.com/eko/gocache/v3/test/mocks/codec"
 	mocksStore "github.com/eko/gocache/v3/test/mocks/store"
 	"github.com/golang/mock/gomock"
@@ -125,3 +126,110 @@ func TestRecordFromCodec(t *testing.T) {
 		assert.Equal(t, tc.expected, v)
 	}
 }
+
+func TestRecordFromCodecWithName(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	stats := &codec.Stats{
+		Hits: 7,
+		Miss: 2,
+	}
+
+	testCodec := mocksCodec.NewMockCodecInterface(ctrl)
+	testCodec.EXPECT().GetStats().Return(stats)
+
+	metrics := NewPrometheus("my-test-service-name")
+
+	// When
+	metrics.RecordFromCodecWithName("regional-redis", testCodec)
+
+	// Wait for data to be processed
+	for len(metrics.codecChannel) > 0 {
+		time.Sleep(1 * time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	// Then
+	metric, err := metrics.collector.GetMetricWithLabelValues("my-test-service-name", "regional-redis", "hit_count")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assert.Equal(t, float64(stats.Hits), testutil.ToFloat64(metric))
+}
+
+func TestRecordCompression(t *testing.T) {
+	// Given
+	metrics := NewPrometheus("my-test-service-name")
+
+	// When
+	metrics.RecordCompression("gzip", 1000, 250, time.Millisecond)
+	metrics.RecordDecompression("gzip", time.Millisecond)
+
+	// Then
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.compressionRatio))
+	assert.Equal(t, 2, testutil.CollectAndCount(metrics.compressionDuration))
+}
+
+func TestRecordDecryptionFailure(t *testing.T) {
+	// Given
+	metrics := NewPrometheus("my-test-service-name")
+
+	// When
+	metrics.RecordDecryptionFailure("key-1")
+	metrics.RecordDecryptionFailure("key-1")
+
+	// Then
+	counter, err := metrics.decryptionFailures.GetMetricWithLabelValues("my-test-service-name", "key-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(counter))
+}
+
+func TestRecordDecryptionFailureWhenKeyIDIsNotValidUTF8(t *testing.T) {
+	// Given
+	metrics := NewPrometheus("my-test-service-name")
+
+	// When
+	metrics.RecordDecryptionFailure("\xff\xfe")
+
+	// Then
+	counter, err := metrics.decryptionFailures.GetMetricWithLabelValues("my-test-service-name", store.UnknownEncryptionKeyID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(counter))
+}
+
+func TestPrometheusClose(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	stats := &codec.Stats{Hits: 3}
+
+	testCodec := mocksCodec.NewMockCodecInterface(ctrl)
+	testCodec.EXPECT().GetStats().Return(stats)
+
+	metrics := NewPrometheus("my-test-service-name")
+	metrics.RecordFromCodecWithName("closed-redis", testCodec)
+
+	// When
+	err := metrics.Close()
+
+	// Then
+	assert.Nil(t, err)
+
+	metric, err := metrics.collector.GetMetricWithLabelValues("my-test-service-name", "closed-redis", "hit_count")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.Equal(t, float64(stats.Hits), testutil.ToFloat64(metric))
+
+	// Codecs are not recorded anymore, and closing twice is a no-op
+	metrics.RecordFromCodec(testCodec)
+	assert.Nil(t, metrics.Close())
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/allegro/bigcache/v3"
 )
 
 // BigcacheClientInterface represents a allegro/bigcache client
@@ -23,10 +25,16 @@ const (
 	BigcacheTagPattern = "gocache_tag_%s"
 )
 
+// bigcacheIteratorClient is implemented by Bigcache clients able to iterate over their entries
+type bigcacheIteratorClient interface {
+	Iterator() *bigcache.EntryInfoIterator
+}
+
 // BigcacheStore is a store for Bigcache
 type BigcacheStore struct {
 	client  BigcacheClientInterface
 	options *Options
+	closer  closeState
 }
 
 // NewBigcache creates a new store to Bigcache instance(s)
@@ -39,6 +47,10 @@ func NewBigcache(client BigcacheClientInterface, options ...Option) *BigcacheSto
 
 // Get returns data stored from a given key
 func (s *BigcacheStore) Get(_ context.Context, key any) (any, error) {
+	if s.closer.isClosed() {
+		return nil, ErrClosed
+	}
+
 	item, err := s.client.Get(key.(string))
 	if err != nil {
 		return nil, err
@@ -52,12 +64,20 @@ func (s *BigcacheStore) Get(_ context.Context, key any) (any, error) {
 
 // GetWithTTL returns data stored from a given key and its corresponding TTL
 func (s *BigcacheStore) GetWithTTL(ctx context.Context, key any) (any, time.Duration, error) {
+	if s.closer.isClosed() {
+		return nil, 0, ErrClosed
+	}
+
 	item, err := s.Get(ctx, key)
 	return item, 0, err
 }
 
 // Set defines data in Bigcache for given key identifier
 func (s *BigcacheStore) Set(ctx context.Context, key any, value any, options ...Option) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
 	opts := applyOptionsWithDefault(s.options, options...)
 
 	var val []byte
@@ -111,11 +131,19 @@ func (s *BigcacheStore) setTags(ctx context.Context, key any, tags []string) {
 
 // Delete removes data from Bigcache for given key identifier
 func (s *BigcacheStore) Delete(_ context.Context, key any) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
 	return s.client.Delete(key.(string))
 }
 
 // Invalidate invalidates some cache data in Bigcache for given options
 func (s *BigcacheStore) Invalidate(ctx context.Context, options ...InvalidateOption) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
 	opts := ApplyInvalidateOptions(options...)
 
 	if tags := opts.tags; len(tags) > 0 {
@@ -142,10 +170,94 @@ func (s *BigcacheStore) Invalidate(ctx context.Context, options ...InvalidateOpt
 
 // Clear resets all data in the store
 func (s *BigcacheStore) Clear(_ context.Context) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
 	return s.client.Reset()
 }
 
+// IterateKeys calls the given function with every key starting with the given prefix.
+// The client has to be able to iterate over its entries, as *bigcache.BigCache is,
+// otherwise ErrKeyIterationNotSupported is returned.
+func (s *BigcacheStore) IterateKeys(ctx context.Context, prefix string, fn func(key string) error) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	client, ok := s.client.(bigcacheIteratorClient)
+	if !ok {
+		return ErrKeyIterationNotSupported
+	}
+
+	// Keys are listed first, so the given function is able to update the store
+	var keys []string
+	iterator := client.Iterator()
+	for iterator.SetNext() {
+		entry, err := iterator.Value()
+		if err != nil {
+			// The entry has been removed meanwhile
+			continue
+		}
+
+		if strings.HasPrefix(entry.Key(), prefix) {
+			keys = append(keys, entry.Key())
+		}
+	}
+
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := fn(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close marks the store as closed. As the client is given by the caller, it is not closed:
+// its owner has to close it (stopping the *bigcache.BigCache cleaning goroutine).
+// Next operations return ErrClosed.
+func (s *BigcacheStore) Close() error {
+	s.closer.close()
+	return nil
+}
+
 // GetType returns the store type
 func (s *BigcacheStore) GetType() string {
 	return BigcacheType
 }
+
+// BigcacheOnRemoveWithReason returns a function to be used as the OnRemoveWithReason
+// callback of the Bigcache config, emitting expire and evict events to the given hooks.
+// Deletions are not emitted, as the hook store emits them.
+func BigcacheOnRemoveWithReason(hooks *Hooks) func(key string, entry []byte, reason bigcache.RemoveReason) {
+	tagPrefix := strings.TrimSuffix(BigcacheTagPattern, "%s")
+
+	return func(key string, entry []byte, reason bigcache.RemoveReason) {
+		var eventType EventType
+		switch reason {
+		case bigcache.Expired:
+			eventType = EventExpire
+		case bigcache.NoSpace:
+			eventType = EventEvict
+		default:
+			return
+		}
+
+		if strings.HasPrefix(key, tagPrefix) {
+			return
+		}
+
+		hooks.Emit(Event{
+			Type:   eventType,
+			Key:    key,
+			Value:  append([]byte(nil), entry...),
+			Source: BigcacheType,
+			Time:   time.Now(),
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/allegro/bigcache/v3"
 	mocksStore "github.com/eko/gocache/v3/test/mocks/store/clients"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -366,3 +367,71 @@ func TestBigcacheGetType(t *testing.T) {
 	// When - Then
 	assert.Equal(t, BigcacheType, store.GetType())
 }
+
+func TestBigcacheIterateKeys(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	client, err := bigcache.NewBigCache(bigcache.DefaultConfig(time.Minute))
+	assert.Nil(t, err)
+
+	store := NewBigcache(client)
+	assert.Nil(t, store.Set(ctx, "svc-a:one", []byte("value")))
+	assert.Nil(t, store.Set(ctx, "svc-a:two", []byte("value")))
+	assert.Nil(t, store.Set(ctx, "svc-b:one", []byte("value")))
+
+	var keys []string
+
+	// When
+	err = store.IterateKeys(ctx, "svc-a:", func(key string) error {
+		keys = append(keys, key)
+		return nil
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"svc-a:one", "svc-a:two"}, keys)
+}
+
+func TestBigcacheIterateKeysWhenClientCannotIterate(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	client := mocksStore.NewMockBigcacheClientInterface(ctrl)
+
+	store := NewBigcache(client)
+
+	// When
+	err := store.IterateKeys(context.Background(), "", func(key string) error {
+		return nil
+	})
+
+	// Then
+	assert.Equal(t, ErrKeyIterationNotSupported, err)
+}
+
+func TestBigcacheOnRemoveWithReason(t *testing.T) {
+	// Given
+	hooks := NewHooks()
+	recorder := &eventRecorder{}
+	hooks.OnExpire(recorder.record)
+	hooks.OnEvict(recorder.record)
+	hooks.OnDelete(recorder.record)
+
+	onRemove := BigcacheOnRemoveWithReason(hooks)
+
+	// When
+	onRemove("expired-key", []byte("my-value"), bigcache.Expired)
+	onRemove("evicted-key", []byte("my-value"), bigcache.NoSpace)
+	onRemove("deleted-key", []byte("my-value"), bigcache.Deleted)
+	onRemove("gocache_tag_my-tag", []byte("my-key"), bigcache.Expired)
+
+	// Then
+	assert.Nil(t, hooks.Close())
+
+	assert.Equal(t, []EventType{EventExpire, EventEvict}, recorder.types())
+	assert.Equal(t, "expired-key", recorder.events[0].Key)
+	assert.Equal(t, []byte("my-value"), recorder.events[0].Value)
+	assert.Equal(t, BigcacheType, recorder.events[0].Source)
+	assert.Equal(t, "evicted-key", recorder.events[1].Key)
+}
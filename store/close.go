@@ -0,0 +1,39 @@
+package store
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+)
+
+// ErrClosed is returned by store operations called after the store has been closed.
+//
+// Stores only close the clients they create themselves (such as the Pegasus one): clients
+// given to a store constructor are owned by the caller, who has to close them once the
+// store is closed. Stores wrapping another store (compression, encryption and hooks) close
+// it, except the namespace one as several namespaces can share the same inner store.
+var ErrClosed = errors.New("store is closed")
+
+// closeStore closes the given store if it holds resources
+func closeStore(store StoreInterface) error {
+	if closer, ok := store.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// closeState records whether a store has been closed, so its operations return ErrClosed
+type closeState struct {
+	closed uint32
+}
+
+// isClosed returns true once the store has been closed
+func (c *closeState) isClosed() bool {
+	return atomic.LoadUint32(&c.closed) == 1
+}
+
+// close marks the store as closed. It returns false if it was already closed.
+func (c *closeState) close() bool {
+	return atomic.CompareAndSwapUint32(&c.closed, 0, 1)
+}
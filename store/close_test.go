@@ -0,0 +1,123 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/allegro/bigcache/v3"
+	"github.com/coocood/freecache"
+	"github.com/dgraph-io/ristretto"
+	mocksStore "github.com/eko/gocache/v3/test/mocks/store/clients"
+	"github.com/golang/mock/gomock"
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+type closableBigcacheClient struct {
+	*mocksStore.MockBigcacheClientInterface
+	closeCalls int
+}
+
+func (c *closableBigcacheClient) Close() error {
+	c.closeCalls++
+	return nil
+}
+
+func TestStoresReturnErrClosedAfterClose(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	bigcacheClient, err := bigcache.NewBigCache(bigcache.DefaultConfig(time.Minute))
+	assert.Nil(t, err)
+	defer bigcacheClient.Close()
+
+	ristrettoClient, err := ristretto.NewCache(&ristretto.Config{NumCounters: 1000, MaxCost: 100, BufferItems: 64})
+	assert.Nil(t, err)
+	defer ristrettoClient.Close()
+
+	keyring, err := NewKeyring("key-1", make([]byte, 32))
+	assert.Nil(t, err)
+
+	newGoCache := func() StoreInterface {
+		return NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+	}
+
+	stores := map[string]StoreInterface{
+		"bigcache":     NewBigcache(bigcacheClient),
+		"freecache":    NewFreecache(freecache.NewCache(1024 * 1024)),
+		"go-cache":     newGoCache(),
+		"memcache":     NewMemcache(mocksStore.NewMockMemcacheClientInterface(ctrl)),
+		"redis":        NewRedis(mocksStore.NewMockRedisClientInterface(ctrl)),
+		"rediscluster": NewRedisCluster(mocksStore.NewMockRedisClusterClientInterface(ctrl)),
+		"ristretto":    NewRistretto(ristrettoClient, WithKeyTracking()),
+		"compression":  WithCompression(newGoCache()),
+		"encryption":   WithEncryption(newGoCache(), keyring),
+		"hooks":        WithHooks(newGoCache(), NewHooks()),
+		"namespace":    WithNamespace(newGoCache(), "svc-a:"),
+	}
+
+	ctx := context.Background()
+
+	for name, store := range stores {
+		// When
+		closeErr := store.(interface{ Close() error }).Close()
+
+		_, getErr := store.Get(ctx, "my-key")
+		_, _, getWithTTLErr := store.GetWithTTL(ctx, "my-key")
+		setErr := store.Set(ctx, "my-key", []byte("my-value"))
+		deleteErr := store.Delete(ctx, "my-key")
+		invalidateErr := store.Invalidate(ctx, WithInvalidateTags([]string{"my-tag"}))
+		clearErr := store.Clear(ctx)
+		iterateErr := IterateKeys(ctx, store, "", func(key string) error { return nil })
+
+		secondCloseErr := store.(interface{ Close() error }).Close()
+
+		// Then
+		assert.Nil(t, closeErr, name)
+		assert.Equal(t, ErrClosed, getErr, name)
+		assert.Equal(t, ErrClosed, getWithTTLErr, name)
+		assert.Equal(t, ErrClosed, setErr, name)
+		assert.Equal(t, ErrClosed, deleteErr, name)
+		assert.Equal(t, ErrClosed, invalidateErr, name)
+		assert.Equal(t, ErrClosed, clearErr, name)
+		if name != "memcache" {
+			assert.Equal(t, ErrClosed, iterateErr, name)
+		}
+		assert.Nil(t, secondCloseErr, name)
+	}
+}
+
+func TestBigcacheStoreCloseKeepsClientOpen(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	client := &closableBigcacheClient{MockBigcacheClientInterface: mocksStore.NewMockBigcacheClientInterface(ctrl)}
+
+	store := NewBigcache(client)
+
+	// When
+	err := store.Close()
+	secondErr := store.Close()
+
+	// Then
+	assert.Nil(t, err)
+	assert.Nil(t, secondErr)
+	assert.Equal(t, 0, client.closeCalls)
+}
+
+func TestNamespaceStoreCloseKeepsInnerStoreOpen(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	inner := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+
+	store := WithNamespace(inner, "svc-a:")
+
+	// When
+	err := store.Close()
+
+	// Then
+	assert.Nil(t, err)
+	assert.Nil(t, inner.Set(ctx, "my-key", "my-value"))
+}
@@ -0,0 +1,435 @@
+package store
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+)
+
+// CompressionMagic prefixes the values set by the compression store, before the byte
+// recording the algorithm. 0xF8 is not a valid UTF-8 byte and is not used as a prefix
+// by other layers (marshaler values start with 0xC1, envelopes with 0xE7 and encrypted
+// values with 0x01), so values set before enabling compression are not mistaken for
+// compressed ones.
+var CompressionMagic = []byte{0xF8, 'G', 'C'}
+
+// ErrDecompression is the cause of the NotFound error returned when a value prefixed by
+// CompressionMagic cannot be decompressed
+var ErrDecompression = errors.New("unable to decompress value")
+
+const (
+	// NoCompressionID is the header byte of values stored without compression
+	NoCompressionID byte = 0x00
+	// GzipCompressionID is the header byte of values compressed using gzip
+	GzipCompressionID byte = 0x01
+	// FlateCompressionID is the header byte of values compressed using flate
+	FlateCompressionID byte = 0x02
+
+	defaultCompressionThreshold = 1024
+
+	// maxReservedCompressionID is the last header byte reserved for built-in algorithms
+	maxReservedCompressionID byte = 0x0f
+)
+
+// Compressor represents a compression algorithm usable by the compression store
+type Compressor interface {
+	ID() byte
+	Name() string
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+// CompressionRecorder records compression metrics, see metrics.Prometheus
+type CompressionRecorder interface {
+	RecordCompression(algorithm string, inputSize, outputSize int, duration time.Duration)
+	RecordDecompression(algorithm string, duration time.Duration)
+}
+
+// builtinCompressors returns the built-in algorithms, which every compression store can read
+func builtinCompressors() map[byte]Compressor {
+	return map[byte]Compressor{
+		GzipCompressionID:  GzipCompressor{Level: gzip.DefaultCompression},
+		FlateCompressionID: FlateCompressor{Level: flate.DefaultCompression},
+	}
+}
+
+// GzipCompressor compresses values using gzip
+type GzipCompressor struct {
+	Level int
+}
+
+// ID returns the header byte of the algorithm
+func (GzipCompressor) ID() byte {
+	return GzipCompressionID
+}
+
+// Name returns the name of the algorithm
+func (GzipCompressor) Name() string {
+	return "gzip"
+}
+
+// Compress compresses the given data
+func (c GzipCompressor) Compress(data []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	writer, err := gzip.NewWriterLevel(&buffer, c.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Decompress decompresses the given data
+func (GzipCompressor) Decompress(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+// FlateCompressor compresses values using flate
+type FlateCompressor struct {
+	Level int
+}
+
+// ID returns the header byte of the algorithm
+func (FlateCompressor) ID() byte {
+	return FlateCompressionID
+}
+
+// Name returns the name of the algorithm
+func (FlateCompressor) Name() string {
+	return "flate"
+}
+
+// Compress compresses the given data
+func (c FlateCompressor) Compress(data []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	writer, err := flate.NewWriter(&buffer, c.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Decompress decompresses the given data
+func (FlateCompressor) Decompress(data []byte) ([]byte, error) {
+	reader := flate.NewReader(bytes.NewReader(data))
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+// CompressionOption represents a compression store option function
+type CompressionOption func(o *CompressionStore)
+
+// WithCompressor allows setting the algorithm used to compress values. Default is gzip.
+// The algorithm is registered in the store so values compressed with it can be read.
+// Header bytes 0x00 to 0x0f are reserved for built-in algorithms: WithCompression panics
+// when another algorithm uses one of them, or when two algorithms use the same header byte.
+func WithCompressor(compressor Compressor) CompressionOption {
+	return func(o *CompressionStore) {
+		o.register(compressor)
+		o.compressor = compressor
+	}
+}
+
+// WithDecompressor allows reading values compressed using the given algorithm, without
+// using it to compress values. Header bytes are checked like WithCompressor does.
+func WithDecompressor(compressor Compressor) CompressionOption {
+	return func(o *CompressionStore) {
+		o.register(compressor)
+	}
+}
+
+// WithCompressionThreshold allows setting the size in bytes under which values are
+// stored without compression. Default is 1024 bytes.
+func WithCompressionThreshold(threshold int) CompressionOption {
+	return func(o *CompressionStore) {
+		o.threshold = threshold
+	}
+}
+
+// WithCompressionRecorder allows recording compression ratio and duration metrics
+func WithCompressionRecorder(recorder CompressionRecorder) CompressionOption {
+	return func(o *CompressionStore) {
+		o.recorder = recorder
+	}
+}
+
+// CompressionStore is a store decorator compressing []byte and string values
+// before they are set in the inner store. Stored values are prefixed by CompressionMagic
+// and a header byte recording the algorithm, so they can be read after changing the
+// algorithm. Values without this prefix, having an unknown header byte or failing to
+// Values without this prefix are considered as set before enabling compression and are
+// returned unchanged, while values having an unknown header byte or failing to decompress
+// are returned as NotFound errors. Other value types are stored unchanged.
+type CompressionStore struct {
+	inner       StoreInterface
+	compressor  Compressor
+	compressors map[byte]Compressor
+	threshold   int
+	recorder    CompressionRecorder
+	closer      closeState
+}
+
+// WithCompression creates a new store compressing the values set in the given store
+func WithCompression(inner StoreInterface, options ...CompressionOption) *CompressionStore {
+	store := &CompressionStore{
+		inner:       inner,
+		compressor:  GzipCompressor{Level: gzip.DefaultCompression},
+		compressors: builtinCompressors(),
+		threshold:   defaultCompressionThreshold,
+	}
+
+	for _, option := range options {
+		option(store)
+	}
+
+	return store
+}
+
+// Get returns data stored from a given key
+func (s *CompressionStore) Get(ctx context.Context, key any) (any, error) {
+	if s.closer.isClosed() {
+		return nil, ErrClosed
+	}
+
+	value, err := s.inner.Get(ctx, key)
+	if err != nil {
+		return value, err
+	}
+
+	return s.decompress(value)
+}
+
+// GetWithTTL returns data stored from a given key and its corresponding TTL
+func (s *CompressionStore) GetWithTTL(ctx context.Context, key any) (any, time.Duration, error) {
+	if s.closer.isClosed() {
+		return nil, 0, ErrClosed
+	}
+
+	value, ttl, err := s.inner.GetWithTTL(ctx, key)
+	if err != nil {
+		return value, ttl, err
+	}
+
+	value, err = s.decompress(value)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return value, ttl, nil
+}
+
+// Set compresses and defines data in the inner store for given key identifier
+func (s *CompressionStore) Set(ctx context.Context, key any, value any, options ...Option) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	var data []byte
+
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return s.inner.Set(ctx, key, value, options...)
+	}
+
+	compressed, err := s.compress(data)
+	if err != nil {
+		return err
+	}
+
+	// Keep the value type so values are read back with the type they were set with
+	if _, ok := value.(string); ok {
+		return s.inner.Set(ctx, key, string(compressed), options...)
+	}
+
+	return s.inner.Set(ctx, key, compressed, options...)
+}
+
+// Delete removes data from the inner store for given key identifier
+func (s *CompressionStore) Delete(ctx context.Context, key any) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	return s.inner.Delete(ctx, key)
+}
+
+// Invalidate invalidates some cache data in the inner store for given options
+func (s *CompressionStore) Invalidate(ctx context.Context, options ...InvalidateOption) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	return s.inner.Invalidate(ctx, options...)
+}
+
+// Clear resets all data in the inner store
+func (s *CompressionStore) Clear(ctx context.Context) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	return s.inner.Clear(ctx)
+}
+
+// IterateKeys iterates over the keys of the inner store, if it supports it
+func (s *CompressionStore) IterateKeys(ctx context.Context, prefix string, fn func(key string) error) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	return IterateKeys(ctx, s.inner, prefix, fn)
+}
+
+// GetKeyConstraints returns the key constraints of the inner store
+func (s *CompressionStore) GetKeyConstraints() KeyConstraints {
+	return GetKeyConstraints(s.inner)
+}
+
+// Close closes the inner store if it holds resources. Next operations return ErrClosed.
+func (s *CompressionStore) Close() error {
+	if !s.closer.close() {
+		return nil
+	}
+
+	return closeStore(s.inner)
+}
+
+// GetType returns the inner store type
+func (s *CompressionStore) GetType() string {
+	return s.inner.GetType()
+}
+
+// register allows reading values compressed using the given algorithm. It panics when the
+// header byte of the algorithm is reserved or already used by another algorithm.
+func (s *CompressionStore) register(compressor Compressor) {
+	id := compressor.ID()
+
+	if id <= maxReservedCompressionID {
+		builtin, ok := builtinCompressors()[id]
+		if !ok || reflect.TypeOf(builtin) != reflect.TypeOf(compressor) {
+			panic(fmt.Sprintf("compression header byte 0x%02x of %q is reserved for built-in algorithms", id, compressor.Name()))
+		}
+	} else if registered, ok := s.compressors[id]; ok {
+		panic(fmt.Sprintf("compression header byte 0x%02x of %q is already used by %q", id, compressor.Name(), registered.Name()))
+	}
+
+	s.compressors[id] = compressor
+}
+
+// compress returns the given data prefixed by the magic bytes and the header byte,
+// compressed if its size is over the threshold
+func (s *CompressionStore) compress(data []byte) ([]byte, error) {
+	if len(data) < s.threshold {
+		return appendCompressionHeader(NoCompressionID, data), nil
+	}
+
+	start := time.Now()
+
+	compressed, err := s.compressor.Compress(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.recorder != nil {
+		s.recorder.RecordCompression(s.compressor.Name(), len(data), len(compressed), time.Since(start))
+	}
+
+	return appendCompressionHeader(s.compressor.ID(), compressed), nil
+}
+
+// appendCompressionHeader returns the given data prefixed by the magic bytes and the given header byte
+func appendCompressionHeader(id byte, data []byte) []byte {
+	result := make([]byte, 0, len(CompressionMagic)+1+len(data))
+	result = append(result, CompressionMagic...)
+	result = append(result, id)
+
+	return append(result, data...)
+}
+
+// decompress reads the header byte of the given value and decompresses it.
+// Values read as a string are returned as a string. Values which have not been
+// set by the compression store are returned unchanged, while values which cannot
+// be decompressed are returned as NotFound errors, so they are loaded again.
+func (s *CompressionStore) decompress(value any) (any, error) {
+	var data []byte
+
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return value, nil
+	}
+
+	if len(data) <= len(CompressionMagic) || !bytes.HasPrefix(data, CompressionMagic) {
+		// Value has been set before enabling compression
+		return value, nil
+	}
+
+	id, payload := data[len(CompressionMagic)], data[len(CompressionMagic)+1:]
+
+	var result []byte
+
+	if id == NoCompressionID {
+		result = payload
+	} else {
+		compressor, ok := s.compressors[id]
+		if !ok {
+			return nil, NotFoundWithCause(fmt.Errorf("%w: unknown algorithm 0x%02x", ErrDecompression, id))
+		}
+
+		start := time.Now()
+
+		decompressed, err := compressor.Decompress(payload)
+		if err != nil {
+			return nil, NotFoundWithCause(fmt.Errorf("%w: %s: %v", ErrDecompression, compressor.Name(), err))
+		}
+
+		if s.recorder != nil {
+			s.recorder.RecordDecompression(compressor.Name(), time.Since(start))
+		}
+
+		result = decompressed
+	}
+
+	if _, ok := value.(string); ok {
+		return string(result), nil
+	}
+
+	return result, nil
+}
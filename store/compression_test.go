@@ -0,0 +1,285 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+type compressionRecorderMock struct {
+	compressions   []string
+	decompressions []string
+}
+
+func (r *compressionRecorderMock) RecordCompression(algorithm string, inputSize, outputSize int, duration time.Duration) {
+	r.compressions = append(r.compressions, algorithm)
+}
+
+func (r *compressionRecorderMock) RecordDecompression(algorithm string, duration time.Duration) {
+	r.decompressions = append(r.decompressions, algorithm)
+}
+
+type reverseCompressor struct{}
+
+func (reverseCompressor) ID() byte     { return 0x10 }
+func (reverseCompressor) Name() string { return "reverse" }
+
+func (reverseCompressor) Compress(data []byte) ([]byte, error) {
+	result := make([]byte, len(data))
+	for i, b := range data {
+		result[len(data)-1-i] = b
+	}
+	return result, nil
+}
+
+func (c reverseCompressor) Decompress(data []byte) ([]byte, error) {
+	return c.Compress(data)
+}
+
+func TestCompressionSetAndGet(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	inner := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+	recorder := &compressionRecorderMock{}
+
+	store := WithCompression(inner, WithCompressionThreshold(10), WithCompressionRecorder(recorder))
+
+	value := []byte(strings.Repeat("my-cache-value", 100))
+
+	// When
+	err := store.Set(ctx, "my-key", value)
+	assert.Nil(t, err)
+
+	result, err := store.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, value, result)
+
+	stored, err := inner.Get(ctx, "my-key")
+	assert.Nil(t, err)
+	assert.Equal(t, append(CompressionMagic, GzipCompressionID), stored.([]byte)[:len(CompressionMagic)+1])
+	assert.Less(t, len(stored.([]byte)), len(value))
+
+	assert.Equal(t, []string{"gzip"}, recorder.compressions)
+	assert.Equal(t, []string{"gzip"}, recorder.decompressions)
+}
+
+func TestCompressionSetWhenUnderThreshold(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	inner := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+
+	store := WithCompression(inner)
+
+	// When
+	err := store.Set(ctx, "my-key", "my-cache-value")
+	assert.Nil(t, err)
+
+	value, _, err := store.GetWithTTL(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "my-cache-value", value)
+
+	stored, err := inner.Get(ctx, "my-key")
+	assert.Nil(t, err)
+	assert.Equal(t, "\xf8GC\x00my-cache-value", stored)
+}
+
+type otherReverseCompressor struct {
+	reverseCompressor
+}
+
+func (otherReverseCompressor) Name() string { return "other-reverse" }
+
+type reservedCompressor struct {
+	reverseCompressor
+}
+
+func (reservedCompressor) ID() byte { return 0x03 }
+
+func TestCompressionGetWhenValueHasBeenSetWithAnotherAlgorithm(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	inner := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+
+	flateStore := WithCompression(inner, WithCompressor(FlateCompressor{Level: 9}), WithCompressionThreshold(0))
+	customStore := WithCompression(inner, WithCompressor(reverseCompressor{}), WithCompressionThreshold(0))
+
+	err := flateStore.Set(ctx, "my-flate-key", []byte("my-cache-value"))
+	assert.Nil(t, err)
+
+	err = customStore.Set(ctx, "my-custom-key", []byte("my-cache-value"))
+	assert.Nil(t, err)
+
+	gzipStore := WithCompression(inner, WithDecompressor(reverseCompressor{}))
+	otherStore := WithCompression(inner)
+
+	// When
+	flateValue, flateErr := gzipStore.Get(ctx, "my-flate-key")
+	customValue, customErr := gzipStore.Get(ctx, "my-custom-key")
+	_, unknownErr := otherStore.Get(ctx, "my-custom-key")
+
+	// Then
+	assert.Nil(t, flateErr)
+	assert.Equal(t, []byte("my-cache-value"), flateValue)
+
+	assert.Nil(t, customErr)
+	assert.Equal(t, []byte("my-cache-value"), customValue)
+
+	assert.True(t, errors.Is(unknownErr, NotFound{}))
+	assert.ErrorIs(t, unknownErr.(*NotFound).Cause(), ErrDecompression)
+}
+
+func TestWithCompressionWhenHeaderByteIsReserved(t *testing.T) {
+	// Given
+	inner := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+
+	// When - Then
+	assert.Panics(t, func() {
+		WithCompression(inner, WithCompressor(reservedCompressor{}))
+	})
+	assert.NotPanics(t, func() {
+		WithCompression(inner, WithCompressor(GzipCompressor{Level: 1}), WithDecompressor(FlateCompressor{Level: 9}))
+	})
+}
+
+func TestWithCompressionWhenHeaderByteIsAlreadyUsed(t *testing.T) {
+	// Given
+	inner := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+
+	// When - Then
+	assert.Panics(t, func() {
+		WithCompression(inner, WithCompressor(reverseCompressor{}), WithDecompressor(otherReverseCompressor{}))
+	})
+}
+
+func TestCompressionGetWhenValueHasBeenSetWithoutCompression(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	inner := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+
+	err := inner.Set(ctx, "my-key", `{"hello":"world"}`)
+	assert.Nil(t, err)
+
+	store := WithCompression(inner)
+
+	// When
+	value, err := store.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, `{"hello":"world"}`, value)
+}
+
+func TestCompressionGetWhenValueHasBeenSetWithoutCompressionAndStartsWithHeaderByte(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	inner := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+
+	legacyValues := map[string][]byte{
+		"no-compression-key": {NoCompressionID, 'a', 'b'},
+		"gzip-key":           {GzipCompressionID, 'a', 'b'},
+		"flate-key":          {FlateCompressionID, 0xff, 0xff},
+	}
+	for key, value := range legacyValues {
+		assert.Nil(t, inner.Set(ctx, key, value))
+	}
+
+	store := WithCompression(inner)
+
+	for key, expected := range legacyValues {
+		// When
+		value, err := store.Get(ctx, key)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, expected, value, key)
+	}
+}
+
+func TestCompressionGetWhenValueCannotBeDecompressed(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	inner := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+
+	corruptedValue := string(CompressionMagic) + "\x01not-gzip"
+	assert.Nil(t, inner.Set(ctx, "my-key", corruptedValue))
+
+	store := WithCompression(inner)
+
+	// When
+	value, err := store.Get(ctx, "my-key")
+	_, ttl, ttlErr := store.GetWithTTL(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, value)
+	assert.True(t, errors.Is(err, NotFound{}))
+	assert.ErrorIs(t, err.(*NotFound).Cause(), ErrDecompression)
+	assert.True(t, errors.Is(ttlErr, NotFound{}))
+	assert.Equal(t, time.Duration(0), ttl)
+}
+
+func TestCompressionSetWhenNotBytes(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	inner := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+
+	store := WithCompression(inner, WithCompressionThreshold(0))
+
+	// When
+	err := store.Set(ctx, "my-key", 12)
+	assert.Nil(t, err)
+
+	value, err := store.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, 12, value)
+}
+
+func TestCompressionGetType(t *testing.T) {
+	// Given
+	store := WithCompression(NewGoCache(cache.New(cache.NoExpiration, time.Minute)))
+
+	// When - Then
+	assert.Equal(t, GoCacheType, store.GetType())
+}
+
+type closableStore struct {
+	*GoCacheStore
+	closeCalls int
+}
+
+func (s *closableStore) Close() error {
+	s.closeCalls++
+	return nil
+}
+
+func TestCompressionStoreClose(t *testing.T) {
+	// Given
+	inner := &closableStore{GoCacheStore: NewGoCache(cache.New(cache.NoExpiration, time.Minute))}
+
+	store := WithCompression(inner)
+
+	// When
+	err := store.Close()
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, 1, inner.closeCalls)
+	assert.Nil(t, WithCompression(NewGoCache(cache.New(cache.NoExpiration, time.Minute))).Close())
+}
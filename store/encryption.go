@@ -0,0 +1,359 @@
+package store
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	encryptionFormatVersion byte = 0x01
+
+	// UnknownEncryptionKeyID is the key identifier recorded for values which cannot be decrypted
+	// using a key of the keyring, so stored bytes are never used as metric labels
+	UnknownEncryptionKeyID = "unknown"
+)
+
+var (
+	// ErrEncryptionUnsupportedValue is returned when setting a value that is neither []byte nor string
+	// in an encryption store
+	ErrEncryptionUnsupportedValue = errors.New("only []byte and string values can be encrypted")
+	// ErrDecryption is the cause of the NotFound error returned when a value cannot be decrypted
+	ErrDecryption = errors.New("unable to decrypt value")
+)
+
+// EncryptionRecorder records encryption metrics, see metrics.Prometheus
+type EncryptionRecorder interface {
+	RecordDecryptionFailure(keyID string)
+}
+
+// Keyring holds the keys used by the encryption store. Values are encrypted using
+// the current key, and previous keys are kept to decrypt values encrypted before a rotation.
+type Keyring struct {
+	mutex     sync.RWMutex
+	currentID string
+	keys      map[string]cipher.AEAD
+}
+
+// NewKeyring creates a new keyring using the given AES key (16, 24 or 32 bytes) to encrypt values
+func NewKeyring(id string, key []byte) (*Keyring, error) {
+	keyring := &Keyring{
+		keys: make(map[string]cipher.AEAD),
+	}
+
+	if err := keyring.Rotate(id, key); err != nil {
+		return nil, err
+	}
+
+	return keyring, nil
+}
+
+// Rotate sets the given key as the key used to encrypt values.
+// The previous keys are kept to decrypt values.
+func (k *Keyring) Rotate(id string, key []byte) error {
+	if err := k.AddDecryptionKey(id, key); err != nil {
+		return err
+	}
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	k.currentID = id
+
+	return nil
+}
+
+// AddDecryptionKey adds a key only used to decrypt values
+func (k *Keyring) AddDecryptionKey(id string, key []byte) error {
+	if id == "" || len(id) > 255 || !utf8.ValidString(id) || id == UnknownEncryptionKeyID {
+		return fmt.Errorf("invalid key identifier %q", id)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	k.keys[id] = aead
+
+	return nil
+}
+
+// RemoveKey removes a key from the keyring. The current key cannot be removed.
+func (k *Keyring) RemoveKey(id string) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	if id != k.currentID {
+		delete(k.keys, id)
+	}
+}
+
+func (k *Keyring) current() (string, cipher.AEAD) {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	return k.currentID, k.keys[k.currentID]
+}
+
+func (k *Keyring) get(id string) (cipher.AEAD, bool) {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	aead, ok := k.keys[id]
+	return aead, ok
+}
+
+// EncryptionOption represents an encryption store option function
+type EncryptionOption func(o *EncryptionStore)
+
+// WithEncryptionKeyAuthentication allows authenticating the cache key along with the value,
+// so an encrypted value cannot be moved to another cache key
+func WithEncryptionKeyAuthentication() EncryptionOption {
+	return func(o *EncryptionStore) {
+		o.authenticateKey = true
+	}
+}
+
+// WithEncryptionRecorder allows recording decryption failures metrics
+func WithEncryptionRecorder(recorder EncryptionRecorder) EncryptionOption {
+	return func(o *EncryptionStore) {
+		o.recorder = recorder
+	}
+}
+
+// EncryptionStore is a store decorator encrypting values with AES-GCM before they are
+// set in the inner store. Each value is prefixed by the identifier of the key used to
+// encrypt it, so keys can be rotated. Values that cannot be decrypted are returned as misses.
+type EncryptionStore struct {
+	inner           StoreInterface
+	keyring         *Keyring
+	authenticateKey bool
+	recorder        EncryptionRecorder
+	closer          closeState
+}
+
+// WithEncryption creates a new store encrypting the values set in the given store
+func WithEncryption(inner StoreInterface, keyring *Keyring, options ...EncryptionOption) *EncryptionStore {
+	store := &EncryptionStore{
+		inner:   inner,
+		keyring: keyring,
+	}
+
+	for _, option := range options {
+		option(store)
+	}
+
+	return store
+}
+
+// Get returns data stored from a given key
+func (s *EncryptionStore) Get(ctx context.Context, key any) (any, error) {
+	if s.closer.isClosed() {
+		return nil, ErrClosed
+	}
+
+	value, err := s.inner.Get(ctx, key)
+	if err != nil {
+		return value, err
+	}
+
+	return s.decrypt(key, value)
+}
+
+// GetWithTTL returns data stored from a given key and its corresponding TTL
+func (s *EncryptionStore) GetWithTTL(ctx context.Context, key any) (any, time.Duration, error) {
+	if s.closer.isClosed() {
+		return nil, 0, ErrClosed
+	}
+
+	value, ttl, err := s.inner.GetWithTTL(ctx, key)
+	if err != nil {
+		return value, ttl, err
+	}
+
+	value, err = s.decrypt(key, value)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return value, ttl, nil
+}
+
+// Set encrypts and defines data in the inner store for given key identifier
+func (s *EncryptionStore) Set(ctx context.Context, key any, value any, options ...Option) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	var data []byte
+
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("%w: %T", ErrEncryptionUnsupportedValue, value)
+	}
+
+	encrypted, err := s.encrypt(key, data)
+	if err != nil {
+		return err
+	}
+
+	// Keep the value type so values are read back with the type they were set with
+	if _, ok := value.(string); ok {
+		return s.inner.Set(ctx, key, string(encrypted), options...)
+	}
+
+	return s.inner.Set(ctx, key, encrypted, options...)
+}
+
+// Delete removes data from the inner store for given key identifier
+func (s *EncryptionStore) Delete(ctx context.Context, key any) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	return s.inner.Delete(ctx, key)
+}
+
+// Invalidate invalidates some cache data in the inner store for given options
+func (s *EncryptionStore) Invalidate(ctx context.Context, options ...InvalidateOption) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	return s.inner.Invalidate(ctx, options...)
+}
+
+// Clear resets all data in the inner store
+func (s *EncryptionStore) Clear(ctx context.Context) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	return s.inner.Clear(ctx)
+}
+
+// IterateKeys iterates over the keys of the inner store, if it supports it
+func (s *EncryptionStore) IterateKeys(ctx context.Context, prefix string, fn func(key string) error) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	return IterateKeys(ctx, s.inner, prefix, fn)
+}
+
+// GetKeyConstraints returns the key constraints of the inner store
+func (s *EncryptionStore) GetKeyConstraints() KeyConstraints {
+	return GetKeyConstraints(s.inner)
+}
+
+// Close closes the inner store if it holds resources. Next operations return ErrClosed.
+func (s *EncryptionStore) Close() error {
+	if !s.closer.close() {
+		return nil
+	}
+
+	return closeStore(s.inner)
+}
+
+// GetType returns the inner store type
+func (s *EncryptionStore) GetType() string {
+	return s.inner.GetType()
+}
+
+// additionalData returns the data authenticated along with the value
+func (s *EncryptionStore) additionalData(key any) []byte {
+	if !s.authenticateKey {
+		return nil
+	}
+
+	return []byte(fmt.Sprint(key))
+}
+
+// encrypt returns the given data encrypted with the current key, prefixed by
+// the format version and the key identifier
+func (s *EncryptionStore) encrypt(key any, data []byte) ([]byte, error) {
+	keyID, aead := s.keyring.current()
+
+	header := make([]byte, 0, 2+len(keyID)+aead.NonceSize())
+	header = append(header, encryptionFormatVersion, byte(len(keyID)))
+	header = append(header, keyID...)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	header = append(header, nonce...)
+
+	return aead.Seal(header, nonce, data, s.additionalData(key)), nil
+}
+
+// decrypt decrypts the given value, or returns a NotFound error when it cannot be decrypted
+func (s *EncryptionStore) decrypt(key any, value any) (any, error) {
+	var data []byte
+
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return nil, s.decryptionFailure(UnknownEncryptionKeyID, fmt.Errorf("%w: unexpected %T value", ErrDecryption, value))
+	}
+
+	if len(data) < 2 || data[0] != encryptionFormatVersion || len(data) < 2+int(data[1]) {
+		return nil, s.decryptionFailure(UnknownEncryptionKeyID, fmt.Errorf("%w: invalid header", ErrDecryption))
+	}
+
+	keyID := string(data[2 : 2+int(data[1])])
+	data = data[2+int(data[1]):]
+
+	aead, ok := s.keyring.get(keyID)
+	if !ok {
+		return nil, s.decryptionFailure(UnknownEncryptionKeyID, fmt.Errorf("%w: unknown key %q", ErrDecryption, keyID))
+	}
+
+	if len(data) < aead.NonceSize() {
+		return nil, s.decryptionFailure(keyID, fmt.Errorf("%w: invalid nonce", ErrDecryption))
+	}
+
+	plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], s.additionalData(key))
+	if err != nil {
+		return nil, s.decryptionFailure(keyID, fmt.Errorf("%w: %v", ErrDecryption, err))
+	}
+
+	if _, ok := value.(string); ok {
+		return string(plaintext), nil
+	}
+
+	return plaintext, nil
+}
+
+// decryptionFailure records a decryption failure and returns it as a NotFound error.
+// The given key identifier has to be the one of a key of the keyring, or UnknownEncryptionKeyID.
+func (s *EncryptionStore) decryptionFailure(keyID string, err error) error {
+	if s.recorder != nil {
+		s.recorder.RecordDecryptionFailure(keyID)
+	}
+
+	return NotFoundWithCause(err)
+}
@@ -0,0 +1,230 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+type encryptionRecorderMock struct {
+	failures []string
+}
+
+func (r *encryptionRecorderMock) RecordDecryptionFailure(keyID string) {
+	r.failures = append(r.failures, keyID)
+}
+
+func newTestKeyring(t *testing.T, id string, b byte) *Keyring {
+	keyring, err := NewKeyring(id, bytes.Repeat([]byte{b}, 32))
+	assert.Nil(t, err)
+
+	return keyring
+}
+
+func TestNewKeyringWhenInvalidKey(t *testing.T) {
+	// When
+	keyring, err := NewKeyring("key-1", []byte("too-short"))
+
+	// Then
+	assert.Nil(t, keyring)
+	assert.Error(t, err)
+}
+
+func TestEncryptionSetAndGet(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	inner := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+
+	store := WithEncryption(inner, newTestKeyring(t, "key-1", 1))
+
+	// When
+	err := store.Set(ctx, "my-key", "my-secret-value")
+	assert.Nil(t, err)
+
+	value, err := store.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "my-secret-value", value)
+
+	stored, err := inner.Get(ctx, "my-key")
+	assert.Nil(t, err)
+	assert.NotContains(t, stored, "my-secret-value")
+	assert.Equal(t, "\x01\x05key-1", stored.(string)[:7])
+}
+
+func TestEncryptionSetWhenUnsupportedValue(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	store := WithEncryption(NewGoCache(cache.New(cache.NoExpiration, time.Minute)), newTestKeyring(t, "key-1", 1))
+
+	// When
+	err := store.Set(ctx, "my-key", 12)
+
+	// Then
+	assert.ErrorIs(t, err, ErrEncryptionUnsupportedValue)
+}
+
+func TestEncryptionGetAfterKeyRotation(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	inner := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+	keyring := newTestKeyring(t, "key-1", 1)
+
+	store := WithEncryption(inner, keyring)
+
+	err := store.Set(ctx, "my-old-key", []byte("my-old-value"))
+	assert.Nil(t, err)
+
+	// When
+	err = keyring.Rotate("key-2", bytes.Repeat([]byte{2}, 32))
+	assert.Nil(t, err)
+
+	err = store.Set(ctx, "my-new-key", []byte("my-new-value"))
+	assert.Nil(t, err)
+
+	// Then
+	oldValue, err := store.Get(ctx, "my-old-key")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("my-old-value"), oldValue)
+
+	newValue, _, err := store.GetWithTTL(ctx, "my-new-key")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("my-new-value"), newValue)
+
+	stored, err := inner.Get(ctx, "my-new-key")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("\x01\x05key-2"), stored.([]byte)[:7])
+}
+
+func TestEncryptionGetWhenKeyRemoved(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	inner := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+	keyring := newTestKeyring(t, "key-1", 1)
+	recorder := &encryptionRecorderMock{}
+
+	store := WithEncryption(inner, keyring, WithEncryptionRecorder(recorder))
+
+	err := store.Set(ctx, "my-key", []byte("my-value"))
+	assert.Nil(t, err)
+
+	assert.Nil(t, keyring.Rotate("key-2", bytes.Repeat([]byte{2}, 32)))
+	keyring.RemoveKey("key-1")
+
+	// When
+	value, err := store.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, value)
+	assert.True(t, errors.Is(err, NotFound{}))
+	assert.ErrorIs(t, err.(*NotFound).Cause(), ErrDecryption)
+	assert.Equal(t, []string{UnknownEncryptionKeyID}, recorder.failures)
+}
+
+func TestEncryptionGetWhenKeyIDIsNotInKeyring(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	inner := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+	recorder := &encryptionRecorderMock{}
+
+	store := WithEncryption(inner, newTestKeyring(t, "key-1", 1), WithEncryptionRecorder(recorder))
+
+	assert.Nil(t, inner.Set(ctx, "invalid-utf8-key", []byte("\x01\x02\xff\xfeciphertext")))
+	assert.Nil(t, inner.Set(ctx, "foreign-key", []byte("\x01\x07foreignciphertext")))
+	assert.Nil(t, inner.Set(ctx, "invalid-header", []byte("\x02")))
+
+	// When
+	_, invalidErr := store.Get(ctx, "invalid-utf8-key")
+	_, foreignErr := store.Get(ctx, "foreign-key")
+	_, headerErr := store.Get(ctx, "invalid-header")
+
+	// Then
+	assert.True(t, errors.Is(invalidErr, NotFound{}))
+	assert.True(t, errors.Is(foreignErr, NotFound{}))
+	assert.True(t, errors.Is(headerErr, NotFound{}))
+	assert.Equal(t, []string{UnknownEncryptionKeyID, UnknownEncryptionKeyID, UnknownEncryptionKeyID}, recorder.failures)
+}
+
+func TestNewKeyringWhenKeyIDIsInvalid(t *testing.T) {
+	// When
+	_, invalidErr := NewKeyring("\xff\xfe", make([]byte, 32))
+	_, unknownErr := NewKeyring(UnknownEncryptionKeyID, make([]byte, 32))
+
+	// Then
+	assert.NotNil(t, invalidErr)
+	assert.NotNil(t, unknownErr)
+}
+
+func TestEncryptionGetWhenValueMovedToAnotherKey(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	inner := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+	recorder := &encryptionRecorderMock{}
+
+	store := WithEncryption(inner, newTestKeyring(t, "key-1", 1), WithEncryptionKeyAuthentication(), WithEncryptionRecorder(recorder))
+
+	err := store.Set(ctx, "my-key", []byte("my-value"))
+	assert.Nil(t, err)
+
+	stored, err := inner.Get(ctx, "my-key")
+	assert.Nil(t, err)
+	assert.Nil(t, inner.Set(ctx, "another-key", stored))
+
+	// When
+	value, err := store.Get(ctx, "another-key")
+
+	// Then
+	assert.Nil(t, value)
+	assert.True(t, errors.Is(err, NotFound{}))
+	assert.Equal(t, []string{"key-1"}, recorder.failures)
+
+	value, err = store.Get(ctx, "my-key")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("my-value"), value)
+}
+
+func TestEncryptionGetWhenValueNotEncrypted(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	inner := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+	assert.Nil(t, inner.Set(ctx, "my-key", "my-plain-value"))
+
+	store := WithEncryption(inner, newTestKeyring(t, "key-1", 1))
+
+	// When
+	value, err := store.Get(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, value)
+	assert.True(t, errors.Is(err, NotFound{}))
+}
+
+func TestEncryptionStoreClose(t *testing.T) {
+	// Given
+	inner := &closableStore{GoCacheStore: NewGoCache(cache.New(cache.NoExpiration, time.Minute))}
+
+	keyring, err := NewKeyring("key-1", make([]byte, 32))
+	assert.Nil(t, err)
+
+	store := WithEncryption(inner, keyring)
+
+	// When
+	err = store.Close()
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, 1, inner.closeCalls)
+}
@@ -0,0 +1,157 @@
+package store
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const defaultSnapshotInterval = 5 * time.Minute
+
+// FileSnapshotterOption represents a file snapshotter option function
+type FileSnapshotterOption func(o *FileSnapshotter)
+
+// WithSnapshotInterval allows setting the duration between two periodic snapshots. Default is 5 minutes.
+func WithSnapshotInterval(interval time.Duration) FileSnapshotterOption {
+	return func(o *FileSnapshotter) {
+		o.interval = interval
+	}
+}
+
+// WithSnapshotErrorHandler allows setting a function called when a periodic snapshot fails
+func WithSnapshotErrorHandler(handler func(err error)) FileSnapshotterOption {
+	return func(o *FileSnapshotter) {
+		o.errorHandler = handler
+	}
+}
+
+// FileSnapshotter dumps a store into a file periodically, and restores it from this file
+type FileSnapshotter struct {
+	store        SnapshotterInterface
+	path         string
+	interval     time.Duration
+	errorHandler func(err error)
+
+	dumpMutex sync.Mutex
+	startOnce sync.Once
+	closeOnce sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// NewFileSnapshotter creates a new snapshotter dumping the given store into the given file
+func NewFileSnapshotter(store SnapshotterInterface, path string, options ...FileSnapshotterOption) *FileSnapshotter {
+	snapshotter := &FileSnapshotter{
+		store:    store,
+		path:     path,
+		interval: defaultSnapshotInterval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	for _, option := range options {
+		option(snapshotter)
+	}
+
+	return snapshotter
+}
+
+// Restore restores the store from the snapshot file, skipping the expired values.
+// A missing file is not an error, as there is no snapshot on the first start.
+func (s *FileSnapshotter) Restore(ctx context.Context) error {
+	file, err := os.Open(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return s.store.Restore(ctx, file)
+}
+
+// Dump writes a snapshot of the store into the file. The snapshot is written into
+// a temporary file first, so the previous snapshot is kept if the dump fails.
+func (s *FileSnapshotter) Dump(ctx context.Context) error {
+	s.dumpMutex.Lock()
+	defer s.dumpMutex.Unlock()
+
+	file, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	writer := bufio.NewWriter(file)
+	if err := s.store.Dump(ctx, writer); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), s.path)
+}
+
+// Start dumps the store into the file periodically, until the snapshotter is closed
+func (s *FileSnapshotter) Start() {
+	s.startOnce.Do(func() {
+		go s.run()
+	})
+}
+
+// Close stops the periodic snapshots and writes a last snapshot of the store
+func (s *FileSnapshotter) Close() error {
+	var err error
+
+	s.closeOnce.Do(func() {
+		close(s.stop)
+
+		started := true
+		s.startOnce.Do(func() {
+			started = false
+		})
+		if started {
+			<-s.done
+		}
+
+		err = s.Dump(context.Background())
+	})
+
+	return err
+}
+
+func (s *FileSnapshotter) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := s.Dump(context.Background()); err != nil && s.errorHandler != nil {
+				s.errorHandler(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSnapshotterDumpAndRestore(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+
+	store := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+	assert.Nil(t, store.Set(ctx, "my-key", "my-value", WithExpiration(time.Hour)))
+
+	// When
+	err := NewFileSnapshotter(store, path).Dump(ctx)
+
+	// Then
+	assert.Nil(t, err)
+
+	restoredStore := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+	assert.Nil(t, NewFileSnapshotter(restoredStore, path).Restore(ctx))
+
+	value, err := restoredStore.Get(ctx, "my-key")
+	assert.Nil(t, err)
+	assert.Equal(t, "my-value", value)
+
+	temporaryFiles, _ := filepath.Glob(path + ".tmp*")
+	assert.Empty(t, temporaryFiles)
+}
+
+func TestFileSnapshotterRestoreWhenNoSnapshot(t *testing.T) {
+	// Given
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	snapshotter := NewFileSnapshotter(NewGoCache(cache.New(cache.NoExpiration, time.Minute)), path)
+
+	// When
+	err := snapshotter.Restore(context.Background())
+
+	// Then
+	assert.Nil(t, err)
+}
+
+func TestFileSnapshotterDumpWhenFails(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	assert.Nil(t, os.WriteFile(path, []byte("previous snapshot"), 0o600))
+
+	snapshotter := NewFileSnapshotter(NewRistretto(nil), path)
+
+	// When
+	err := snapshotter.Dump(ctx)
+
+	// Then
+	assert.True(t, errors.Is(err, ErrKeyIterationNotSupported))
+
+	content, _ := os.ReadFile(path)
+	assert.Equal(t, "previous snapshot", string(content))
+}
+
+func TestFileSnapshotterStartAndClose(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+
+	store := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+	assert.Nil(t, store.Set(ctx, "my-key", "my-value"))
+
+	snapshotter := NewFileSnapshotter(store, path, WithSnapshotInterval(5*time.Millisecond))
+
+	// When
+	snapshotter.Start()
+
+	// Then
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}, time.Second, 5*time.Millisecond)
+
+	assert.Nil(t, store.Set(ctx, "my-other-key", "my-value"))
+	assert.Nil(t, snapshotter.Close())
+	assert.Nil(t, snapshotter.Close())
+
+	restoredStore := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+	assert.Nil(t, NewFileSnapshotter(restoredStore, path).Restore(ctx))
+
+	value, err := restoredStore.Get(ctx, "my-other-key")
+	assert.Nil(t, err)
+	assert.Equal(t, "my-value", value)
+}
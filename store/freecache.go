@@ -5,12 +5,17 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/coocood/freecache"
 )
 
 const (
 	// FreecacheType represents the storage type as a string value
 	FreecacheType = "freecache"
+	// FreecacheMaxKeyLength represents the maximum length of Freecache keys, in bytes
+	FreecacheMaxKeyLength = 65535
 	// FreecacheTagPattern represents the tag pattern to be used as a key in specified storage
 	FreecacheTagPattern = "freecache_tag_%s"
 )
@@ -27,10 +32,23 @@ type FreecacheClientInterface interface {
 	Clear()
 }
 
+// freecacheIteratorClient is implemented by freecache clients able to iterate over their entries
+type freecacheIteratorClient interface {
+	NewIterator() *freecache.Iterator
+}
+
 // FreecacheStore is a store for freecache
 type FreecacheStore struct {
 	client  FreecacheClientInterface
 	options *Options
+	closer  closeState
+
+	// expirations holds the expiration date of the keys set with an expiration through
+	// the store once events are subscribed, as freecache does not report evictions
+	eventsMutex sync.Mutex
+	subscriber  func(event Event)
+	expirations map[string]time.Time
+	lastSweep   time.Time
 }
 
 // NewFreecache creates a new store to freecache instance(s)
@@ -43,11 +61,16 @@ func NewFreecache(client FreecacheClientInterface, options ...Option) *Freecache
 
 // Get returns data stored from a given key. It returns the value or not found error
 func (f *FreecacheStore) Get(_ context.Context, key any) (any, error) {
+	if f.closer.isClosed() {
+		return nil, ErrClosed
+	}
+
 	var err error
 	var result any
 	if k, ok := key.(string); ok {
 		result, err = f.client.Get([]byte(k))
 		if err != nil {
+			f.missed(k)
 			return nil, NotFoundWithCause(errors.New("value not found in Freecache store"))
 		}
 		return result, err
@@ -58,9 +81,14 @@ func (f *FreecacheStore) Get(_ context.Context, key any) (any, error) {
 
 // GetWithTTL returns data stored from a given key and its corresponding TTL
 func (f *FreecacheStore) GetWithTTL(_ context.Context, key any) (any, time.Duration, error) {
+	if f.closer.isClosed() {
+		return nil, 0, ErrClosed
+	}
+
 	if k, ok := key.(string); ok {
 		result, err := f.client.Get([]byte(k))
 		if err != nil {
+			f.missed(k)
 			return nil, 0, NotFoundWithCause(errors.New("value not found in Freecache store"))
 		}
 
@@ -80,6 +108,10 @@ func (f *FreecacheStore) GetWithTTL(_ context.Context, key any) (any, time.Durat
 // the entry will not be written to the cache. expireSeconds <= 0 means no expire,
 // but it can be evicted when cache is full.
 func (f *FreecacheStore) Set(ctx context.Context, key any, value any, options ...Option) error {
+	if f.closer.isClosed() {
+		return ErrClosed
+	}
+
 	var err error
 	var val []byte
 
@@ -99,6 +131,7 @@ func (f *FreecacheStore) Set(ctx context.Context, key any, value any, options ..
 		if err != nil {
 			return fmt.Errorf("size of key: %v, value: %v, err: %v", k, len(val), err)
 		}
+		f.track(k, opts.expiration)
 		if tags := opts.tags; len(tags) > 0 {
 			f.setTags(ctx, key, tags)
 		}
@@ -140,7 +173,12 @@ func (f *FreecacheStore) getCacheKeysForTag(ctx context.Context, tagKey string)
 
 // Delete deletes an item in the cache by key and returns err or nil if a delete occurred
 func (f *FreecacheStore) Delete(_ context.Context, key any) error {
+	if f.closer.isClosed() {
+		return ErrClosed
+	}
+
 	if v, ok := key.(string); ok {
+		f.untrack(v)
 		if f.client.Del([]byte(v)) {
 			return nil
 		}
@@ -151,6 +189,10 @@ func (f *FreecacheStore) Delete(_ context.Context, key any) error {
 
 // Invalidate invalidates some cache data in freecache for given options
 func (f *FreecacheStore) Invalidate(ctx context.Context, options ...InvalidateOption) error {
+	if f.closer.isClosed() {
+		return ErrClosed
+	}
+
 	opts := ApplyInvalidateOptions(options...)
 
 	if tags := opts.tags; len(tags) > 0 {
@@ -177,11 +219,166 @@ func (f *FreecacheStore) Invalidate(ctx context.Context, options ...InvalidateOp
 
 // Clear resets all data in the store
 func (f *FreecacheStore) Clear(_ context.Context) error {
+	if f.closer.isClosed() {
+		return ErrClosed
+	}
+
 	f.client.Clear()
+
+	f.eventsMutex.Lock()
+	if f.expirations != nil {
+		f.expirations = make(map[string]time.Time)
+	}
+	f.eventsMutex.Unlock()
+
+	return nil
+}
+
+// IterateKeys calls the given function with every unexpired key starting with the given prefix.
+// The client has to be able to iterate over its entries, as *freecache.Cache is,
+// otherwise ErrKeyIterationNotSupported is returned.
+func (f *FreecacheStore) IterateKeys(ctx context.Context, prefix string, fn func(key string) error) error {
+	if f.closer.isClosed() {
+		return ErrClosed
+	}
+
+	client, ok := f.client.(freecacheIteratorClient)
+	if !ok {
+		return ErrKeyIterationNotSupported
+	}
+
+	// Keys are listed first, so the given function is able to update the store
+	var keys []string
+	iterator := client.NewIterator()
+	for entry := iterator.Next(); entry != nil; entry = iterator.Next() {
+		if key := string(entry.Key); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := fn(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close marks the store as closed. As the client is given by the caller, it is not closed,
+// freecache clients holding no resources to release anyway. Next operations return ErrClosed.
+func (f *FreecacheStore) Close() error {
+	f.closer.close()
 	return nil
 }
 
+// SubscribeEvents reports the evictions and the expirations of the keys set with an
+// expiration through the store, as freecache does not report them: a key read as missing
+// before its expiration has been evicted. Expired keys that are not read again are
+// reported when they are swept (at most once a minute, on writes).
+func (f *FreecacheStore) SubscribeEvents(fn func(event Event)) bool {
+	if f.closer.isClosed() {
+		return false
+	}
+
+	f.eventsMutex.Lock()
+	defer f.eventsMutex.Unlock()
+
+	f.subscriber = fn
+	f.expirations = make(map[string]time.Time)
+	f.lastSweep = time.Now()
+
+	return true
+}
+
+// track records the expiration date of the given key when events are subscribed
+func (f *FreecacheStore) track(key string, expiration time.Duration) {
+	if strings.HasPrefix(key, strings.TrimSuffix(FreecacheTagPattern, "%s")) {
+		return
+	}
+
+	now := time.Now()
+
+	f.eventsMutex.Lock()
+	if f.expirations == nil {
+		f.eventsMutex.Unlock()
+		return
+	}
+
+	if expiration > 0 {
+		f.expirations[key] = now.Add(expiration)
+	} else {
+		delete(f.expirations, key)
+	}
+	f.eventsMutex.Unlock()
+
+	f.sweep(now)
+}
+
+// untrack stops recording the expiration date of the given key
+func (f *FreecacheStore) untrack(key string) {
+	f.eventsMutex.Lock()
+	delete(f.expirations, key)
+	f.eventsMutex.Unlock()
+}
+
+// missed reports the eviction or the expiration of the given key, read as missing
+func (f *FreecacheStore) missed(key string) {
+	now := time.Now()
+
+	f.eventsMutex.Lock()
+	expiresAt, ok := f.expirations[key]
+	delete(f.expirations, key)
+	subscriber := f.subscriber
+	f.eventsMutex.Unlock()
+
+	if !ok {
+		return
+	}
+
+	eventType := EventEvict
+	if !expiresAt.After(now) {
+		eventType = EventExpire
+	}
+
+	subscriber(Event{Type: eventType, Key: key, Source: FreecacheType, Time: now})
+}
+
+// sweep reports the expiration of the keys expired since the last sweep, so recorded
+// expiration dates do not accumulate for keys that are never read again
+func (f *FreecacheStore) sweep(now time.Time) {
+	f.eventsMutex.Lock()
+	if now.Sub(f.lastSweep) < defaultHookExpirySweepInterval {
+		f.eventsMutex.Unlock()
+		return
+	}
+	f.lastSweep = now
+
+	var expired []string
+	for key, expiresAt := range f.expirations {
+		if !expiresAt.After(now) {
+			expired = append(expired, key)
+			delete(f.expirations, key)
+		}
+	}
+	subscriber := f.subscriber
+	f.eventsMutex.Unlock()
+
+	for _, key := range expired {
+		subscriber(Event{Type: EventExpire, Key: key, Source: FreecacheType, Time: now})
+	}
+}
+
 // GetType returns the store type
 func (f *FreecacheStore) GetType() string {
 	return FreecacheType
 }
+
+// GetKeyConstraints returns the constraints of Freecache keys: at most 65535 bytes
+func (f *FreecacheStore) GetKeyConstraints() KeyConstraints {
+	return KeyConstraints{MaxLength: FreecacheMaxKeyLength}
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/coocood/freecache"
 	mocksStore "github.com/eko/gocache/v3/test/mocks/store/clients"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -485,6 +486,45 @@ func TestFreecacheClearAll(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestFreecacheIterateKeys(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	store := NewFreecache(freecache.NewCache(1024 * 1024))
+	assert.Nil(t, store.Set(ctx, "svc-a:one", []byte("value")))
+	assert.Nil(t, store.Set(ctx, "svc-a:two", []byte("value")))
+	assert.Nil(t, store.Set(ctx, "svc-b:one", []byte("value")))
+
+	var keys []string
+
+	// When
+	err := store.IterateKeys(ctx, "svc-a:", func(key string) error {
+		keys = append(keys, key)
+		return nil
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"svc-a:one", "svc-a:two"}, keys)
+}
+
+func TestFreecacheIterateKeysWhenClientCannotIterate(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	client := mocksStore.NewMockFreecacheClientInterface(ctrl)
+
+	store := NewFreecache(client)
+
+	// When
+	err := store.IterateKeys(context.Background(), "", func(key string) error {
+		return nil
+	})
+
+	// Then
+	assert.Equal(t, ErrKeyIterationNotSupported, err)
+}
+
 func TestFreecacheGetType(t *testing.T) {
 	// Given
 	ctrl := gomock.NewController(t)
@@ -4,8 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/patrickmn/go-cache"
 )
 
 const (
@@ -22,6 +26,7 @@ type GoCacheClientInterface interface {
 	Set(k string, x any, d time.Duration)
 	Delete(k string)
 	Flush()
+	Items() map[string]cache.Item
 }
 
 // GoCacheStore is a store for GoCache (memory) library
@@ -29,6 +34,21 @@ type GoCacheStore struct {
 	mu      sync.RWMutex
 	client  GoCacheClientInterface
 	options *Options
+
+	deletingMu sync.Mutex
+	deleting   map[string]int
+
+	evictedMu        sync.Mutex
+	evictedInstalled bool
+	onEvicted        func(key string, value any)
+	subscriber       func(event Event)
+
+	closer closeState
+}
+
+// goCacheEvictionNotifier is implemented by go-cache clients able to report removed items
+type goCacheEvictionNotifier interface {
+	OnEvicted(f func(string, any))
 }
 
 // NewGoCache creates a new store to GoCache (memory) library instance
@@ -41,6 +61,10 @@ func NewGoCache(client GoCacheClientInterface, options ...Option) *GoCacheStore
 
 // Get returns data stored from a given key
 func (s *GoCacheStore) Get(_ context.Context, key any) (any, error) {
+	if s.closer.isClosed() {
+		return nil, ErrClosed
+	}
+
 	var err error
 	keyStr := key.(string)
 	value, exists := s.client.Get(keyStr)
@@ -53,6 +77,10 @@ func (s *GoCacheStore) Get(_ context.Context, key any) (any, error) {
 
 // GetWithTTL returns data stored from a given key and its corresponding TTL
 func (s *GoCacheStore) GetWithTTL(_ context.Context, key any) (any, time.Duration, error) {
+	if s.closer.isClosed() {
+		return nil, 0, ErrClosed
+	}
+
 	data, t, exists := s.client.GetWithExpiration(key.(string))
 	if !exists {
 		return data, 0, NotFoundWithCause(errors.New("value not found in GoCache store"))
@@ -63,11 +91,12 @@ func (s *GoCacheStore) GetWithTTL(_ context.Context, key any) (any, time.Duratio
 
 // Set defines data in GoCache memoey cache for given key identifier
 func (s *GoCacheStore) Set(ctx context.Context, key any, value any, options ...Option) error {
-	opts := ApplyOptions(options...)
-	if opts == nil {
-		opts = s.options
+	if s.closer.isClosed() {
+		return ErrClosed
 	}
 
+	opts := ApplyOptions(options...).applyExpirationJitter()
+
 	s.client.Set(key.(string), value, opts.expiration)
 
 	if tags := opts.tags; len(tags) > 0 {
@@ -109,12 +138,37 @@ func (s *GoCacheStore) setTags(ctx context.Context, key any, tags []string) {
 
 // Delete removes data in GoCache memoey cache for given key identifier
 func (s *GoCacheStore) Delete(_ context.Context, key any) error {
-	s.client.Delete(key.(string))
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	keyStr := key.(string)
+
+	s.deletingMu.Lock()
+	if s.deleting != nil {
+		s.deleting[keyStr]++
+	}
+	s.deletingMu.Unlock()
+
+	s.client.Delete(keyStr)
+
+	s.deletingMu.Lock()
+	if s.deleting != nil {
+		if s.deleting[keyStr]--; s.deleting[keyStr] <= 0 {
+			delete(s.deleting, keyStr)
+		}
+	}
+	s.deletingMu.Unlock()
+
 	return nil
 }
 
 // Invalidate invalidates some cache data in GoCache memoey cache for given options
 func (s *GoCacheStore) Invalidate(ctx context.Context, options ...InvalidateOption) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
 	opts := ApplyInvalidateOptions(options...)
 
 	if tags := opts.tags; len(tags) > 0 {
@@ -141,6 +195,14 @@ func (s *GoCacheStore) Invalidate(ctx context.Context, options ...InvalidateOpti
 	return nil
 }
 
+// Close marks the store as closed. As the client is given by the caller, it is not closed:
+// the go-cache janitor goroutine is stopped by go-cache once its client is garbage collected.
+// Next operations return ErrClosed.
+func (s *GoCacheStore) Close() error {
+	s.closer.close()
+	return nil
+}
+
 // GetType returns the store type
 func (s *GoCacheStore) GetType() string {
 	return GoCacheType
@@ -148,6 +210,178 @@ func (s *GoCacheStore) GetType() string {
 
 // Clear resets all data in the store
 func (s *GoCacheStore) Clear(_ context.Context) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
 	s.client.Flush()
 	return nil
 }
+
+// IterateKeys calls the given function with every unexpired key starting with the given prefix
+func (s *GoCacheStore) IterateKeys(_ context.Context, prefix string, fn func(key string) error) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	for key := range s.client.Items() {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		if err := fn(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SubscribeEvents forwards the expirations reported by the go-cache client, which
+// has to be a *cache.Cache. Keys deleted through the store are not reported.
+// The OnEvicted callback of the client is replaced (go-cache does not allow reading it):
+// use GoCacheStore.OnEvicted to be called on evictions too.
+func (s *GoCacheStore) SubscribeEvents(fn func(event Event)) bool {
+	if s.closer.isClosed() {
+		return false
+	}
+
+	s.deletingMu.Lock()
+	if s.deleting == nil {
+		s.deleting = make(map[string]int)
+	}
+	s.deletingMu.Unlock()
+
+	return s.handleEvictions(func() {
+		s.subscriber = fn
+	})
+}
+
+// OnEvicted sets a function called with the key and the value of the items removed from
+// the go-cache client, which has to be a *cache.Cache. It returns false otherwise.
+// It has to be used instead of the OnEvicted method of the client when events are
+// subscribed (see SubscribeEvents), both being then called.
+func (s *GoCacheStore) OnEvicted(f func(key string, value any)) bool {
+	return s.handleEvictions(func() {
+		s.onEvicted = f
+	})
+}
+
+// handleEvictions calls the given function to update the eviction callbacks, and sets
+// the OnEvicted callback of the client calling them if not done yet
+func (s *GoCacheStore) handleEvictions(update func()) bool {
+	client, ok := s.client.(goCacheEvictionNotifier)
+	if !ok {
+		return false
+	}
+
+	s.evictedMu.Lock()
+	defer s.evictedMu.Unlock()
+
+	update()
+
+	if !s.evictedInstalled {
+		s.evictedInstalled = true
+		client.OnEvicted(s.evicted)
+	}
+
+	return true
+}
+
+// evicted calls the eviction callback and forwards the expiration of the given item
+// to the subscriber, unless it is a tag or it is being deleted through the store
+func (s *GoCacheStore) evicted(key string, value any) {
+	s.evictedMu.Lock()
+	onEvicted, subscriber := s.onEvicted, s.subscriber
+	s.evictedMu.Unlock()
+
+	if onEvicted != nil {
+		onEvicted(key, value)
+	}
+
+	if subscriber == nil || strings.HasPrefix(key, strings.TrimSuffix(GoCacheTagPattern, "%s")) {
+		return
+	}
+
+	s.deletingMu.Lock()
+	_, deleting := s.deleting[key]
+	s.deletingMu.Unlock()
+
+	if deleting {
+		return
+	}
+
+	subscriber(Event{
+		Type:   EventExpire,
+		Key:    key,
+		Value:  value,
+		Source: GoCacheType,
+		Time:   time.Now(),
+	})
+}
+
+// Dump writes every unexpired value of the store into the given writer, with its
+// expiration date and its tags. See SnapshotterInterface.
+func (s *GoCacheStore) Dump(ctx context.Context, w io.Writer) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	tagPrefix := strings.TrimSuffix(GoCacheTagPattern, "%s")
+	items := s.client.Items()
+
+	keysByTag := make(map[string][]string)
+	for key, item := range items {
+		if !strings.HasPrefix(key, tagPrefix) {
+			continue
+		}
+
+		if cacheKeys, ok := item.Object.(map[string]struct{}); ok {
+			tag := strings.TrimPrefix(key, tagPrefix)
+			for cacheKey := range cacheKeys {
+				keysByTag[tag] = append(keysByTag[tag], cacheKey)
+			}
+		}
+	}
+	tagsByKey := snapshotTags(keysByTag)
+
+	writer, err := newSnapshotWriter(w, GoCacheType)
+	if err != nil {
+		return err
+	}
+
+	for key, item := range items {
+		if strings.HasPrefix(key, tagPrefix) {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		entry := snapshotEntry{
+			Key:   key,
+			Value: item.Object,
+			Tags:  tagsByKey[key],
+		}
+		if item.Expiration > 0 {
+			entry.ExpiresAt = time.Unix(0, item.Expiration)
+		}
+
+		if err := writer.write(entry); err != nil {
+			return err
+		}
+	}
+
+	return writer.close()
+}
+
+// Restore sets the values of the given snapshot into the store, skipping the expired ones.
+// See SnapshotterInterface.
+func (s *GoCacheStore) Restore(ctx context.Context, r io.Reader) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	return restoreSnapshot(ctx, s, r)
+}
@@ -1,8 +1,11 @@
 package store
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"math/rand"
 	"testing"
 	"time"
 
@@ -135,6 +138,33 @@ func TestGoCacheSet(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestGoCacheSetWithExpirationJitter(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	cacheKey := "my-key"
+	cacheValue := "my-cache-value"
+
+	options := ApplyOptions(WithExpirationJitter(time.Minute))
+	SetJitterSource(rand.NewSource(42))
+	expectedExpiration := options.JitteredExpiration(time.Hour)
+	SetJitterSource(rand.NewSource(42))
+
+	client := mocksStore.NewMockGoCacheClientInterface(ctrl)
+	client.EXPECT().Set(cacheKey, cacheValue, expectedExpiration)
+
+	store := NewGoCache(client)
+
+	// When
+	err := store.Set(ctx, cacheKey, cacheValue, WithExpiration(time.Hour), WithExpirationJitter(time.Minute))
+
+	// Then
+	assert.Nil(t, err)
+	assert.NotEqual(t, time.Hour, expectedExpiration)
+}
+
 func TestGoCacheSetWhenNoOptionsGiven(t *testing.T) {
 	// Given
 	ctrl := gomock.NewController(t)
@@ -156,6 +186,27 @@ func TestGoCacheSetWhenNoOptionsGiven(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestGoCacheSetIgnoresStoreOptions(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	cacheKey := "my-key"
+	cacheValue := "my-cache-value"
+
+	client := mocksStore.NewMockGoCacheClientInterface(ctrl)
+	client.EXPECT().Set(cacheKey, cacheValue, 0*time.Second)
+
+	store := NewGoCache(client, WithExpiration(time.Hour))
+
+	// When
+	err := store.Set(ctx, cacheKey, cacheValue)
+
+	// Then
+	assert.Nil(t, err)
+}
+
 func TestGoCacheSetWithTags(t *testing.T) {
 	// Given
 	ctrl := gomock.NewController(t)
@@ -344,3 +395,58 @@ func TestGoCacheInvalidateConcurrency(t *testing.T) {
 
 	}
 }
+
+func TestGoCacheIterateKeys(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	store := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+	_ = store.Set(ctx, "svc-a:one", "value")
+	_ = store.Set(ctx, "svc-a:two", "value")
+	_ = store.Set(ctx, "svc-b:one", "value")
+
+	var keys []string
+
+	// When
+	err := store.IterateKeys(ctx, "svc-a:", func(key string) error {
+		keys = append(keys, key)
+		return nil
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"svc-a:one", "svc-a:two"}, keys)
+}
+
+func TestGoCacheDumpAndRestore(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	store := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+	assert.Nil(t, store.Set(ctx, "my-key", "my-value"))
+	assert.Nil(t, store.Set(ctx, "expiring-key", 42, WithExpiration(time.Hour), WithTags([]string{"my-tag"})))
+
+	var buffer bytes.Buffer
+
+	// When
+	err := store.Dump(ctx, &buffer)
+
+	// Then
+	assert.Nil(t, err)
+
+	restoredStore := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+	assert.Nil(t, restoredStore.Restore(ctx, &buffer))
+
+	value, err := restoredStore.Get(ctx, "my-key")
+	assert.Nil(t, err)
+	assert.Equal(t, "my-value", value)
+
+	value, ttl, err := restoredStore.GetWithTTL(ctx, "expiring-key")
+	assert.Nil(t, err)
+	assert.Equal(t, 42, value)
+	assert.InDelta(t, time.Hour, ttl, float64(time.Minute))
+
+	assert.Nil(t, restoredStore.Invalidate(ctx, WithInvalidateTags([]string{"my-tag"})))
+	_, err = restoredStore.Get(ctx, "expiring-key")
+	assert.True(t, errors.Is(err, NotFound{}))
+}
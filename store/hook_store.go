@@ -0,0 +1,288 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const defaultHookExpirySweepInterval = time.Minute
+
+// EventSourceInterface represents stores able to forward the evictions and expirations
+// reported by their client. SubscribeEvents returns false when the client does not report them.
+type EventSourceInterface interface {
+	SubscribeEvents(fn func(event Event)) bool
+}
+
+// HookStoreOption represents a hook store option function
+type HookStoreOption func(o *HookStore)
+
+// WithHookNativeExpirations disables the synthesised expire events, when expirations
+// are already forwarded from the client (see BigcacheOnRemoveWithReason)
+func WithHookNativeExpirations() HookStoreOption {
+	return func(o *HookStore) {
+		o.synthesizeExpirations = false
+	}
+}
+
+// HookStore is a store decorator emitting events to hooks for the operations made on the inner store
+type HookStore struct {
+	inner  StoreInterface
+	hooks  *Hooks
+	closer closeState
+
+	synthesizeExpirations bool
+	mu                    sync.Mutex
+	expirations           map[string]expiringKey
+	lastSweep             time.Time
+	now                   func() time.Time
+}
+
+type expiringKey struct {
+	key       any
+	expiresAt time.Time
+}
+
+// WithHooks creates a new store emitting set, delete, hit and miss events to the given hooks.
+// Evictions and expirations are forwarded from the inner store when it implements
+// EventSourceInterface. Otherwise, expire events are synthesised for keys set with a
+// WithExpiration option, when they are read after it or when expired keys are swept.
+func WithHooks(inner StoreInterface, hooks *Hooks, options ...HookStoreOption) *HookStore {
+	store := &HookStore{
+		inner:                 inner,
+		hooks:                 hooks,
+		synthesizeExpirations: true,
+		expirations:           make(map[string]expiringKey),
+		now:                   time.Now,
+	}
+
+	for _, option := range options {
+		option(store)
+	}
+
+	if source, ok := inner.(EventSourceInterface); ok && source.SubscribeEvents(hooks.Emit) {
+		store.synthesizeExpirations = false
+	}
+
+	store.lastSweep = store.now()
+
+	return store
+}
+
+// Get returns data stored from a given key
+func (s *HookStore) Get(ctx context.Context, key any) (any, error) {
+	if s.closer.isClosed() {
+		return nil, ErrClosed
+	}
+
+	value, err := s.inner.Get(ctx, key)
+	s.emitRead(key, value, err)
+
+	return value, err
+}
+
+// GetWithTTL returns data stored from a given key and its corresponding TTL
+func (s *HookStore) GetWithTTL(ctx context.Context, key any) (any, time.Duration, error) {
+	if s.closer.isClosed() {
+		return nil, 0, ErrClosed
+	}
+
+	value, ttl, err := s.inner.GetWithTTL(ctx, key)
+	s.emitRead(key, value, err)
+
+	return value, ttl, err
+}
+
+// Set defines data in the inner store for given key identifier
+func (s *HookStore) Set(ctx context.Context, key any, value any, options ...Option) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	if err := s.inner.Set(ctx, key, value, options...); err != nil {
+		return err
+	}
+
+	if s.synthesizeExpirations {
+		s.track(key, applyOptionsWithDefault(defaultOptions(s.inner), options...).expiration)
+		s.sweep()
+	}
+
+	s.emit(EventSet, key, value)
+
+	return nil
+}
+
+// Delete removes data from the inner store for given key identifier
+func (s *HookStore) Delete(ctx context.Context, key any) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	if err := s.inner.Delete(ctx, key); err != nil {
+		return err
+	}
+
+	if s.synthesizeExpirations {
+		s.untrack(key)
+	}
+
+	s.emit(EventDelete, key, nil)
+
+	return nil
+}
+
+// Invalidate invalidates some cache data in the inner store for given options
+func (s *HookStore) Invalidate(ctx context.Context, options ...InvalidateOption) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	return s.inner.Invalidate(ctx, options...)
+}
+
+// Clear resets all data in the inner store
+func (s *HookStore) Clear(ctx context.Context) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	if err := s.inner.Clear(ctx); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.expirations = make(map[string]expiringKey)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// IterateKeys iterates over the keys of the inner store, if it supports it
+func (s *HookStore) IterateKeys(ctx context.Context, prefix string, fn func(key string) error) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	return IterateKeys(ctx, s.inner, prefix, fn)
+}
+
+// GetKeyConstraints returns the key constraints of the inner store
+func (s *HookStore) GetKeyConstraints() KeyConstraints {
+	return GetKeyConstraints(s.inner)
+}
+
+// Close closes the inner store if it holds resources. As hooks can be shared, they are not closed. Next operations return ErrClosed.
+func (s *HookStore) Close() error {
+	if !s.closer.close() {
+		return nil
+	}
+
+	return closeStore(s.inner)
+}
+
+// GetType returns the inner store type
+func (s *HookStore) GetType() string {
+	return s.inner.GetType()
+}
+
+// GetHooks returns the hooks events are emitted to
+func (s *HookStore) GetHooks() *Hooks {
+	return s.hooks
+}
+
+func (s *HookStore) emit(eventType EventType, key any, value any) {
+	s.hooks.Emit(Event{
+		Type:   eventType,
+		Key:    key,
+		Value:  value,
+		Source: s.inner.GetType(),
+		Time:   s.now(),
+	})
+}
+
+// emitRead emits a hit or a miss event depending on the result of a read. When the key
+// has been set with an expiration that passed, an expire event is emitted first.
+func (s *HookStore) emitRead(key any, value any, err error) {
+	if err == nil {
+		s.emit(EventHit, key, value)
+	} else if errors.Is(err, NotFound{}) {
+		if s.synthesizeExpirations {
+			if expired, ok := s.untrack(key); ok && !expired.expiresAt.After(s.now()) {
+				s.emit(EventExpire, key, nil)
+			}
+		}
+		s.emit(EventMiss, key, nil)
+	}
+
+	if s.synthesizeExpirations {
+		s.sweep()
+	}
+}
+
+// track records the expiration date of the given key, if any
+func (s *HookStore) track(key any, expiration time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	trackedKey := hookStoreKey(key)
+	if expiration <= 0 {
+		delete(s.expirations, trackedKey)
+		return
+	}
+
+	s.expirations[trackedKey] = expiringKey{key: key, expiresAt: s.now().Add(expiration)}
+}
+
+// untrack stops recording the expiration date of the given key and returns it
+func (s *HookStore) untrack(key any) (expiringKey, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	trackedKey := hookStoreKey(key)
+	expiring, ok := s.expirations[trackedKey]
+	delete(s.expirations, trackedKey)
+
+	return expiring, ok
+}
+
+// sweep emits expire events for the keys expired since the last sweep, so recorded
+// expiration dates do not accumulate for keys that are never read again
+func (s *HookStore) sweep() {
+	now := s.now()
+
+	s.mu.Lock()
+	if now.Sub(s.lastSweep) < defaultHookExpirySweepInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastSweep = now
+
+	var expired []expiringKey
+	for trackedKey, expiring := range s.expirations {
+		if !expiring.expiresAt.After(now) {
+			expired = append(expired, expiring)
+			delete(s.expirations, trackedKey)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, expiring := range expired {
+		s.hooks.Emit(Event{
+			Type:   EventExpire,
+			Key:    expiring.key,
+			Source: s.inner.GetType(),
+			Time:   expiring.expiresAt,
+		})
+	}
+}
+
+func hookStoreKey(key any) string {
+	if stringKey, ok := key.(string); ok {
+		return stringKey
+	}
+
+	return fmt.Sprint(key)
+}
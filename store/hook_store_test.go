@@ -0,0 +1,275 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mocksStore "github.com/eko/gocache/v3/test/mocks/store/clients"
+	"github.com/golang/mock/gomock"
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func recordAll(hooks *Hooks, recorder *eventRecorder) {
+	for _, eventType := range []EventType{EventSet, EventDelete, EventEvict, EventExpire, EventHit, EventMiss} {
+		hooks.On(eventType, recorder.record)
+	}
+}
+
+func TestHookStoreEmitsEvents(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	hooks := NewHooks()
+	recorder := &eventRecorder{}
+	recordAll(hooks, recorder)
+
+	store := WithHooks(NewGoCache(cache.New(cache.NoExpiration, time.Minute)), hooks)
+
+	// When
+	assert.Nil(t, store.Set(ctx, "my-key", "my-value"))
+
+	value, err := store.Get(ctx, "my-key")
+	assert.Nil(t, err)
+	assert.Equal(t, "my-value", value)
+
+	assert.Nil(t, store.Delete(ctx, "my-key"))
+
+	_, _, err = store.GetWithTTL(ctx, "my-key")
+	assert.True(t, errors.Is(err, NotFound{}))
+
+	// Then
+	assert.Nil(t, hooks.Close())
+
+	assert.Equal(t, []EventType{EventSet, EventHit, EventDelete, EventMiss}, recorder.types())
+	assert.Equal(t, "my-value", recorder.events[1].Value)
+	assert.Equal(t, GoCacheType, recorder.events[1].Source)
+}
+
+func TestHookStoreForwardsGoCacheExpirations(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	hooks := NewHooks()
+	expired := make(chan Event, 1)
+	hooks.OnExpire(func(event Event) {
+		expired <- event
+	})
+
+	store := WithHooks(NewGoCache(cache.New(cache.NoExpiration, 5*time.Millisecond)), hooks)
+
+	assert.Nil(t, store.Set(ctx, "deleted-key", "my-value", WithExpiration(time.Hour)))
+	assert.Nil(t, store.Delete(ctx, "deleted-key"))
+
+	// When
+	assert.Nil(t, store.Set(ctx, "my-key", "my-value", WithExpiration(time.Millisecond), WithTags([]string{"my-tag"})))
+
+	// Then
+	select {
+	case event := <-expired:
+		assert.Equal(t, "my-key", event.Key)
+		assert.Equal(t, "my-value", event.Value)
+		assert.Equal(t, GoCacheType, event.Source)
+	case <-time.After(time.Second):
+		t.Fatal("expire event has not been emitted")
+	}
+
+	assert.Nil(t, hooks.Close())
+	assert.Empty(t, expired)
+}
+
+func TestHookStoreKeepsGoCacheOnEvictedCallback(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	client := cache.New(cache.NoExpiration, time.Minute)
+	goCacheStore := NewGoCache(client)
+
+	var evictedKeys []string
+	assert.True(t, goCacheStore.OnEvicted(func(key string, value any) {
+		evictedKeys = append(evictedKeys, key)
+	}))
+
+	hooks := NewHooks()
+	recorder := &eventRecorder{}
+	hooks.OnExpire(recorder.record)
+
+	store := WithHooks(goCacheStore, hooks)
+
+	assert.Nil(t, store.Set(ctx, "deleted-key", "my-value"))
+	assert.Nil(t, store.Set(ctx, "my-key", "my-value", WithExpiration(time.Millisecond)))
+
+	// When
+	assert.Nil(t, store.Delete(ctx, "deleted-key"))
+	time.Sleep(5 * time.Millisecond)
+	client.DeleteExpired()
+
+	// Then
+	assert.Nil(t, hooks.Close())
+	assert.Equal(t, []string{"deleted-key", "my-key"}, evictedKeys)
+	assert.Len(t, recorder.events, 1)
+	assert.Equal(t, "my-key", recorder.events[0].Key)
+}
+
+func TestHookStoreTracksExpirationsWithStoreDefaultOptions(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+
+	client := mocksStore.NewMockRistrettoClientInterface(ctrl)
+	client.EXPECT().SetWithTTL("my-key", "my-value", int64(0), time.Second).Return(true)
+
+	store := WithHooks(NewRistretto(client, WithExpiration(time.Second)), NewHooks())
+
+	// When
+	assert.Nil(t, store.Set(ctx, "my-key", "my-value"))
+
+	// Then
+	assert.Contains(t, store.expirations, "my-key")
+}
+
+func TestHookStoreForwardsFreecacheEvictions(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+
+	client := mocksStore.NewMockFreecacheClientInterface(ctrl)
+	client.EXPECT().Set([]byte("evicted-key"), []byte("my-value"), 3600).Return(nil)
+	client.EXPECT().Set([]byte("deleted-key"), []byte("my-value"), 3600).Return(nil)
+	client.EXPECT().Del([]byte("deleted-key")).Return(true)
+	client.EXPECT().Get([]byte("evicted-key")).Return(nil, errors.New("not found"))
+	client.EXPECT().Get([]byte("deleted-key")).Return(nil, errors.New("not found"))
+
+	hooks := NewHooks()
+	recorder := &eventRecorder{}
+	recordAll(hooks, recorder)
+
+	store := WithHooks(NewFreecache(client), hooks)
+
+	assert.Nil(t, store.Set(ctx, "evicted-key", []byte("my-value"), WithExpiration(time.Hour)))
+	assert.Nil(t, store.Set(ctx, "deleted-key", []byte("my-value"), WithExpiration(time.Hour)))
+	assert.Nil(t, store.Delete(ctx, "deleted-key"))
+
+	// When
+	_, evictedErr := store.Get(ctx, "evicted-key")
+	_, deletedErr := store.Get(ctx, "deleted-key")
+
+	// Then
+	assert.True(t, errors.Is(evictedErr, NotFound{}))
+	assert.True(t, errors.Is(deletedErr, NotFound{}))
+
+	assert.Nil(t, hooks.Close())
+	assert.Equal(t, []EventType{EventSet, EventSet, EventDelete, EventEvict, EventMiss, EventMiss}, recorder.types())
+	assert.Equal(t, "evicted-key", recorder.events[3].Key)
+	assert.Equal(t, FreecacheType, recorder.events[3].Source)
+}
+
+func TestHookStoreSynthesizesExpirationsOnRead(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+
+	client := mocksStore.NewMockRistrettoClientInterface(ctrl)
+	client.EXPECT().SetWithTTL("my-key", "my-value", int64(0), time.Second).Return(true)
+	client.EXPECT().Get("my-key").Return(nil, false)
+
+	hooks := NewHooks()
+	recorder := &eventRecorder{}
+	recordAll(hooks, recorder)
+
+	now := time.Now()
+	store := WithHooks(NewRistretto(client), hooks)
+	store.now = func() time.Time { return now }
+
+	assert.Nil(t, store.Set(ctx, "my-key", "my-value", WithExpiration(time.Second)))
+
+	// When
+	now = now.Add(2 * time.Second)
+	_, err := store.Get(ctx, "my-key")
+
+	// Then
+	assert.True(t, errors.Is(err, NotFound{}))
+
+	assert.Nil(t, hooks.Close())
+	assert.Equal(t, []EventType{EventSet, EventExpire, EventMiss}, recorder.types())
+}
+
+func TestHookStoreSynthesizesExpirationsOnSweep(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+
+	client := mocksStore.NewMockRistrettoClientInterface(ctrl)
+	client.EXPECT().SetWithTTL("my-key", "my-value", int64(0), time.Second).Return(true)
+	client.EXPECT().SetWithTTL("my-other-key", "my-value", int64(0), time.Duration(0)).Return(true)
+
+	hooks := NewHooks()
+	recorder := &eventRecorder{}
+	hooks.OnExpire(recorder.record)
+
+	now := time.Now()
+	store := WithHooks(NewRistretto(client), hooks)
+	store.now = func() time.Time { return now }
+
+	assert.Nil(t, store.Set(ctx, "my-key", "my-value", WithExpiration(time.Second)))
+
+	// When
+	now = now.Add(defaultHookExpirySweepInterval + time.Second)
+	assert.Nil(t, store.Set(ctx, "my-other-key", "my-value"))
+
+	// Then
+	assert.Nil(t, hooks.Close())
+	assert.Len(t, recorder.events, 1)
+	assert.Equal(t, "my-key", recorder.events[0].Key)
+	assert.Empty(t, store.expirations)
+}
+
+func TestHookStoreWithNativeExpirations(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+
+	client := mocksStore.NewMockBigcacheClientInterface(ctrl)
+	client.EXPECT().Set("my-key", []byte("my-value")).Return(nil)
+
+	// When
+	store := WithHooks(NewBigcache(client), NewHooks(), WithHookNativeExpirations())
+
+	// Then
+	assert.Nil(t, store.Set(ctx, "my-key", []byte("my-value"), WithExpiration(time.Second)))
+	assert.Empty(t, store.expirations)
+}
+
+func TestHookStoreWhenSetFails(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+
+	client := mocksStore.NewMockRistrettoClientInterface(ctrl)
+	client.EXPECT().SetWithTTL("my-key", "my-value", int64(0), time.Duration(0)).Return(false)
+
+	hooks := NewHooks()
+	recorder := &eventRecorder{}
+	recordAll(hooks, recorder)
+
+	store := WithHooks(NewRistretto(client), hooks)
+
+	// When
+	err := store.Set(ctx, "my-key", "my-value")
+
+	// Then
+	assert.NotNil(t, err)
+
+	assert.Nil(t, hooks.Close())
+	assert.Empty(t, recorder.types())
+}
+
+func TestHookStoreGetType(t *testing.T) {
+	// Given
+	store := WithHooks(NewGoCache(cache.New(cache.NoExpiration, time.Minute)), NewHooks())
+
+	// When - Then
+	assert.Equal(t, GoCacheType, store.GetType())
+}
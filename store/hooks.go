@@ -0,0 +1,257 @@
+package store
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventType represents the type of a cache event
+type EventType int
+
+const (
+	// EventSet is emitted when a value is set
+	EventSet EventType = iota + 1
+	// EventDelete is emitted when a value is deleted
+	EventDelete
+	// EventEvict is emitted when a value is removed by the store to make room for other values
+	EventEvict
+	// EventExpire is emitted when a value is removed because it expired
+	EventExpire
+	// EventHit is emitted when a value is found
+	EventHit
+	// EventMiss is emitted when a value is not found
+	EventMiss
+)
+
+const defaultHooksQueueSize = 10000
+
+// String returns the name of the event type
+func (t EventType) String() string {
+	switch t {
+	case EventSet:
+		return "set"
+	case EventDelete:
+		return "delete"
+	case EventEvict:
+		return "evict"
+	case EventExpire:
+		return "expire"
+	case EventHit:
+		return "hit"
+	case EventMiss:
+		return "miss"
+	default:
+		return "unknown"
+	}
+}
+
+// Event represents an activity on a cache or a store
+type Event struct {
+	Type EventType
+	Key  any
+	// Value is the set or found value, or the removed one when the store gives it
+	Value any
+	// Source is the type of the cache or store the event comes from
+	Source string
+	Time   time.Time
+}
+
+// Hook is a function called with cache events
+type Hook func(event Event)
+
+// HooksPanicHandler is called when a hook panics, with the recovered value
+type HooksPanicHandler func(event Event, recovered any)
+
+// HooksOption represents a hooks registry option function
+type HooksOption func(o *Hooks)
+
+// WithHooksQueueSize allows setting the number of events waiting to be handled by hooks.
+// Events emitted when the queue is full are dropped. Default size is 10000.
+func WithHooksQueueSize(size int) HooksOption {
+	return func(o *Hooks) {
+		o.queueSize = size
+	}
+}
+
+// WithHooksPanicHandler allows setting a function called when a hook panics
+func WithHooksPanicHandler(handler HooksPanicHandler) HooksOption {
+	return func(o *Hooks) {
+		o.panicHandler = handler
+	}
+}
+
+type registeredHook struct {
+	id   uint64
+	hook Hook
+}
+
+// Hooks is a registry of functions called with cache events. Hooks are called
+// asynchronously, in the order events were emitted, and a panicking hook does
+// not prevent other hooks from being called.
+type Hooks struct {
+	mu     sync.RWMutex
+	hooks  map[EventType][]registeredHook
+	nextID uint64
+
+	queueSize    int
+	queue        chan Event
+	panicHandler HooksPanicHandler
+	dropped      uint64
+
+	closeMutex sync.RWMutex
+	closed     bool
+	done       chan struct{}
+}
+
+// NewHooks creates a new hooks registry
+func NewHooks(options ...HooksOption) *Hooks {
+	hooks := &Hooks{
+		hooks:     make(map[EventType][]registeredHook),
+		queueSize: defaultHooksQueueSize,
+		done:      make(chan struct{}),
+	}
+
+	for _, option := range options {
+		option(hooks)
+	}
+
+	hooks.queue = make(chan Event, hooks.queueSize)
+	go hooks.dispatcher()
+
+	return hooks
+}
+
+// On registers a hook called with events of the given type. The returned function unregisters it.
+func (h *Hooks) On(eventType EventType, hook Hook) func() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+	id := h.nextID
+	h.hooks[eventType] = append(h.hooks[eventType], registeredHook{id: id, hook: hook})
+
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		registered := h.hooks[eventType]
+		for i, entry := range registered {
+			if entry.id == id {
+				h.hooks[eventType] = append(registered[:i:i], registered[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// OnSet registers a hook called when a value is set
+func (h *Hooks) OnSet(hook Hook) func() {
+	return h.On(EventSet, hook)
+}
+
+// OnDelete registers a hook called when a value is deleted
+func (h *Hooks) OnDelete(hook Hook) func() {
+	return h.On(EventDelete, hook)
+}
+
+// OnEvict registers a hook called when a value is evicted by the store
+func (h *Hooks) OnEvict(hook Hook) func() {
+	return h.On(EventEvict, hook)
+}
+
+// OnExpire registers a hook called when a value expired
+func (h *Hooks) OnExpire(hook Hook) func() {
+	return h.On(EventExpire, hook)
+}
+
+// OnHit registers a hook called when a value is found
+func (h *Hooks) OnHit(hook Hook) func() {
+	return h.On(EventHit, hook)
+}
+
+// OnMiss registers a hook called when a value is not found
+func (h *Hooks) OnMiss(hook Hook) func() {
+	return h.On(EventMiss, hook)
+}
+
+// Emit queues the given event to be handled by the registered hooks. Events having
+// no registered hook are ignored, and events are dropped when the queue is full.
+func (h *Hooks) Emit(event Event) {
+	if !h.has(event.Type) {
+		return
+	}
+
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	h.closeMutex.RLock()
+	defer h.closeMutex.RUnlock()
+
+	if h.closed {
+		return
+	}
+
+	select {
+	case h.queue <- event:
+	default:
+		atomic.AddUint64(&h.dropped, 1)
+	}
+}
+
+// Dropped returns the number of events dropped because the queue was full
+func (h *Hooks) Dropped() uint64 {
+	return atomic.LoadUint64(&h.dropped)
+}
+
+// Close stops the hooks after the queued events have been handled.
+// Next events are ignored.
+func (h *Hooks) Close() error {
+	h.closeMutex.Lock()
+	if h.closed {
+		h.closeMutex.Unlock()
+		return nil
+	}
+	h.closed = true
+	close(h.queue)
+	h.closeMutex.Unlock()
+
+	<-h.done
+
+	return nil
+}
+
+// has returns true if hooks are registered for the given event type
+func (h *Hooks) has(eventType EventType) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.hooks[eventType]) > 0
+}
+
+// dispatcher calls the registered hooks with the queued events
+func (h *Hooks) dispatcher() {
+	defer close(h.done)
+
+	for event := range h.queue {
+		h.mu.RLock()
+		registered := h.hooks[event.Type]
+		h.mu.RUnlock()
+
+		for _, entry := range registered {
+			h.call(entry.hook, event)
+		}
+	}
+}
+
+// call calls the given hook, recovering from its panics
+func (h *Hooks) call(hook Hook, event Event) {
+	defer func() {
+		if recovered := recover(); recovered != nil && h.panicHandler != nil {
+			h.panicHandler(event, recovered)
+		}
+	}()
+
+	hook(event)
+}
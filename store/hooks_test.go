@@ -0,0 +1,153 @@
+package store
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// eventRecorder records the events emitted to hooks
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *eventRecorder) record(event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+}
+
+func (r *eventRecorder) types() []EventType {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	types := make([]EventType, len(r.events))
+	for i, event := range r.events {
+		types[i] = event.Type
+	}
+
+	return types
+}
+
+func TestHooksEmit(t *testing.T) {
+	// Given
+	hooks := NewHooks()
+
+	recorder := &eventRecorder{}
+	hooks.OnSet(recorder.record)
+	hooks.OnHit(recorder.record)
+
+	// When
+	hooks.Emit(Event{Type: EventSet, Key: "my-key", Value: "my-value"})
+	hooks.Emit(Event{Type: EventMiss, Key: "my-key"})
+	hooks.Emit(Event{Type: EventHit, Key: "my-key", Value: "my-value"})
+
+	// Then
+	assert.Nil(t, hooks.Close())
+
+	assert.Equal(t, []EventType{EventSet, EventHit}, recorder.types())
+	assert.Equal(t, "my-key", recorder.events[0].Key)
+	assert.Equal(t, "my-value", recorder.events[0].Value)
+	assert.False(t, recorder.events[0].Time.IsZero())
+}
+
+func TestHooksUnregister(t *testing.T) {
+	// Given
+	hooks := NewHooks()
+
+	recorder := &eventRecorder{}
+	unregister := hooks.OnDelete(recorder.record)
+	hooks.OnDelete(recorder.record)
+
+	// When
+	unregister()
+	hooks.Emit(Event{Type: EventDelete, Key: "my-key"})
+
+	// Then
+	assert.Nil(t, hooks.Close())
+	assert.Equal(t, []EventType{EventDelete}, recorder.types())
+}
+
+func TestHooksWhenHookPanics(t *testing.T) {
+	// Given
+	var recovered []any
+	hooks := NewHooks(WithHooksPanicHandler(func(event Event, value any) {
+		recovered = append(recovered, value)
+	}))
+
+	recorder := &eventRecorder{}
+	hooks.OnEvict(func(event Event) {
+		panic("hook failure")
+	})
+	hooks.OnEvict(recorder.record)
+
+	// When
+	hooks.Emit(Event{Type: EventEvict, Key: "my-key"})
+	hooks.Emit(Event{Type: EventEvict, Key: "my-other-key"})
+
+	// Then
+	assert.Nil(t, hooks.Close())
+	assert.Equal(t, []EventType{EventEvict, EventEvict}, recorder.types())
+	assert.Equal(t, []any{"hook failure", "hook failure"}, recovered)
+}
+
+func TestHooksWhenQueueIsFull(t *testing.T) {
+	// Given
+	hooks := NewHooks(WithHooksQueueSize(1))
+
+	blocked := make(chan struct{})
+	release := make(chan struct{})
+	recorder := &eventRecorder{}
+	hooks.OnExpire(func(event Event) {
+		if event.Key == "blocking-key" {
+			close(blocked)
+			<-release
+		}
+		recorder.record(event)
+	})
+
+	hooks.Emit(Event{Type: EventExpire, Key: "blocking-key"})
+	<-blocked
+
+	// When
+	hooks.Emit(Event{Type: EventExpire, Key: "queued-key"})
+	hooks.Emit(Event{Type: EventExpire, Key: "dropped-key"})
+
+	// Then
+	assert.Equal(t, uint64(1), hooks.Dropped())
+
+	close(release)
+	assert.Nil(t, hooks.Close())
+	assert.Len(t, recorder.events, 2)
+	assert.Equal(t, "queued-key", recorder.events[1].Key)
+}
+
+func TestHooksEmitWhenClosed(t *testing.T) {
+	// Given
+	hooks := NewHooks()
+
+	recorder := &eventRecorder{}
+	hooks.OnMiss(recorder.record)
+
+	assert.Nil(t, hooks.Close())
+
+	// When
+	hooks.Emit(Event{Type: EventMiss, Key: "my-key"})
+
+	// Then
+	assert.Nil(t, hooks.Close())
+	assert.Empty(t, recorder.types())
+}
+
+func TestEventTypeString(t *testing.T) {
+	assert.Equal(t, "set", EventSet.String())
+	assert.Equal(t, "delete", EventDelete.String())
+	assert.Equal(t, "evict", EventEvict.String())
+	assert.Equal(t, "expire", EventExpire.String())
+	assert.Equal(t, "hit", EventHit.String())
+	assert.Equal(t, "miss", EventMiss.String())
+	assert.Equal(t, "unknown", EventType(0).String())
+}
@@ -15,3 +15,10 @@ type StoreInterface interface {
 	Clear(ctx context.Context) error
 	GetType() string
 }
+
+// KeyIteratorInterface is implemented by stores able to iterate over their keys.
+// The given function is called with every key starting with the given prefix,
+// iteration stops on the first error returned by it.
+type KeyIteratorInterface interface {
+	IterateKeys(ctx context.Context, prefix string, fn func(key string) error) error
+}
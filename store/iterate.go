@@ -0,0 +1,37 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ErrKeyIterationNotSupported is returned when a store is not able to iterate over its keys
+var ErrKeyIterationNotSupported = errors.New("store does not support key iteration")
+
+// IterateKeys calls the given function with every key of the store starting with the given prefix.
+// It returns ErrKeyIterationNotSupported if the store does not implement KeyIteratorInterface.
+func IterateKeys(ctx context.Context, store StoreInterface, prefix string, fn func(key string) error) error {
+	iterator, ok := store.(KeyIteratorInterface)
+	if !ok {
+		return ErrKeyIterationNotSupported
+	}
+
+	return iterator.IterateKeys(ctx, prefix, fn)
+}
+
+// redisMatchPattern returns a SCAN pattern matching keys starting with the given prefix
+func redisMatchPattern(prefix string) string {
+	var builder strings.Builder
+
+	for _, char := range prefix {
+		switch char {
+		case '*', '?', '[', ']', '\\':
+			builder.WriteRune('\\')
+		}
+		builder.WriteRune(char)
+	}
+	builder.WriteRune('*')
+
+	return builder.String()
+}
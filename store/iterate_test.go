@@ -0,0 +1,22 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisMatchPattern(t *testing.T) {
+	// Given
+	testCases := map[string]string{
+		"":          "*",
+		"svc-a:":    "svc-a:*",
+		"svc*a?[b]": `svc\*a\?\[b\]*`,
+		`svc\a`:     `svc\\a*`,
+	}
+
+	for prefix, expected := range testCases {
+		// When - Then
+		assert.Equal(t, expected, redisMatchPattern(prefix))
+	}
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+)
+
+// ErrInvalidKey is returned when a key does not respect the constraints of a store
+var ErrInvalidKey = errors.New("invalid key")
+
+// KeyConstraints represents the constraints keys have to respect to be accepted by a store
+type KeyConstraints struct {
+	// MaxLength is the maximum length of keys in bytes, zero means unlimited
+	MaxLength int
+	// DisallowWhitespace rejects keys containing whitespaces or control characters
+	DisallowWhitespace bool
+}
+
+// KeyConstraintsInterface is implemented by stores having constraints on their keys
+type KeyConstraintsInterface interface {
+	GetKeyConstraints() KeyConstraints
+}
+
+// GetKeyConstraints returns the key constraints of the given store, if any
+func GetKeyConstraints(store StoreInterface) KeyConstraints {
+	if constrained, ok := store.(KeyConstraintsInterface); ok {
+		return constrained.GetKeyConstraints()
+	}
+
+	return KeyConstraints{}
+}
+
+// IsUnconstrained returns true if any key is accepted
+func (c KeyConstraints) IsUnconstrained() bool {
+	return c.MaxLength == 0 && !c.DisallowWhitespace
+}
+
+// Validate returns an ErrInvalidKey error if the given key does not respect the constraints
+func (c KeyConstraints) Validate(key string) error {
+	if c.MaxLength > 0 && len(key) > c.MaxLength {
+		return fmt.Errorf("%w: key is %d bytes long, maximum is %d", ErrInvalidKey, len(key), c.MaxLength)
+	}
+
+	for _, char := range key {
+		if !c.IsValidRune(char) {
+			return fmt.Errorf("%w: key contains illegal character %q", ErrInvalidKey, char)
+		}
+	}
+
+	return nil
+}
+
+// IsValidRune returns true if the given character is accepted in keys
+func (c KeyConstraints) IsValidRune(char rune) bool {
+	return !c.DisallowWhitespace || !(unicode.IsSpace(char) || unicode.IsControl(char))
+}
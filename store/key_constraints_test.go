@@ -0,0 +1,37 @@
+package store
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyConstraintsValidate(t *testing.T) {
+	// Given
+	constraints := KeyConstraints{MaxLength: 10, DisallowWhitespace: true}
+
+	// When - Then
+	assert.Nil(t, constraints.Validate("my-key"))
+	assert.ErrorIs(t, constraints.Validate("my-too-long-key"), ErrInvalidKey)
+	assert.ErrorIs(t, constraints.Validate("my key"), ErrInvalidKey)
+	assert.ErrorIs(t, constraints.Validate("my\x00key"), ErrInvalidKey)
+
+	assert.True(t, KeyConstraints{}.IsUnconstrained())
+	assert.Nil(t, KeyConstraints{}.Validate("my key "+strings.Repeat("a", 1000)))
+}
+
+func TestGetKeyConstraints(t *testing.T) {
+	// Given
+	memcacheStore := NewMemcache(nil)
+
+	// When - Then
+	assert.Equal(t, KeyConstraints{MaxLength: 250, DisallowWhitespace: true}, GetKeyConstraints(memcacheStore))
+	assert.Equal(t, KeyConstraints{MaxLength: 65535}, GetKeyConstraints(NewFreecache(nil)))
+	assert.Equal(t, KeyConstraints{}, GetKeyConstraints(NewGoCache(cache.New(cache.NoExpiration, time.Minute))))
+
+	assert.Equal(t, KeyConstraints{MaxLength: 244, DisallowWhitespace: true}, GetKeyConstraints(WithNamespace(memcacheStore, "svc-a:")))
+	assert.Equal(t, KeyConstraints{MaxLength: 250, DisallowWhitespace: true}, GetKeyConstraints(WithCompression(memcacheStore)))
+}
@@ -25,6 +25,8 @@ type MemcacheClientInterface interface {
 const (
 	// MemcacheType represents the storage type as a string value
 	MemcacheType = "memcache"
+	// MemcacheMaxKeyLength represents the maximum length of Memcache keys, in bytes
+	MemcacheMaxKeyLength = 250
 	// MemcacheTagPattern represents the tag pattern to be used as a key in specified storage
 	MemcacheTagPattern = "gocache_tag_%s"
 
@@ -35,6 +37,7 @@ const (
 type MemcacheStore struct {
 	client  MemcacheClientInterface
 	options *Options
+	closer  closeState
 }
 
 // NewMemcache creates a new store to Memcache instance(s)
@@ -47,6 +50,10 @@ func NewMemcache(client MemcacheClientInterface, options ...Option) *MemcacheSto
 
 // Get returns data stored from a given key
 func (s *MemcacheStore) Get(_ context.Context, key any) (any, error) {
+	if s.closer.isClosed() {
+		return nil, ErrClosed
+	}
+
 	item, err := s.client.Get(key.(string))
 	if err != nil {
 		return nil, err
@@ -60,6 +67,10 @@ func (s *MemcacheStore) Get(_ context.Context, key any) (any, error) {
 
 // GetWithTTL returns data stored from a given key and its corresponding TTL
 func (s *MemcacheStore) GetWithTTL(_ context.Context, key any) (any, time.Duration, error) {
+	if s.closer.isClosed() {
+		return nil, 0, ErrClosed
+	}
+
 	item, err := s.client.Get(key.(string))
 	if err != nil {
 		return nil, 0, err
@@ -73,6 +84,10 @@ func (s *MemcacheStore) GetWithTTL(_ context.Context, key any) (any, time.Durati
 
 // Set defines data in Memcache for given key identifier
 func (s *MemcacheStore) Set(ctx context.Context, key any, value any, options ...Option) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
 	opts := applyOptionsWithDefault(s.options, options...)
 
 	item := &memcache.Item{
@@ -158,11 +173,19 @@ func (s *MemcacheStore) addKeyToTagValue(tagKey string, key any) error {
 
 // Delete removes data from Memcache for given key identifier
 func (s *MemcacheStore) Delete(_ context.Context, key any) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
 	return s.client.Delete(key.(string))
 }
 
 // Invalidate invalidates some cache data in Memcache for given options
 func (s *MemcacheStore) Invalidate(ctx context.Context, options ...InvalidateOption) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
 	opts := ApplyInvalidateOptions(options...)
 
 	if tags := opts.tags; len(tags) > 0 {
@@ -189,10 +212,26 @@ func (s *MemcacheStore) Invalidate(ctx context.Context, options ...InvalidateOpt
 
 // Clear resets all data in the store
 func (s *MemcacheStore) Clear(_ context.Context) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
 	return s.client.FlushAll()
 }
 
+// Close marks the store as closed. As the client is given by the caller, it is not closed:
+// its owner has to close it. Next operations return ErrClosed.
+func (s *MemcacheStore) Close() error {
+	s.closer.close()
+	return nil
+}
+
 // GetType returns the store type
 func (s *MemcacheStore) GetType() string {
 	return MemcacheType
 }
+
+// GetKeyConstraints returns the constraints of Memcache keys: at most 250 bytes, without whitespaces
+func (s *MemcacheStore) GetKeyConstraints() KeyConstraints {
+	return KeyConstraints{MaxLength: MemcacheMaxKeyLength, DisallowWhitespace: true}
+}
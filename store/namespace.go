@@ -0,0 +1,176 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// NamespaceStore is a store decorator prefixing every key and tag with a namespace,
+// allowing several services or tenants to share the same storage. As the inner store
+// is shared, it is not closed when the namespace is closed.
+type NamespaceStore struct {
+	inner  StoreInterface
+	prefix string
+	closer closeState
+}
+
+// WithNamespace creates a new store prefixing every key and tag of the inner store with the given prefix.
+// Namespaces can be nested: WithNamespace(WithNamespace(redisStore, "svc-a:"), "tenant-1:")
+// stores keys as "svc-a:tenant-1:<key>".
+func WithNamespace(inner StoreInterface, prefix string) *NamespaceStore {
+	return &NamespaceStore{
+		inner:  inner,
+		prefix: prefix,
+	}
+}
+
+// Get returns data stored from a given key
+func (s *NamespaceStore) Get(ctx context.Context, key any) (any, error) {
+	if s.closer.isClosed() {
+		return nil, ErrClosed
+	}
+
+	return s.inner.Get(ctx, s.key(key))
+}
+
+// GetWithTTL returns data stored from a given key and its corresponding TTL
+func (s *NamespaceStore) GetWithTTL(ctx context.Context, key any) (any, time.Duration, error) {
+	if s.closer.isClosed() {
+		return nil, 0, ErrClosed
+	}
+
+	return s.inner.GetWithTTL(ctx, s.key(key))
+}
+
+// Set defines data in the inner store for given key identifier
+func (s *NamespaceStore) Set(ctx context.Context, key any, value any, options ...Option) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	if tags := ApplyOptions(options...).tags; len(tags) > 0 {
+		options = append(options[:len(options):len(options)], WithTags(s.tags(tags)))
+	}
+
+	return s.inner.Set(ctx, s.key(key), value, options...)
+}
+
+// Delete removes data from the inner store for given key identifier
+func (s *NamespaceStore) Delete(ctx context.Context, key any) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	return s.inner.Delete(ctx, s.key(key))
+}
+
+// Invalidate invalidates some cache data in the inner store for given options
+func (s *NamespaceStore) Invalidate(ctx context.Context, options ...InvalidateOption) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	if tags := ApplyInvalidateOptions(options...).tags; len(tags) > 0 {
+		options = append(options[:len(options):len(options)], WithInvalidateTags(s.tags(tags)))
+	}
+
+	return s.inner.Invalidate(ctx, options...)
+}
+
+// Clear removes every key of the namespace from the inner store, as well as the keys
+// holding the tags of the namespace. Other namespaces are kept, so the inner store is
+// never flushed: it has to support key iteration, otherwise ErrKeyIterationNotSupported
+// is returned.
+func (s *NamespaceStore) Clear(ctx context.Context) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	err := s.IterateKeys(ctx, "", func(key string) error {
+		return s.inner.Delete(ctx, s.prefix+key)
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.clearTags(ctx, s.prefix)
+}
+
+// clearTags removes the keys holding the tags starting with the given prefix
+// from the store wrapped by the namespaces
+func (s *NamespaceStore) clearTags(ctx context.Context, prefix string) error {
+	if inner, ok := s.inner.(*NamespaceStore); ok {
+		return inner.clearTags(ctx, inner.prefix+prefix)
+	}
+
+	tagKeyPrefix := strings.TrimSuffix(RedisTagPattern, "%s")
+	if s.inner.GetType() == FreecacheType {
+		tagKeyPrefix = strings.TrimSuffix(FreecacheTagPattern, "%s")
+	}
+
+	return IterateKeys(ctx, s.inner, tagKeyPrefix+prefix, func(key string) error {
+		return s.inner.Delete(ctx, key)
+	})
+}
+
+// IterateKeys calls the given function with every key of the namespace starting
+// with the given prefix. Keys are given without the namespace prefix.
+func (s *NamespaceStore) IterateKeys(ctx context.Context, prefix string, fn func(key string) error) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	return IterateKeys(ctx, s.inner, s.prefix+prefix, func(key string) error {
+		return fn(strings.TrimPrefix(key, s.prefix))
+	})
+}
+
+// GetKeyConstraints returns the key constraints of the inner store, the maximum
+// length being reduced by the length of the namespace prefix
+func (s *NamespaceStore) GetKeyConstraints() KeyConstraints {
+	constraints := GetKeyConstraints(s.inner)
+	if constraints.MaxLength > 0 {
+		constraints.MaxLength -= len(s.prefix)
+		if constraints.MaxLength <= 0 {
+			constraints.MaxLength = 1
+		}
+	}
+
+	return constraints
+}
+
+// Close marks the namespace as closed. As the inner store is shared, it is not closed.
+// Next operations return ErrClosed.
+func (s *NamespaceStore) Close() error {
+	s.closer.close()
+	return nil
+}
+
+// GetType returns the inner store type
+func (s *NamespaceStore) GetType() string {
+	return s.inner.GetType()
+}
+
+// GetPrefix returns the namespace prefix
+func (s *NamespaceStore) GetPrefix() string {
+	return s.prefix
+}
+
+func (s *NamespaceStore) key(key any) string {
+	if stringKey, ok := key.(string); ok {
+		return s.prefix + stringKey
+	}
+
+	return s.prefix + fmt.Sprint(key)
+}
+
+func (s *NamespaceStore) tags(tags []string) []string {
+	prefixed := make([]string, len(tags))
+	for i, tag := range tags {
+		prefixed[i] = s.prefix + tag
+	}
+
+	return prefixed
+}
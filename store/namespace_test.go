@@ -0,0 +1,195 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespaceSetGet(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	inner := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+	store := WithNamespace(inner, "svc-a:")
+
+	// When
+	err := store.Set(ctx, "my-key", "my-value")
+
+	// Then
+	assert.Nil(t, err)
+
+	value, err := store.Get(ctx, "my-key")
+	assert.Nil(t, err)
+	assert.Equal(t, "my-value", value)
+
+	value, err = inner.Get(ctx, "svc-a:my-key")
+	assert.Nil(t, err)
+	assert.Equal(t, "my-value", value)
+
+	_, err = inner.Get(ctx, "my-key")
+	assert.True(t, errors.Is(err, NotFound{}))
+}
+
+func TestNamespaceDelete(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	inner := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+	_ = inner.Set(ctx, "svc-a:my-key", "my-value")
+	_ = inner.Set(ctx, "my-key", "my-value")
+
+	store := WithNamespace(inner, "svc-a:")
+
+	// When
+	err := store.Delete(ctx, "my-key")
+
+	// Then
+	assert.Nil(t, err)
+
+	_, err = inner.Get(ctx, "svc-a:my-key")
+	assert.True(t, errors.Is(err, NotFound{}))
+
+	_, err = inner.Get(ctx, "my-key")
+	assert.Nil(t, err)
+}
+
+func TestNamespaceInvalidate(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	inner := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+	storeA := WithNamespace(inner, "svc-a:")
+	storeB := WithNamespace(inner, "svc-b:")
+
+	_ = storeA.Set(ctx, "my-key", "value-a", WithTags([]string{"books"}))
+	_ = storeB.Set(ctx, "my-key", "value-b", WithTags([]string{"books"}))
+
+	// When
+	err := storeA.Invalidate(ctx, WithInvalidateTags([]string{"books"}))
+
+	// Then
+	assert.Nil(t, err)
+
+	_, err = storeA.Get(ctx, "my-key")
+	assert.True(t, errors.Is(err, NotFound{}))
+
+	value, err := storeB.Get(ctx, "my-key")
+	assert.Nil(t, err)
+	assert.Equal(t, "value-b", value)
+}
+
+func TestNamespaceClear(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	inner := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+	storeA := WithNamespace(inner, "svc-a:")
+	storeB := WithNamespace(inner, "svc-b:")
+
+	_ = storeA.Set(ctx, "one", "value")
+	_ = storeA.Set(ctx, "two", "value")
+	_ = storeB.Set(ctx, "one", "value")
+
+	// When
+	err := storeA.Clear(ctx)
+
+	// Then
+	assert.Nil(t, err)
+
+	_, err = storeA.Get(ctx, "one")
+	assert.True(t, errors.Is(err, NotFound{}))
+	_, err = storeA.Get(ctx, "two")
+	assert.True(t, errors.Is(err, NotFound{}))
+
+	value, err := storeB.Get(ctx, "one")
+	assert.Nil(t, err)
+	assert.Equal(t, "value", value)
+}
+
+func TestNamespaceClearRemovesTags(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	inner := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+	storeA := WithNamespace(WithNamespace(inner, "svc:"), "tenant-a:")
+	storeB := WithNamespace(WithNamespace(inner, "svc:"), "tenant-b:")
+
+	_ = storeA.Set(ctx, "one", "value", WithTags([]string{"my-tag"}))
+	_ = storeB.Set(ctx, "one", "value", WithTags([]string{"my-tag"}))
+
+	// When
+	err := storeA.Clear(ctx)
+
+	// Then
+	assert.Nil(t, err)
+
+	_, err = inner.Get(ctx, "gocache_tag_svc:tenant-a:my-tag")
+	assert.True(t, errors.Is(err, NotFound{}))
+
+	_, err = inner.Get(ctx, "gocache_tag_svc:tenant-b:my-tag")
+	assert.Nil(t, err)
+}
+
+func TestNamespaceClearWhenIterationNotSupported(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	store := WithNamespace(&MemcacheStore{}, "svc-a:")
+
+	// When
+	err := store.Clear(ctx)
+
+	// Then
+	assert.Equal(t, ErrKeyIterationNotSupported, err)
+}
+
+func TestNamespaceNested(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	inner := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+	service := WithNamespace(inner, "svc-a:")
+	tenant1 := WithNamespace(service, "tenant-1:")
+	tenant2 := WithNamespace(service, "tenant-2:")
+
+	_ = tenant1.Set(ctx, "one", "value")
+	_ = tenant1.Set(ctx, "two", "value")
+	_ = tenant2.Set(ctx, "one", "value")
+
+	var keys []string
+
+	// When
+	err := tenant1.IterateKeys(ctx, "", func(key string) error {
+		keys = append(keys, key)
+		return nil
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{"one", "two"}, keys)
+
+	_, err = inner.Get(ctx, "svc-a:tenant-1:one")
+	assert.Nil(t, err)
+
+	assert.Nil(t, tenant1.Clear(ctx))
+
+	_, err = inner.Get(ctx, "svc-a:tenant-1:one")
+	assert.True(t, errors.Is(err, NotFound{}))
+
+	_, err = inner.Get(ctx, "svc-a:tenant-2:one")
+	assert.Nil(t, err)
+}
+
+func TestNamespaceGetType(t *testing.T) {
+	// Given
+	store := WithNamespace(NewGoCache(cache.New(cache.NoExpiration, time.Minute)), "svc-a:")
+
+	// When - Then
+	assert.Equal(t, GoCacheType, store.GetType())
+	assert.Equal(t, "svc-a:", store.GetPrefix())
+}
@@ -1,6 +1,8 @@
 package store
 
 import (
+	"math/rand"
+	"sync"
 	"time"
 )
 
@@ -8,9 +10,26 @@ import (
 type Option func(o *Options)
 
 type Options struct {
-	cost       int64
-	expiration time.Duration
-	tags       []string
+	cost                     int64
+	expiration               time.Duration
+	expirationJitter         time.Duration
+	expirationJitterFraction float64
+	tags                     []string
+	keyTracking              bool
+}
+
+var (
+	jitterMtx    sync.Mutex
+	jitterRandom = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// SetJitterSource sets the random source used to compute expiration jitters.
+// It is mainly useful to get deterministic expirations in tests.
+func SetJitterSource(source rand.Source) {
+	jitterMtx.Lock()
+	defer jitterMtx.Unlock()
+
+	jitterRandom = rand.New(source)
 }
 
 func (o *Options) isEmpty() bool {
@@ -21,6 +40,45 @@ func (o *Options) Expiration() time.Duration {
 	return o.expiration
 }
 
+func (o *Options) ExpirationJitter() time.Duration {
+	return o.expirationJitter
+}
+
+func (o *Options) ExpirationJitterFraction() float64 {
+	return o.expirationJitterFraction
+}
+
+// JitteredExpiration returns the given expiration increased by a random duration
+// computed from the expiration jitter options. Zero (no expiration) is kept as is.
+func (o *Options) JitteredExpiration(expiration time.Duration) time.Duration {
+	if expiration <= 0 {
+		return expiration
+	}
+
+	maxJitter := o.expirationJitter
+	if o.expirationJitterFraction > 0 {
+		maxJitter = time.Duration(o.expirationJitterFraction * float64(expiration))
+	}
+	if maxJitter <= 0 {
+		return expiration
+	}
+
+	jitterMtx.Lock()
+	defer jitterMtx.Unlock()
+
+	return expiration + time.Duration(jitterRandom.Int63n(int64(maxJitter)))
+}
+
+// applyExpirationJitter randomises the expiration once, so the jitter
+// is not applied again if options are passed to another layer
+func (o *Options) applyExpirationJitter() *Options {
+	o.expiration = o.JitteredExpiration(o.expiration)
+	o.expirationJitter = 0
+	o.expirationJitterFraction = 0
+
+	return o
+}
+
 func applyOptionsWithDefault(defaultOptions *Options, opts ...Option) *Options {
 	returnedOptions := &Options{}
 	*returnedOptions = *defaultOptions
@@ -29,7 +87,37 @@ func applyOptionsWithDefault(defaultOptions *Options, opts ...Option) *Options {
 		opt(returnedOptions)
 	}
 
-	return returnedOptions
+	return returnedOptions.applyExpirationJitter()
+}
+
+// defaultOptions returns the options applied by default by the given store to its writes,
+// looking through store decorators
+func defaultOptions(store StoreInterface) *Options {
+	switch s := store.(type) {
+	case *BigcacheStore:
+		return s.options
+	case *FreecacheStore:
+		return s.options
+	case *MemcacheStore:
+		return s.options
+	case *RedisStore:
+		return s.options
+	case *RedisClusterStore:
+		return s.options
+	case *RistrettoStore:
+		return s.options
+	case *CompressionStore:
+		return defaultOptions(s.inner)
+	case *EncryptionStore:
+		return defaultOptions(s.inner)
+	case *HookStore:
+		return defaultOptions(s.inner)
+	case *NamespaceStore:
+		return defaultOptions(s.inner)
+	}
+
+	// Other stores, such as go-cache and Pegasus ones, do not apply default options
+	return &Options{}
 }
 
 func ApplyOptions(opts ...Option) *Options {
@@ -50,6 +138,14 @@ func WithCost(cost int64) Option {
 	}
 }
 
+// WithKeyTracking allows recording the keys set through the store, so stores unable
+// to list their keys can be dumped. Actually it is used by Ristretto store only.
+func WithKeyTracking() Option {
+	return func(o *Options) {
+		o.keyTracking = true
+	}
+}
+
 // WithExpiration allows to specify an expiration time when setting a value.
 func WithExpiration(expiration time.Duration) Option {
 	return func(o *Options) {
@@ -57,6 +153,25 @@ func WithExpiration(expiration time.Duration) Option {
 	}
 }
 
+// WithExpirationJitter allows to randomise the expiration time when setting a value,
+// by adding a random duration between zero and the given jitter.
+// It spreads expirations of values set at the same time with the same expiration.
+func WithExpirationJitter(jitter time.Duration) Option {
+	return func(o *Options) {
+		o.expirationJitter = jitter
+		o.expirationJitterFraction = 0
+	}
+}
+
+// WithExpirationJitterFraction allows to randomise the expiration time when setting a value,
+// by adding a random duration between zero and the given fraction of the expiration.
+func WithExpirationJitterFraction(fraction float64) Option {
+	return func(o *Options) {
+		o.expirationJitter = 0
+		o.expirationJitterFraction = fraction
+	}
+}
+
 // WithTags allows to specify associated tags to the current value.
 func WithTags(tags []string) Option {
 	return func(o *Options) {
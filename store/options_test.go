@@ -1,6 +1,7 @@
 package store
 
 import (
+	"math/rand"
 	"testing"
 	"time"
 
@@ -50,3 +51,96 @@ func Test_applyOptionsWithDefault(t *testing.T) {
 	assert.Equal(t, int64(7), options.cost)
 	assert.Equal(t, 25*time.Second, options.expiration)
 }
+
+func TestOptionsJitteredExpiration(t *testing.T) {
+	testCases := []struct {
+		name    string
+		options []Option
+		min     time.Duration
+		max     time.Duration
+	}{
+		{
+			name:    "without jitter",
+			options: []Option{},
+			min:     time.Minute,
+			max:     time.Minute,
+		},
+		{
+			name:    "with duration jitter",
+			options: []Option{WithExpirationJitter(10 * time.Second)},
+			min:     time.Minute,
+			max:     time.Minute + 10*time.Second,
+		},
+		{
+			name:    "with fraction jitter",
+			options: []Option{WithExpirationJitterFraction(0.5)},
+			min:     time.Minute,
+			max:     time.Minute + 30*time.Second,
+		},
+		{
+			name:    "with last jitter option winning",
+			options: []Option{WithExpirationJitterFraction(0.5), WithExpirationJitter(time.Second)},
+			min:     time.Minute,
+			max:     time.Minute + time.Second,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Given
+			options := ApplyOptions(tc.options...)
+
+			// When
+			for i := 0; i < 100; i++ {
+				expiration := options.JitteredExpiration(time.Minute)
+
+				// Then
+				assert.GreaterOrEqual(t, expiration, tc.min)
+				assert.LessOrEqual(t, expiration, tc.max)
+			}
+		})
+	}
+}
+
+func TestOptionsJitteredExpirationWhenNoExpiration(t *testing.T) {
+	// Given
+	options := ApplyOptions(WithExpirationJitter(10 * time.Second))
+
+	// When - Then
+	assert.Equal(t, time.Duration(0), options.JitteredExpiration(0))
+}
+
+func TestOptionsJitteredExpirationIsDeterministicWithSource(t *testing.T) {
+	// Given
+	options := ApplyOptions(WithExpirationJitter(time.Hour))
+
+	// When
+	SetJitterSource(rand.NewSource(42))
+	first := options.JitteredExpiration(time.Minute)
+
+	SetJitterSource(rand.NewSource(42))
+	second := options.JitteredExpiration(time.Minute)
+
+	// Then
+	assert.Equal(t, first, second)
+}
+
+func Test_applyOptionsWithDefaultAppliesJitterOnce(t *testing.T) {
+	// Given
+	defaultOptions := &Options{
+		expiration:       25 * time.Second,
+		expirationJitter: 5 * time.Second,
+	}
+
+	// When
+	options := applyOptionsWithDefault(defaultOptions)
+
+	// Then
+	assert.GreaterOrEqual(t, options.expiration, 25*time.Second)
+	assert.Less(t, options.expiration, 30*time.Second)
+	assert.Equal(t, time.Duration(0), options.expirationJitter)
+
+	// default options are left untouched
+	assert.Equal(t, 25*time.Second, defaultOptions.expiration)
+	assert.Equal(t, 5*time.Second, defaultOptions.expirationJitter)
+}
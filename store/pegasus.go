@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/XiaoMi/pegasus-go-client/admin"
@@ -15,6 +16,8 @@ import (
 const (
 	// PegasusType represents the storage type as a string value
 	PegasusType = "pegasus"
+	// PegasusMaxKeyLength represents the maximum length of Pegasus hash keys, in bytes
+	PegasusMaxKeyLength = 65535
 	// PegasusTagPattern represents the tag pattern to be used as a key in specified storage
 	PegasusTagPattern = "gocache_tag_%s"
 	// Pegasus ttl(time-to-live) in seconds: -1 if ttl is not set; -2 if entry doesn't exist
@@ -43,6 +46,10 @@ type OptionsPegasus struct {
 type PegasusStore struct {
 	client  pegasus.Client
 	options *OptionsPegasus
+
+	closer    closeState
+	closeOnce sync.Once
+	closeErr  error
 }
 
 // NewPegasus creates a new store to pegasus instance(s)
@@ -120,13 +127,23 @@ func dropTable(ctx context.Context, options *OptionsPegasus) error {
 	return tableClient.DropTable(ctx, options.TableName)
 }
 
-// Close when exit store
+// Close closes the client, which has been created by the store. It is safe to call it several
+// times. Next operations return ErrClosed.
 func (p *PegasusStore) Close() error {
-	return p.client.Close()
+	p.closer.close()
+	p.closeOnce.Do(func() {
+		p.closeErr = p.client.Close()
+	})
+
+	return p.closeErr
 }
 
 // Get returns data stored from a given key
 func (p *PegasusStore) Get(ctx context.Context, key any) (any, error) {
+	if p.closer.isClosed() {
+		return nil, ErrClosed
+	}
+
 	table, err := p.client.OpenTable(ctx, p.options.TableName)
 	if err != nil {
 		return nil, err
@@ -145,6 +162,10 @@ func (p *PegasusStore) Get(ctx context.Context, key any) (any, error) {
 
 // GetWithTTL returns data stored from a given key and its corresponding TTL
 func (p *PegasusStore) GetWithTTL(ctx context.Context, key any) (any, time.Duration, error) {
+	if p.closer.isClosed() {
+		return nil, 0, ErrClosed
+	}
+
 	table, err := p.client.OpenTable(ctx, p.options.TableName)
 	if err != nil {
 		return nil, 0, err
@@ -169,7 +190,11 @@ func (p *PegasusStore) GetWithTTL(ctx context.Context, key any) (any, time.Durat
 
 // Set defines data in Pegasus for given key identifier
 func (p *PegasusStore) Set(ctx context.Context, key, value any, options ...Option) error {
-	opts := ApplyOptions(options...)
+	if p.closer.isClosed() {
+		return ErrClosed
+	}
+
+	opts := ApplyOptions(options...).applyExpirationJitter()
 
 	table, err := p.client.OpenTable(ctx, p.options.TableName)
 	if err != nil {
@@ -223,6 +248,10 @@ func (p *PegasusStore) setTags(ctx context.Context, key any, tags []string) erro
 
 // Delete removes data from Pegasus for given key identifier
 func (p *PegasusStore) Delete(ctx context.Context, key any) error {
+	if p.closer.isClosed() {
+		return ErrClosed
+	}
+
 	table, err := p.client.OpenTable(ctx, p.options.TableName)
 	if err != nil {
 		return err
@@ -234,6 +263,10 @@ func (p *PegasusStore) Delete(ctx context.Context, key any) error {
 
 // Invalidate invalidates some cache data in Pegasus for given options
 func (p *PegasusStore) Invalidate(ctx context.Context, options ...InvalidateOption) error {
+	if p.closer.isClosed() {
+		return ErrClosed
+	}
+
 	opts := ApplyInvalidateOptions(options...)
 	if tags := opts.tags; len(tags) > 0 {
 		for _, tag := range tags {
@@ -261,6 +294,10 @@ func (p *PegasusStore) Invalidate(ctx context.Context, options ...InvalidateOpti
 
 // Clear resets all data in the store
 func (p *PegasusStore) Clear(ctx context.Context) error {
+	if p.closer.isClosed() {
+		return ErrClosed
+	}
+
 	table, err := p.client.OpenTable(ctx, p.options.TableName)
 	if err != nil {
 		return err
@@ -301,3 +338,8 @@ func (p *PegasusStore) Clear(ctx context.Context) error {
 func (p *PegasusStore) GetType() string {
 	return PegasusType
 }
+
+// GetKeyConstraints returns the constraints of Pegasus hash keys: at most 65535 bytes
+func (p *PegasusStore) GetKeyConstraints() KeyConstraints {
+	return KeyConstraints{MaxLength: PegasusMaxKeyLength}
+}
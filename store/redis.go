@@ -18,6 +18,7 @@ type RedisClientInterface interface {
 	FlushAll(ctx context.Context) *redis.StatusCmd
 	SAdd(ctx context.Context, key string, members ...any) *redis.IntCmd
 	SMembers(ctx context.Context, key string) *redis.StringSliceCmd
+	Scan(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd
 }
 
 const (
@@ -25,12 +26,15 @@ const (
 	RedisType = "redis"
 	// RedisTagPattern represents the tag pattern to be used as a key in specified storage
 	RedisTagPattern = "gocache_tag_%s"
+
+	redisScanCount = 1000
 )
 
 // RedisStore is a store for Redis
 type RedisStore struct {
 	client  RedisClientInterface
 	options *Options
+	closer  closeState
 }
 
 // NewRedis creates a new store to Redis instance(s)
@@ -43,6 +47,10 @@ func NewRedis(client RedisClientInterface, options ...Option) *RedisStore {
 
 // Get returns data stored from a given key
 func (s *RedisStore) Get(ctx context.Context, key any) (any, error) {
+	if s.closer.isClosed() {
+		return nil, ErrClosed
+	}
+
 	object, err := s.client.Get(ctx, key.(string)).Result()
 	if err == redis.Nil {
 		return nil, NotFoundWithCause(err)
@@ -52,6 +60,10 @@ func (s *RedisStore) Get(ctx context.Context, key any) (any, error) {
 
 // GetWithTTL returns data stored from a given key and its corresponding TTL
 func (s *RedisStore) GetWithTTL(ctx context.Context, key any) (any, time.Duration, error) {
+	if s.closer.isClosed() {
+		return nil, 0, ErrClosed
+	}
+
 	object, err := s.client.Get(ctx, key.(string)).Result()
 	if err == redis.Nil {
 		return nil, 0, NotFoundWithCause(err)
@@ -70,6 +82,10 @@ func (s *RedisStore) GetWithTTL(ctx context.Context, key any) (any, time.Duratio
 
 // Set defines data in Redis for given key identifier
 func (s *RedisStore) Set(ctx context.Context, key any, value any, options ...Option) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
 	opts := applyOptionsWithDefault(s.options, options...)
 
 	err := s.client.Set(ctx, key.(string), value, opts.expiration).Err()
@@ -94,12 +110,20 @@ func (s *RedisStore) setTags(ctx context.Context, key any, tags []string) {
 
 // Delete removes data from Redis for given key identifier
 func (s *RedisStore) Delete(ctx context.Context, key any) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
 	_, err := s.client.Del(ctx, key.(string)).Result()
 	return err
 }
 
 // Invalidate invalidates some cache data in Redis for given options
 func (s *RedisStore) Invalidate(ctx context.Context, options ...InvalidateOption) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
 	opts := ApplyInvalidateOptions(options...)
 
 	if tags := opts.tags; len(tags) > 0 {
@@ -121,6 +145,13 @@ func (s *RedisStore) Invalidate(ctx context.Context, options ...InvalidateOption
 	return nil
 }
 
+// Close marks the store as closed. As the client is given by the caller, it is not closed:
+// its owner has to close it. Next operations return ErrClosed.
+func (s *RedisStore) Close() error {
+	s.closer.close()
+	return nil
+}
+
 // GetType returns the store type
 func (s *RedisStore) GetType() string {
 	return RedisType
@@ -128,9 +159,51 @@ func (s *RedisStore) GetType() string {
 
 // Clear resets all data in the store
 func (s *RedisStore) Clear(ctx context.Context) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
 	if err := s.client.FlushAll(ctx).Err(); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// IterateKeys calls the given function with every key starting with the given prefix,
+// using SCAN so Redis is never blocked
+func (s *RedisStore) IterateKeys(ctx context.Context, prefix string, fn func(key string) error) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	return scanKeys(ctx, s.client.Scan, prefix, fn)
+}
+
+func scanKeys(
+	ctx context.Context,
+	scan func(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd,
+	prefix string,
+	fn func(key string) error,
+) error {
+	match := redisMatchPattern(prefix)
+
+	var cursor uint64
+	for {
+		keys, next, err := scan(ctx, cursor, match, redisScanCount).Result()
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			if err := fn(key); err != nil {
+				return err
+			}
+		}
+
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
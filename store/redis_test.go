@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -180,3 +181,55 @@ func TestRedisGetType(t *testing.T) {
 	// When - Then
 	assert.Equal(t, RedisType, store.GetType())
 }
+
+func TestRedisIterateKeys(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	client := mocksStore.NewMockRedisClientInterface(ctrl)
+	gomock.InOrder(
+		client.EXPECT().Scan(ctx, uint64(0), `svc\*a:*`, int64(redisScanCount)).
+			Return(redis.NewScanCmdResult([]string{"svc*a:one", "svc*a:two"}, 42, nil)),
+		client.EXPECT().Scan(ctx, uint64(42), `svc\*a:*`, int64(redisScanCount)).
+			Return(redis.NewScanCmdResult([]string{"svc*a:three"}, 0, nil)),
+	)
+
+	store := NewRedis(client)
+
+	var keys []string
+
+	// When
+	err := store.IterateKeys(ctx, "svc*a:", func(key string) error {
+		keys = append(keys, key)
+		return nil
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"svc*a:one", "svc*a:two", "svc*a:three"}, keys)
+}
+
+func TestRedisIterateKeysWhenError(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	expectedErr := errors.New("unable to scan")
+
+	client := mocksStore.NewMockRedisClientInterface(ctrl)
+	client.EXPECT().Scan(ctx, uint64(0), "svc-a:*", int64(redisScanCount)).
+		Return(redis.NewScanCmdResult(nil, 0, expectedErr))
+
+	store := NewRedis(client)
+
+	// When
+	err := store.IterateKeys(ctx, "svc-a:", func(key string) error {
+		return nil
+	})
+
+	// Then
+	assert.Equal(t, expectedErr, err)
+}
@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -18,6 +19,7 @@ type RedisClusterClientInterface interface {
 	FlushAll(ctx context.Context) *redis.StatusCmd
 	SAdd(ctx context.Context, key string, members ...any) *redis.IntCmd
 	SMembers(ctx context.Context, key string) *redis.StringSliceCmd
+	ForEachMaster(ctx context.Context, fn func(ctx context.Context, client *redis.Client) error) error
 }
 
 const (
@@ -31,6 +33,7 @@ const (
 type RedisClusterStore struct {
 	clusclient RedisClusterClientInterface
 	options    *Options
+	closer     closeState
 }
 
 // NewRedis creates a new store to Redis instance(s)
@@ -43,6 +46,10 @@ func NewRedisCluster(client RedisClusterClientInterface, options ...Option) *Red
 
 // Get returns data stored from a given key
 func (s *RedisClusterStore) Get(ctx context.Context, key any) (any, error) {
+	if s.closer.isClosed() {
+		return nil, ErrClosed
+	}
+
 	object, err := s.clusclient.Get(ctx, key.(string)).Result()
 	if err == redis.Nil {
 		return nil, NotFoundWithCause(err)
@@ -52,6 +59,10 @@ func (s *RedisClusterStore) Get(ctx context.Context, key any) (any, error) {
 
 // GetWithTTL returns data stored from a given key and its corresponding TTL
 func (s *RedisClusterStore) GetWithTTL(ctx context.Context, key any) (any, time.Duration, error) {
+	if s.closer.isClosed() {
+		return nil, 0, ErrClosed
+	}
+
 	object, err := s.clusclient.Get(ctx, key.(string)).Result()
 	if err == redis.Nil {
 		return nil, 0, NotFoundWithCause(err)
@@ -70,6 +81,10 @@ func (s *RedisClusterStore) GetWithTTL(ctx context.Context, key any) (any, time.
 
 // Set defines data in Redis for given key identifier
 func (s *RedisClusterStore) Set(ctx context.Context, key any, value any, options ...Option) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
 	opts := applyOptionsWithDefault(s.options, options...)
 
 	err := s.clusclient.Set(ctx, key.(string), value, opts.expiration).Err()
@@ -94,12 +109,20 @@ func (s *RedisClusterStore) setTags(ctx context.Context, key any, tags []string)
 
 // Delete removes data from Redis for given key identifier
 func (s *RedisClusterStore) Delete(ctx context.Context, key any) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
 	_, err := s.clusclient.Del(ctx, key.(string)).Result()
 	return err
 }
 
 // Invalidate invalidates some cache data in Redis for given options
 func (s *RedisClusterStore) Invalidate(ctx context.Context, options ...InvalidateOption) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
 	opts := ApplyInvalidateOptions(options...)
 
 	if tags := opts.tags; len(tags) > 0 {
@@ -123,6 +146,10 @@ func (s *RedisClusterStore) Invalidate(ctx context.Context, options ...Invalidat
 
 // Clear resets all data in the store
 func (s *RedisClusterStore) Clear(ctx context.Context) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
 	if err := s.clusclient.FlushAll(ctx).Err(); err != nil {
 		return err
 	}
@@ -130,7 +157,33 @@ func (s *RedisClusterStore) Clear(ctx context.Context) error {
 	return nil
 }
 
+// Close marks the store as closed. As the client is given by the caller, it is not closed:
+// its owner has to close it. Next operations return ErrClosed.
+func (s *RedisClusterStore) Close() error {
+	s.closer.close()
+	return nil
+}
+
 // GetType returns the store type
 func (s *RedisClusterStore) GetType() string {
 	return RedisClusterType
 }
+
+// IterateKeys calls the given function with every key starting with the given prefix,
+// scanning each master node of the cluster
+func (s *RedisClusterStore) IterateKeys(ctx context.Context, prefix string, fn func(key string) error) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	var mu sync.Mutex
+
+	return s.clusclient.ForEachMaster(ctx, func(ctx context.Context, client *redis.Client) error {
+		return scanKeys(ctx, client.Scan, prefix, func(key string) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			return fn(key)
+		})
+	})
+}
@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -180,3 +181,25 @@ func TestRedisClusterGetType(t *testing.T) {
 	// When - Then
 	assert.Equal(t, RedisClusterType, store.GetType())
 }
+
+func TestRedisClusterIterateKeysWhenError(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	ctx := context.Background()
+
+	expectedErr := errors.New("unable to reach masters")
+
+	client := mocksStore.NewMockRedisClusterClientInterface(ctrl)
+	client.EXPECT().ForEachMaster(ctx, gomock.Any()).Return(expectedErr)
+
+	store := NewRedisCluster(client)
+
+	// When
+	err := store.IterateKeys(ctx, "svc-a:", func(key string) error {
+		return nil
+	})
+
+	// Then
+	assert.Equal(t, expectedErr, err)
+}
@@ -4,8 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"reflect"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/dgraph-io/ristretto"
+	"github.com/dgraph-io/ristretto/z"
 )
 
 const (
@@ -13,6 +19,10 @@ const (
 	RistrettoType = "ristretto"
 	// RistrettoTagPattern represents the tag pattern to be used as a key in specified storage
 	RistrettoTagPattern = "gocache_tag_%s"
+
+	// ristrettoKeysSweepInterval is the minimum interval between two removals of
+	// expired keys from the tracked keys
+	ristrettoKeysSweepInterval = time.Minute
 )
 
 // RistrettoClientInterface represents a dgraph-io/ristretto client
@@ -27,18 +37,39 @@ type RistrettoClientInterface interface {
 type RistrettoStore struct {
 	client  RistrettoClientInterface
 	options *Options
+
+	// keys holds the expiration date of the keys set through the store,
+	// when key tracking is enabled, as Ristretto does not allow listing keys.
+	// keyHashes gives the tracked keys from the hashes given by Ristretto callbacks.
+	keysMutex   sync.Mutex
+	keys        map[any]time.Time
+	keyHashes   map[ristrettoKeyHash]any
+	keysSweptAt time.Time
+
+	closer closeState
 }
 
 // NewRistretto creates a new store to Ristretto (memory) library instance
 func NewRistretto(client RistrettoClientInterface, options ...Option) *RistrettoStore {
-	return &RistrettoStore{
+	store := &RistrettoStore{
 		client:  client,
 		options: ApplyOptions(options...),
 	}
+
+	if store.options.keyTracking {
+		store.keys = make(map[any]time.Time)
+		store.keyHashes = make(map[ristrettoKeyHash]any)
+	}
+
+	return store
 }
 
 // Get returns data stored from a given key
 func (s *RistrettoStore) Get(_ context.Context, key any) (any, error) {
+	if s.closer.isClosed() {
+		return nil, ErrClosed
+	}
+
 	var err error
 
 	value, exists := s.client.Get(key)
@@ -51,21 +82,33 @@ func (s *RistrettoStore) Get(_ context.Context, key any) (any, error) {
 
 // GetWithTTL returns data stored from a given key and its corresponding TTL
 func (s *RistrettoStore) GetWithTTL(ctx context.Context, key any) (any, time.Duration, error) {
+	if s.closer.isClosed() {
+		return nil, 0, ErrClosed
+	}
+
 	value, err := s.Get(ctx, key)
 	return value, 0, err
 }
 
 // Set defines data in Ristretto memoey cache for given key identifier
 func (s *RistrettoStore) Set(ctx context.Context, key any, value any, options ...Option) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
 	opts := applyOptionsWithDefault(s.options, options...)
 
 	var err error
 
+	// The key is tracked first, so it is untracked when Ristretto rejects it asynchronously
+	s.trackKey(key, opts.expiration)
+
 	if set := s.client.SetWithTTL(key, value, opts.cost, opts.expiration); !set {
 		err = fmt.Errorf("An error has occurred while setting value '%v' on key '%v'", value, key)
 	}
 
 	if err != nil {
+		s.untrackKey(key)
 		return err
 	}
 
@@ -105,12 +148,21 @@ func (s *RistrettoStore) setTags(ctx context.Context, key any, tags []string) {
 
 // Delete removes data in Ristretto memoey cache for given key identifier
 func (s *RistrettoStore) Delete(_ context.Context, key any) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
 	s.client.Del(key)
+	s.untrackKey(key)
 	return nil
 }
 
 // Invalidate invalidates some cache data in Redis for given options
 func (s *RistrettoStore) Invalidate(ctx context.Context, options ...InvalidateOption) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
 	opts := ApplyInvalidateOptions(options...)
 
 	if tags := opts.tags; len(tags) > 0 {
@@ -137,7 +189,27 @@ func (s *RistrettoStore) Invalidate(ctx context.Context, options ...InvalidateOp
 
 // Clear resets all data in the store
 func (s *RistrettoStore) Clear(_ context.Context) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
 	s.client.Clear()
+
+	if s.keys != nil {
+		s.keysMutex.Lock()
+		s.keys = make(map[any]time.Time)
+		s.keyHashes = make(map[ristrettoKeyHash]any)
+		s.keysMutex.Unlock()
+	}
+
+	return nil
+}
+
+// Close marks the store as closed. As the client is given by the caller, it is not closed:
+// its owner has to close it (stopping the *ristretto.Cache goroutines).
+// Next operations return ErrClosed.
+func (s *RistrettoStore) Close() error {
+	s.closer.close()
 	return nil
 }
 
@@ -145,3 +217,239 @@ func (s *RistrettoStore) Clear(_ context.Context) error {
 func (s *RistrettoStore) GetType() string {
 	return RistrettoType
 }
+
+// IterateKeys calls the given function with every string key set through the store, still
+// present and starting with the given prefix. It requires the store to be created using the
+// WithKeyTracking option, ErrKeyIterationNotSupported is returned otherwise.
+func (s *RistrettoStore) IterateKeys(ctx context.Context, prefix string, fn func(key string) error) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	if s.keys == nil {
+		return ErrKeyIterationNotSupported
+	}
+
+	now := time.Now()
+
+	// Keys are listed first, so the given function is able to update the store
+	var keys []string
+	s.keysMutex.Lock()
+	for key, expiresAt := range s.keys {
+		stringKey, ok := key.(string)
+		if ok && strings.HasPrefix(stringKey, prefix) && (expiresAt.IsZero() || expiresAt.After(now)) {
+			keys = append(keys, stringKey)
+		}
+	}
+	s.keysMutex.Unlock()
+
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if _, found := s.client.Get(key); !found {
+			// The value has been evicted
+			s.untrackKey(key)
+			continue
+		}
+
+		if err := fn(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RistrettoOnEvict returns a function to be used as the OnEvict callback of the Ristretto
+// config, emitting evict events to the given hooks. Ristretto only gives the hash of evicted
+// keys, which is used as event key, and does not tell expirations from evictions: both
+// are emitted as evict events, as well as the removal of tags and cleared values.
+func RistrettoOnEvict(hooks *Hooks) func(item *ristretto.Item) {
+	return func(item *ristretto.Item) {
+		hooks.Emit(Event{
+			Type:   EventEvict,
+			Key:    item.Key,
+			Value:  item.Value,
+			Source: RistrettoType,
+			Time:   time.Now(),
+		})
+	}
+}
+
+// OnEvict removes the given item from the keys tracked by the store. When key tracking is
+// enabled, use it as the OnEvict and OnReject callbacks of the Ristretto config, so keys
+// evicted, expired or rejected by Ristretto are not kept in memory. Otherwise only expired
+// keys are removed, at most once a minute. Keys are identified using the default Ristretto
+// key hash function.
+func (s *RistrettoStore) OnEvict(item *ristretto.Item) {
+	if s.keys == nil || item == nil {
+		return
+	}
+
+	hash := ristrettoKeyHash{key: item.Key, conflict: item.Conflict}
+
+	s.keysMutex.Lock()
+	defer s.keysMutex.Unlock()
+
+	if key, ok := s.keyHashes[hash]; ok {
+		delete(s.keys, key)
+		delete(s.keyHashes, hash)
+	}
+}
+
+// Dump writes every value set through the store and still present into the given writer,
+// with its expiration date and its tags. It requires the store to be created using the
+// WithKeyTracking option, ErrKeyIterationNotSupported is returned otherwise.
+// See SnapshotterInterface.
+func (s *RistrettoStore) Dump(ctx context.Context, w io.Writer) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	if s.keys == nil {
+		return ErrKeyIterationNotSupported
+	}
+
+	tagPrefix := strings.TrimSuffix(RistrettoTagPattern, "%s")
+
+	s.keysMutex.Lock()
+	keys := make(map[any]time.Time, len(s.keys))
+	for key, expiresAt := range s.keys {
+		keys[key] = expiresAt
+	}
+	s.keysMutex.Unlock()
+
+	keysByTag := make(map[string][]string)
+	for key := range keys {
+		tagKey, ok := key.(string)
+		if !ok || !strings.HasPrefix(tagKey, tagPrefix) {
+			continue
+		}
+
+		if value, found := s.client.Get(tagKey); found {
+			if bytes, ok := value.([]byte); ok {
+				keysByTag[strings.TrimPrefix(tagKey, tagPrefix)] = strings.Split(string(bytes), ",")
+			}
+		}
+	}
+	tagsByKey := snapshotTags(keysByTag)
+
+	writer, err := newSnapshotWriter(w, RistrettoType)
+	if err != nil {
+		return err
+	}
+
+	for key, expiresAt := range keys {
+		if stringKey, ok := key.(string); ok && strings.HasPrefix(stringKey, tagPrefix) {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		value, found := s.client.Get(key)
+		if !found || (!expiresAt.IsZero() && !expiresAt.After(time.Now())) {
+			// The value has been evicted or has expired
+			s.untrackKey(key)
+			continue
+		}
+
+		entry := snapshotEntry{
+			Key:       key,
+			Value:     value,
+			ExpiresAt: expiresAt,
+		}
+		if stringKey, ok := key.(string); ok {
+			entry.Tags = tagsByKey[stringKey]
+		}
+
+		if err := writer.write(entry); err != nil {
+			return err
+		}
+	}
+
+	return writer.close()
+}
+
+// Restore sets the values of the given snapshot into the store, skipping the expired ones.
+// See SnapshotterInterface.
+func (s *RistrettoStore) Restore(ctx context.Context, r io.Reader) error {
+	if s.closer.isClosed() {
+		return ErrClosed
+	}
+
+	return restoreSnapshot(ctx, s, r)
+}
+
+// trackKey records the expiration date of the given key, when key tracking is enabled
+func (s *RistrettoStore) trackKey(key any, expiration time.Duration) {
+	if s.keys == nil || key == nil || !reflect.TypeOf(key).Comparable() {
+		return
+	}
+
+	var expiresAt time.Time
+	if expiration > 0 {
+		expiresAt = time.Now().Add(expiration)
+	}
+
+	now := time.Now()
+
+	s.keysMutex.Lock()
+	defer s.keysMutex.Unlock()
+
+	s.keys[key] = expiresAt
+	if hash, ok := hashRistrettoKey(key); ok {
+		s.keyHashes[hash] = key
+	}
+
+	if now.Sub(s.keysSweptAt) >= ristrettoKeysSweepInterval {
+		s.keysSweptAt = now
+		s.sweepKeys(now)
+	}
+}
+
+// sweepKeys removes the expired keys from the tracked keys. keysMutex must be held.
+func (s *RistrettoStore) sweepKeys(now time.Time) {
+	for key, expiresAt := range s.keys {
+		if !expiresAt.IsZero() && !expiresAt.After(now) {
+			delete(s.keys, key)
+			if hash, ok := hashRistrettoKey(key); ok {
+				delete(s.keyHashes, hash)
+			}
+		}
+	}
+}
+
+// untrackKey removes the given key from the tracked keys
+func (s *RistrettoStore) untrackKey(key any) {
+	if s.keys == nil || key == nil || !reflect.TypeOf(key).Comparable() {
+		return
+	}
+
+	s.keysMutex.Lock()
+	delete(s.keys, key)
+	if hash, ok := hashRistrettoKey(key); ok {
+		delete(s.keyHashes, hash)
+	}
+	s.keysMutex.Unlock()
+}
+
+// ristrettoKeyHash identifies a key the way Ristretto does
+type ristrettoKeyHash struct {
+	key      uint64
+	conflict uint64
+}
+
+// hashRistrettoKey returns the hash of the given key, if it is supported by Ristretto
+func hashRistrettoKey(key any) (ristrettoKeyHash, bool) {
+	switch key.(type) {
+	case uint64, string, []byte, byte, int, int32, uint32, int64:
+		hashKey, conflict := z.KeyToHash(key)
+		return ristrettoKeyHash{key: hashKey, conflict: conflict}, true
+	default:
+		return ristrettoKeyHash{}, false
+	}
+}
@@ -1,11 +1,14 @@
 package store
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"testing"
 	"time"
 
+	"github.com/dgraph-io/ristretto"
+	"github.com/dgraph-io/ristretto/z"
 	mocksStore "github.com/eko/gocache/v3/test/mocks/store/clients"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -314,3 +317,232 @@ func TestRistrettoGetType(t *testing.T) {
 	// When - Then
 	assert.Equal(t, RistrettoType, store.GetType())
 }
+
+func TestRistrettoOnEvict(t *testing.T) {
+	// Given
+	hooks := NewHooks()
+	recorder := &eventRecorder{}
+	hooks.OnEvict(recorder.record)
+
+	onEvict := RistrettoOnEvict(hooks)
+
+	// When
+	onEvict(&ristretto.Item{Key: 42, Value: "my-value"})
+
+	// Then
+	assert.Nil(t, hooks.Close())
+
+	assert.Equal(t, []EventType{EventEvict}, recorder.types())
+	assert.Equal(t, uint64(42), recorder.events[0].Key)
+	assert.Equal(t, "my-value", recorder.events[0].Value)
+	assert.Equal(t, RistrettoType, recorder.events[0].Source)
+}
+
+func TestRistrettoDump(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+
+	client := mocksStore.NewMockRistrettoClientInterface(ctrl)
+	client.EXPECT().SetWithTTL("my-key", "my-value", int64(0), time.Hour).Return(true)
+	client.EXPECT().SetWithTTL("evicted-key", "my-value", int64(0), time.Duration(0)).Return(true)
+	client.EXPECT().Get("gocache_tag_my-tag").Return(nil, false)
+	client.EXPECT().SetWithTTL("gocache_tag_my-tag", []byte("my-key"), int64(0), 720*time.Hour).Return(true)
+	client.EXPECT().Get("gocache_tag_my-tag").Return([]byte("my-key"), true)
+	client.EXPECT().Get("my-key").Return("my-value", true)
+	client.EXPECT().Get("evicted-key").Return(nil, false)
+
+	store := NewRistretto(client, WithKeyTracking())
+	assert.Nil(t, store.Set(ctx, "my-key", "my-value", WithExpiration(time.Hour), WithTags([]string{"my-tag"})))
+	assert.Nil(t, store.Set(ctx, "evicted-key", "my-value"))
+
+	var buffer bytes.Buffer
+
+	// When
+	err := store.Dump(ctx, &buffer)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Len(t, store.keys, 2)
+
+	var entries []snapshotEntry
+	assert.Nil(t, readSnapshot(ctx, &buffer, func(entry snapshotEntry) error {
+		entries = append(entries, entry)
+		return nil
+	}))
+
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "my-key", entries[0].Key)
+	assert.Equal(t, "my-value", entries[0].Value)
+	assert.Equal(t, []string{"my-tag"}, entries[0].Tags)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), entries[0].ExpiresAt, time.Minute)
+}
+
+func TestRistrettoDumpWithoutKeyTracking(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	client := mocksStore.NewMockRistrettoClientInterface(ctrl)
+	store := NewRistretto(client)
+
+	// When
+	err := store.Dump(context.Background(), &bytes.Buffer{})
+
+	// Then
+	assert.Equal(t, ErrKeyIterationNotSupported, err)
+}
+
+func TestRistrettoIterateKeys(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+
+	client := mocksStore.NewMockRistrettoClientInterface(ctrl)
+	client.EXPECT().SetWithTTL("svc-a:one", "value", int64(0), time.Duration(0)).Return(true)
+	client.EXPECT().SetWithTTL("svc-a:evicted", "value", int64(0), time.Duration(0)).Return(true)
+	client.EXPECT().SetWithTTL("svc-b:one", "value", int64(0), time.Duration(0)).Return(true)
+	client.EXPECT().Get("svc-a:one").Return("value", true)
+	client.EXPECT().Get("svc-a:evicted").Return(nil, false)
+
+	store := NewRistretto(client, WithKeyTracking())
+	assert.Nil(t, store.Set(ctx, "svc-a:one", "value"))
+	assert.Nil(t, store.Set(ctx, "svc-a:evicted", "value"))
+	assert.Nil(t, store.Set(ctx, "svc-b:one", "value"))
+
+	var keys []string
+
+	// When
+	err := store.IterateKeys(ctx, "svc-a:", func(key string) error {
+		keys = append(keys, key)
+		return nil
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"svc-a:one"}, keys)
+	assert.Len(t, store.keys, 2)
+}
+
+func TestRistrettoIterateKeysWithoutKeyTracking(t *testing.T) {
+	// Given
+	ctrl := gomock.NewController(t)
+
+	client := mocksStore.NewMockRistrettoClientInterface(ctrl)
+	store := NewRistretto(client)
+
+	// When
+	err := store.IterateKeys(context.Background(), "", func(key string) error {
+		return nil
+	})
+
+	// Then
+	assert.Equal(t, ErrKeyIterationNotSupported, err)
+}
+
+func TestRistrettoRestore(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+
+	var buffer bytes.Buffer
+	writer, err := newSnapshotWriter(&buffer, RistrettoType)
+	assert.Nil(t, err)
+	assert.Nil(t, writer.write(snapshotEntry{Key: "my-key", Value: "my-value"}))
+	assert.Nil(t, writer.close())
+
+	client := mocksStore.NewMockRistrettoClientInterface(ctrl)
+	client.EXPECT().SetWithTTL("my-key", "my-value", int64(0), time.Duration(0)).Return(true)
+
+	store := NewRistretto(client, WithKeyTracking())
+
+	// When
+	err = store.Restore(ctx, &buffer)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Contains(t, store.keys, "my-key")
+}
+
+func TestRistrettoOnEvictUntracksKey(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+
+	client := mocksStore.NewMockRistrettoClientInterface(ctrl)
+	client.EXPECT().SetWithTTL("my-key", "my-value", int64(0), time.Duration(0)).Return(true)
+	client.EXPECT().SetWithTTL("other-key", "my-value", int64(0), time.Duration(0)).Return(true)
+
+	store := NewRistretto(client, WithKeyTracking())
+	assert.Nil(t, store.Set(ctx, "my-key", "my-value"))
+	assert.Nil(t, store.Set(ctx, "other-key", "my-value"))
+
+	key, conflict := z.KeyToHash("my-key")
+
+	// When
+	store.OnEvict(&ristretto.Item{Key: key, Conflict: conflict})
+
+	// Then
+	assert.Len(t, store.keys, 1)
+	assert.Contains(t, store.keys, "other-key")
+	assert.Len(t, store.keyHashes, 1)
+}
+
+func TestRistrettoTrackedKeysAreBoundedByClient(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	var store *RistrettoStore
+
+	client, err := ristretto.NewCache(&ristretto.Config{
+		NumCounters:        1000,
+		MaxCost:            10,
+		BufferItems:        64,
+		IgnoreInternalCost: true,
+		OnEvict:            func(item *ristretto.Item) { store.OnEvict(item) },
+		OnReject:           func(item *ristretto.Item) { store.OnEvict(item) },
+	})
+	assert.Nil(t, err)
+	defer client.Close()
+
+	store = NewRistretto(client, WithKeyTracking(), WithCost(1))
+
+	// When
+	for i := 0; i < 100; i++ {
+		_ = store.Set(ctx, fmt.Sprintf("key-%d", i), "my-value")
+	}
+	client.Wait()
+
+	// Then
+	store.keysMutex.Lock()
+	defer store.keysMutex.Unlock()
+
+	assert.LessOrEqual(t, len(store.keys), 10)
+	for key := range store.keys {
+		_, found := client.Get(key)
+		assert.True(t, found, key)
+	}
+}
+
+func TestRistrettoTrackedKeysAreSweptWhenExpired(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+
+	client := mocksStore.NewMockRistrettoClientInterface(ctrl)
+	client.EXPECT().SetWithTTL("expired-key", "my-value", int64(0), time.Millisecond).Return(true)
+	client.EXPECT().SetWithTTL("my-key", "my-value", int64(0), time.Duration(0)).Return(true)
+
+	store := NewRistretto(client, WithKeyTracking())
+	assert.Nil(t, store.Set(ctx, "expired-key", "my-value", WithExpiration(time.Millisecond)))
+
+	time.Sleep(2 * time.Millisecond)
+	store.keysSweptAt = time.Time{}
+
+	// When
+	assert.Nil(t, store.Set(ctx, "my-key", "my-value"))
+
+	// Then
+	assert.Len(t, store.keys, 1)
+	assert.Contains(t, store.keys, "my-key")
+	assert.Len(t, store.keyHashes, 1)
+}
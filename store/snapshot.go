@@ -0,0 +1,168 @@
+package store
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+const (
+	snapshotMagic = "GOCACHESNAP"
+	// snapshotVersion is the version of the snapshot format, written after the magic bytes
+	snapshotVersion byte = 0x01
+)
+
+// ErrInvalidSnapshot is returned when restoring data that is not a snapshot or has an unsupported version
+var ErrInvalidSnapshot = errors.New("invalid snapshot")
+
+// SnapshotterInterface represents stores able to dump their content and to restore it,
+// so in-memory caches can be warm when an application restarts
+type SnapshotterInterface interface {
+	Dump(ctx context.Context, w io.Writer) error
+	Restore(ctx context.Context, r io.Reader) error
+}
+
+// snapshotHeader is the first record of a snapshot
+type snapshotHeader struct {
+	Store     string
+	CreatedAt time.Time
+}
+
+// snapshotEntry is a record of a snapshot. Values are encoded using gob, so values
+// of types other than the built-in ones have to be registered using gob.Register.
+type snapshotEntry struct {
+	Key       any
+	Value     any
+	ExpiresAt time.Time
+	Tags      []string
+}
+
+// snapshotWriter streams entries into a gzip compressed snapshot
+type snapshotWriter struct {
+	compressor *gzip.Writer
+	encoder    *gob.Encoder
+}
+
+func newSnapshotWriter(w io.Writer, storeType string) (*snapshotWriter, error) {
+	if _, err := io.WriteString(w, snapshotMagic); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write([]byte{snapshotVersion}); err != nil {
+		return nil, err
+	}
+
+	compressor := gzip.NewWriter(w)
+	writer := &snapshotWriter{
+		compressor: compressor,
+		encoder:    gob.NewEncoder(compressor),
+	}
+
+	if err := writer.encoder.Encode(snapshotHeader{Store: storeType, CreatedAt: time.Now()}); err != nil {
+		return nil, err
+	}
+
+	return writer, nil
+}
+
+func (w *snapshotWriter) write(entry snapshotEntry) error {
+	if err := w.encoder.Encode(entry); err != nil {
+		return fmt.Errorf("unable to encode value of key %v: %w", entry.Key, err)
+	}
+
+	return nil
+}
+
+func (w *snapshotWriter) close() error {
+	return w.compressor.Close()
+}
+
+// readSnapshot calls the given function with every entry of the snapshot
+func readSnapshot(ctx context.Context, r io.Reader, fn func(entry snapshotEntry) error) error {
+	reader := bufio.NewReader(r)
+
+	header := make([]byte, len(snapshotMagic)+1)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	if string(header[:len(snapshotMagic)]) != snapshotMagic {
+		return fmt.Errorf("%w: unexpected header", ErrInvalidSnapshot)
+	}
+	if version := header[len(snapshotMagic)]; version != snapshotVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidSnapshot, version)
+	}
+
+	decompressor, err := gzip.NewReader(reader)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+	defer decompressor.Close()
+
+	decoder := gob.NewDecoder(decompressor)
+
+	var snapshot snapshotHeader
+	if err := decoder.Decode(&snapshot); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var entry snapshotEntry
+		if err := decoder.Decode(&entry); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("%w: %v", ErrInvalidSnapshot, err)
+		}
+
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+}
+
+// restoreSnapshot sets every unexpired entry of the snapshot into the given store,
+// with its remaining expiration and its tags
+func restoreSnapshot(ctx context.Context, store StoreInterface, r io.Reader) error {
+	return readSnapshot(ctx, r, func(entry snapshotEntry) error {
+		var options []Option
+
+		if !entry.ExpiresAt.IsZero() {
+			remaining := time.Until(entry.ExpiresAt)
+			if remaining <= 0 {
+				return nil
+			}
+			options = append(options, WithExpiration(remaining))
+		}
+
+		if len(entry.Tags) > 0 {
+			options = append(options, WithTags(entry.Tags))
+		}
+
+		return store.Set(ctx, entry.Key, entry.Value, options...)
+	})
+}
+
+// snapshotTags indexes the tags of the keys, from the keys of each tag
+func snapshotTags(keysByTag map[string][]string) map[string][]string {
+	tagsByKey := make(map[string][]string)
+	for tag, keys := range keysByTag {
+		for _, key := range keys {
+			tagsByKey[key] = append(tagsByKey[key], tag)
+		}
+	}
+
+	for _, tags := range tagsByKey {
+		sort.Strings(tags)
+	}
+
+	return tagsByKey
+}
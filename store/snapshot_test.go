@@ -0,0 +1,106 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestoreSnapshot(t *testing.T) {
+	// Given
+	ctx := context.Background()
+
+	var buffer bytes.Buffer
+	writer, err := newSnapshotWriter(&buffer, GoCacheType)
+	assert.Nil(t, err)
+
+	assert.Nil(t, writer.write(snapshotEntry{Key: "my-key", Value: "my-value"}))
+	assert.Nil(t, writer.write(snapshotEntry{Key: "expired-key", Value: "my-value", ExpiresAt: time.Now().Add(-time.Second)}))
+	assert.Nil(t, writer.write(snapshotEntry{
+		Key:       "tagged-key",
+		Value:     []byte("my-value"),
+		ExpiresAt: time.Now().Add(time.Hour),
+		Tags:      []string{"my-tag"},
+	}))
+	assert.Nil(t, writer.close())
+
+	store := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+
+	// When
+	err = restoreSnapshot(ctx, store, &buffer)
+
+	// Then
+	assert.Nil(t, err)
+
+	value, err := store.Get(ctx, "my-key")
+	assert.Nil(t, err)
+	assert.Equal(t, "my-value", value)
+
+	_, err = store.Get(ctx, "expired-key")
+	assert.True(t, errors.Is(err, NotFound{}))
+
+	value, ttl, err := store.GetWithTTL(ctx, "tagged-key")
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("my-value"), value)
+	assert.InDelta(t, time.Hour, ttl, float64(time.Minute))
+
+	assert.Nil(t, store.Invalidate(ctx, WithInvalidateTags([]string{"my-tag"})))
+	_, err = store.Get(ctx, "tagged-key")
+	assert.True(t, errors.Is(err, NotFound{}))
+}
+
+func TestRestoreSnapshotWhenInvalid(t *testing.T) {
+	// Given
+	ctx := context.Background()
+	store := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+
+	// When - Then
+	err := restoreSnapshot(ctx, store, bytes.NewReader([]byte("not a snapshot")))
+	assert.True(t, errors.Is(err, ErrInvalidSnapshot))
+
+	err = restoreSnapshot(ctx, store, bytes.NewReader(append([]byte(snapshotMagic), 0x42)))
+	assert.True(t, errors.Is(err, ErrInvalidSnapshot))
+	assert.Contains(t, err.Error(), "unsupported version 66")
+}
+
+func TestRestoreSnapshotWhenContextCanceled(t *testing.T) {
+	// Given
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var buffer bytes.Buffer
+	writer, err := newSnapshotWriter(&buffer, GoCacheType)
+	assert.Nil(t, err)
+	assert.Nil(t, writer.write(snapshotEntry{Key: "my-key", Value: "my-value"}))
+	assert.Nil(t, writer.close())
+
+	store := NewGoCache(cache.New(cache.NoExpiration, time.Minute))
+
+	// When
+	err = restoreSnapshot(ctx, store, &buffer)
+
+	// Then
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	_, err = store.Get(context.Background(), "my-key")
+	assert.True(t, errors.Is(err, NotFound{}))
+}
+
+func TestSnapshotTags(t *testing.T) {
+	// When
+	tagsByKey := snapshotTags(map[string][]string{
+		"tag-b": {"my-key"},
+		"tag-a": {"my-key", "my-other-key"},
+	})
+
+	// Then
+	assert.Equal(t, map[string][]string{
+		"my-key":       {"tag-a", "tag-b"},
+		"my-other-key": {"tag-a"},
+	}, tagsByKey)
+}
@@ -51,6 +51,20 @@ func (mr *MockCacheInterfaceMockRecorder[T]) Clear(ctx interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clear", reflect.TypeOf((*MockCacheInterface[T])(nil).Clear), ctx)
 }
 
+// Close mocks base method.
+func (m *MockCacheInterface[T]) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockCacheInterfaceMockRecorder[T]) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockCacheInterface[T])(nil).Close))
+}
+
 // Delete mocks base method.
 func (m *MockCacheInterface[T]) Delete(ctx context.Context, key any) error {
 	m.ctrl.T.Helper()
@@ -206,6 +220,20 @@ func (mr *MockSetterCacheInterfaceMockRecorder[T]) Clear(ctx interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Clear", reflect.TypeOf((*MockSetterCacheInterface[T])(nil).Clear), ctx)
 }
 
+// Close mocks base method.
+func (m *MockSetterCacheInterface[T]) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockSetterCacheInterfaceMockRecorder[T]) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockSetterCacheInterface[T])(nil).Close))
+}
+
 // Delete mocks base method.
 func (m *MockSetterCacheInterface[T]) Delete(ctx context.Context, key any) error {
 	m.ctrl.T.Helper()
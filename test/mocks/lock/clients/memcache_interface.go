@@ -0,0 +1,78 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: lock/memcache.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	memcache "github.com/bradfitz/gomemcache/memcache"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockMemcacheClientInterface is a mock of MemcacheClientInterface interface.
+type MockMemcacheClientInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockMemcacheClientInterfaceMockRecorder
+}
+
+// MockMemcacheClientInterfaceMockRecorder is the mock recorder for MockMemcacheClientInterface.
+type MockMemcacheClientInterfaceMockRecorder struct {
+	mock *MockMemcacheClientInterface
+}
+
+// NewMockMemcacheClientInterface creates a new mock instance.
+func NewMockMemcacheClientInterface(ctrl *gomock.Controller) *MockMemcacheClientInterface {
+	mock := &MockMemcacheClientInterface{ctrl: ctrl}
+	mock.recorder = &MockMemcacheClientInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMemcacheClientInterface) EXPECT() *MockMemcacheClientInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockMemcacheClientInterface) Add(item *memcache.Item) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockMemcacheClientInterfaceMockRecorder) Add(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockMemcacheClientInterface)(nil).Add), item)
+}
+
+// CompareAndSwap mocks base method.
+func (m *MockMemcacheClientInterface) CompareAndSwap(item *memcache.Item) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompareAndSwap", item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompareAndSwap indicates an expected call of CompareAndSwap.
+func (mr *MockMemcacheClientInterfaceMockRecorder) CompareAndSwap(item interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompareAndSwap", reflect.TypeOf((*MockMemcacheClientInterface)(nil).CompareAndSwap), item)
+}
+
+// Get mocks base method.
+func (m *MockMemcacheClientInterface) Get(key string) (*memcache.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", key)
+	ret0, _ := ret[0].(*memcache.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockMemcacheClientInterfaceMockRecorder) Get(key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockMemcacheClientInterface)(nil).Get), key)
+}
@@ -0,0 +1,79 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: lock/interface.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockLockerInterface is a mock of LockerInterface interface.
+type MockLockerInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockLockerInterfaceMockRecorder
+}
+
+// MockLockerInterfaceMockRecorder is the mock recorder for MockLockerInterface.
+type MockLockerInterfaceMockRecorder struct {
+	mock *MockLockerInterface
+}
+
+// NewMockLockerInterface creates a new mock instance.
+func NewMockLockerInterface(ctrl *gomock.Controller) *MockLockerInterface {
+	mock := &MockLockerInterface{ctrl: ctrl}
+	mock.recorder = &MockLockerInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLockerInterface) EXPECT() *MockLockerInterfaceMockRecorder {
+	return m.recorder
+}
+
+// GetType mocks base method.
+func (m *MockLockerInterface) GetType() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetType")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetType indicates an expected call of GetType.
+func (mr *MockLockerInterfaceMockRecorder) GetType() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetType", reflect.TypeOf((*MockLockerInterface)(nil).GetType))
+}
+
+// Obtain mocks base method.
+func (m *MockLockerInterface) Obtain(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Obtain", ctx, key, ttl)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Obtain indicates an expected call of Obtain.
+func (mr *MockLockerInterfaceMockRecorder) Obtain(ctx, key, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Obtain", reflect.TypeOf((*MockLockerInterface)(nil).Obtain), ctx, key, ttl)
+}
+
+// Release mocks base method.
+func (m *MockLockerInterface) Release(ctx context.Context, key, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Release", ctx, key, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockLockerInterfaceMockRecorder) Release(ctx, key, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockLockerInterface)(nil).Release), ctx, key, token)
+}
@@ -45,3 +45,50 @@ func (mr *MockMetricsInterfaceMockRecorder) RecordFromCodec(codec interface{}) *
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFromCodec", reflect.TypeOf((*MockMetricsInterface)(nil).RecordFromCodec), codec)
 }
+
+// MockNamedMetricsInterface is a mock of NamedMetricsInterface interface.
+type MockNamedMetricsInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockNamedMetricsInterfaceMockRecorder
+}
+
+// MockNamedMetricsInterfaceMockRecorder is the mock recorder for MockNamedMetricsInterface.
+type MockNamedMetricsInterfaceMockRecorder struct {
+	mock *MockNamedMetricsInterface
+}
+
+// NewMockNamedMetricsInterface creates a new mock instance.
+func NewMockNamedMetricsInterface(ctrl *gomock.Controller) *MockNamedMetricsInterface {
+	mock := &MockNamedMetricsInterface{ctrl: ctrl}
+	mock.recorder = &MockNamedMetricsInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNamedMetricsInterface) EXPECT() *MockNamedMetricsInterfaceMockRecorder {
+	return m.recorder
+}
+
+// RecordFromCodec mocks base method.
+func (m *MockNamedMetricsInterface) RecordFromCodec(codec codec.CodecInterface) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RecordFromCodec", codec)
+}
+
+// RecordFromCodec indicates an expected call of RecordFromCodec.
+func (mr *MockNamedMetricsInterfaceMockRecorder) RecordFromCodec(codec interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFromCodec", reflect.TypeOf((*MockNamedMetricsInterface)(nil).RecordFromCodec), codec)
+}
+
+// RecordFromCodecWithName mocks base method.
+func (m *MockNamedMetricsInterface) RecordFromCodecWithName(name string, codec codec.CodecInterface) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RecordFromCodecWithName", name, codec)
+}
+
+// RecordFromCodecWithName indicates an expected call of RecordFromCodecWithName.
+func (mr *MockNamedMetricsInterfaceMockRecorder) RecordFromCodecWithName(name, codec interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordFromCodecWithName", reflect.TypeOf((*MockNamedMetricsInterface)(nil).RecordFromCodecWithName), name, codec)
+}
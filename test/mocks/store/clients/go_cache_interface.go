@@ -9,6 +9,7 @@ import (
 	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	cache "github.com/patrickmn/go-cache"
 )
 
 // MockGoCacheClientInterface is a mock of GoCacheClientInterface interface.
@@ -89,6 +90,20 @@ func (mr *MockGoCacheClientInterfaceMockRecorder) GetWithExpiration(k interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithExpiration", reflect.TypeOf((*MockGoCacheClientInterface)(nil).GetWithExpiration), k)
 }
 
+// Items mocks base method.
+func (m *MockGoCacheClientInterface) Items() map[string]cache.Item {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Items")
+	ret0, _ := ret[0].(map[string]cache.Item)
+	return ret0
+}
+
+// Items indicates an expected call of Items.
+func (mr *MockGoCacheClientInterfaceMockRecorder) Items() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Items", reflect.TypeOf((*MockGoCacheClientInterface)(nil).Items))
+}
+
 // Set mocks base method.
 func (m *MockGoCacheClientInterface) Set(k string, x any, d time.Duration) {
 	m.ctrl.T.Helper()
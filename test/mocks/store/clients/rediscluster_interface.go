@@ -83,6 +83,20 @@ func (mr *MockRedisClusterClientInterfaceMockRecorder) FlushAll(ctx interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlushAll", reflect.TypeOf((*MockRedisClusterClientInterface)(nil).FlushAll), ctx)
 }
 
+// ForEachMaster mocks base method.
+func (m *MockRedisClusterClientInterface) ForEachMaster(ctx context.Context, fn func(context.Context, *redis.Client) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForEachMaster", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForEachMaster indicates an expected call of ForEachMaster.
+func (mr *MockRedisClusterClientInterfaceMockRecorder) ForEachMaster(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForEachMaster", reflect.TypeOf((*MockRedisClusterClientInterface)(nil).ForEachMaster), ctx, fn)
+}
+
 // Get mocks base method.
 func (m *MockRedisClusterClientInterface) Get(ctx context.Context, key string) *redis.StringCmd {
 	m.ctrl.T.Helper()
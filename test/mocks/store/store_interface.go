@@ -146,3 +146,40 @@ func (mr *MockStoreInterfaceMockRecorder) Set(ctx, key, value interface{}, optio
 	varargs := append([]interface{}{ctx, key, value}, options...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockStoreInterface)(nil).Set), varargs...)
 }
+
+// MockKeyIteratorInterface is a mock of KeyIteratorInterface interface.
+type MockKeyIteratorInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeyIteratorInterfaceMockRecorder
+}
+
+// MockKeyIteratorInterfaceMockRecorder is the mock recorder for MockKeyIteratorInterface.
+type MockKeyIteratorInterfaceMockRecorder struct {
+	mock *MockKeyIteratorInterface
+}
+
+// NewMockKeyIteratorInterface creates a new mock instance.
+func NewMockKeyIteratorInterface(ctrl *gomock.Controller) *MockKeyIteratorInterface {
+	mock := &MockKeyIteratorInterface{ctrl: ctrl}
+	mock.recorder = &MockKeyIteratorInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKeyIteratorInterface) EXPECT() *MockKeyIteratorInterfaceMockRecorder {
+	return m.recorder
+}
+
+// IterateKeys mocks base method.
+func (m *MockKeyIteratorInterface) IterateKeys(ctx context.Context, prefix string, fn func(string) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IterateKeys", ctx, prefix, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IterateKeys indicates an expected call of IterateKeys.
+func (mr *MockKeyIteratorInterfaceMockRecorder) IterateKeys(ctx, prefix, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IterateKeys", reflect.TypeOf((*MockKeyIteratorInterface)(nil).IterateKeys), ctx, prefix, fn)
+}